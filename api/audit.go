@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"go-desktop-app/database"
+	"go-desktop-app/logging"
+	"go-desktop-app/metrics"
+)
+
+// auditedRoutes associa as rotas privilegiadas à operação registrada na auditoria
+var auditedRoutes = map[string]string{
+	"/escreve_arquivo":     "file.read",
+	"/move_arquivo":        "file.move",
+	"/executar_terceiros":  "process.execute",
+	"/api/license/status":  "license.status",
+	"/api/license/setup":   "license.setup",
+	"/api/license/verify":  "license.verify",
+	"/api/license/import":  "license.import",
+	"/api/license/clear":   "license.clear",
+	"/api/license/history": "license.history",
+	"/api/config/reload":   "config.reload",
+}
+
+// auditContextKey é a chave do registro de auditoria no contexto da requisição
+type auditContextKey struct{}
+
+// auditRecord acumula os detalhes que o handler informa durante a requisição
+type auditRecord struct {
+	target    string
+	arguments map[string]string
+	err       string
+}
+
+// auditResponseWriter captura o status code para o resultado da auditoria
+type auditResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (aw *auditResponseWriter) WriteHeader(code int) {
+	aw.statusCode = code
+	aw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap permite que http.ResponseController acesse o writer original
+func (aw *auditResponseWriter) Unwrap() http.ResponseWriter {
+	return aw.ResponseWriter
+}
+
+// AuditMiddleware grava na trilha de auditoria cada chamada às rotas de arquivo, execução
+// e licença, inclusive as recusadas pelo rate limit ou pela validação
+func AuditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation, ok := auditedRoutes[r.URL.Path]
+		if !ok || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		record := &auditRecord{}
+		aw := &auditResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(aw, r.WithContext(context.WithValue(r.Context(), auditContextKey{}, record)))
+
+		entry := &database.AuditEntry{
+			// Endereço remoto, ou a API key se ela estiver cadastrada em api_keys
+			Caller:     clientIdentity(r),
+			RemoteAddr: clientAddress(r),
+			Operation:  operation,
+			Target:     record.target,
+			Arguments:  record.arguments,
+			Result:     auditResult(aw.statusCode),
+			StatusCode: aw.statusCode,
+			Error:      record.err,
+			RequestID:  RequestIDFromContext(r.Context()),
+		}
+		if err := database.AppendAudit(entry); err != nil {
+			logger.Error("Falha ao gravar registro de auditoria", "operation", operation, "error", err,
+				logging.RequestIDKey, entry.RequestID)
+			return
+		}
+		metrics.AuditRecords.Inc(operation, entry.Result)
+	})
+}
+
+// auditResult converte o status HTTP no resultado da auditoria
+func auditResult(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return database.AuditSuccess
+	case status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return database.AuditDenied
+	default:
+		return database.AuditFailure
+	}
+}
+
+// auditTarget informa o alvo (arquivo, executável ou URL) e os argumentos da operação auditada.
+// Argumentos já informados são mantidos; os novos são acrescentados.
+func auditTarget(r *http.Request, target string, arguments map[string]string) {
+	record, ok := r.Context().Value(auditContextKey{}).(*auditRecord)
+	if !ok {
+		return
+	}
+	if target != "" {
+		record.target = target
+	}
+	for key, value := range arguments {
+		if record.arguments == nil {
+			record.arguments = make(map[string]string)
+		}
+		record.arguments[key] = value
+	}
+}
+
+// auditError informa o erro que fez a operação auditada falhar
+func auditError(r *http.Request, err error) {
+	if record, ok := r.Context().Value(auditContextKey{}).(*auditRecord); ok && err != nil {
+		record.err = err.Error()
+	}
+}
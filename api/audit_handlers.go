@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-desktop-app/database"
+)
+
+// AuditResponse representa a resposta de GET /api/audit
+type AuditResponse struct {
+	Entries []database.AuditEntry `json:"entries"`
+	Total   int                   `json:"total"`
+	Limit   int                   `json:"limit"`
+	Offset  int                   `json:"offset"`
+}
+
+// AuditHandler consulta a trilha de auditoria, do registro mais recente para o mais antigo.
+// Filtros: from, to, operation, result, caller, request_id, q (trecho do alvo), limit e offset.
+func AuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	entries, total, err := database.QueryAudit(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AuditResponse{Entries: entries, Total: total, Limit: filter.Limit, Offset: filter.Offset})
+}
+
+// AuditExportHandler exporta a trilha de auditoria filtrada em NDJSON ou CSV, em ordem cronológica.
+// Aceita os mesmos filtros de /api/audit (exceto limit/offset) e o parâmetro format.
+func AuditExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ExportFormatNDJSON
+	}
+	if format != ExportFormatNDJSON && format != ExportFormatCSV {
+		writeBadRequest(w, "parâmetro format deve ser ndjson ou csv")
+		return
+	}
+	spec := exportFormats[format]
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().Format("20060102-150405"), spec.extension)
+	w.Header().Set("Content-Type", spec.contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// Cabeçalhos já enviados: um erro no meio da exportação apenas interrompe o arquivo
+	if err := writeAuditExport(w, format, filter); err != nil {
+		logger.Warn("Exportação da auditoria interrompida", "error", err)
+	}
+}
+
+// AuditVerifyHandler confere a cadeia de hashes da trilha de auditoria.
+// Responde 200 com valid=true se a cadeia estiver íntegra e 409 com o primeiro registro inválido.
+func AuditVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	verification, err := database.VerifyAuditChain()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !verification.Valid {
+		logger.Error("Trilha de auditoria adulterada", "broken_at", verification.BrokenAt, "message", verification.Message)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !verification.Valid {
+		w.WriteHeader(http.StatusConflict)
+	}
+	json.NewEncoder(w).Encode(verification)
+}
+
+// writeAuditExport escreve os registros filtrados no formato pedido
+func writeAuditExport(w io.Writer, format string, filter database.AuditFilter) error {
+	if format == ExportFormatNDJSON {
+		encoder := json.NewEncoder(w)
+		return database.ExportAudit(filter, func(entry database.AuditEntry) error {
+			return encoder.Encode(entry)
+		})
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"id", "timestamp", "caller", "remote_addr", "operation", "target", "arguments",
+		"result", "status_code", "error", "request_id", "prev_hash", "hash"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	err := database.ExportAudit(filter, func(entry database.AuditEntry) error {
+		var arguments string
+		if len(entry.Arguments) > 0 {
+			data, _ := json.Marshal(entry.Arguments)
+			arguments = string(data)
+		}
+		return cw.Write([]string{
+			strconv.FormatInt(entry.ID, 10), entry.Timestamp.Format(time.RFC3339Nano), entry.Caller, entry.RemoteAddr,
+			entry.Operation, entry.Target, arguments, entry.Result, strconv.Itoa(entry.StatusCode), entry.Error,
+			entry.RequestID, entry.PrevHash, entry.Hash,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// parseAuditFilter interpreta os filtros da consulta e da exportação da auditoria
+func parseAuditFilter(r *http.Request) (database.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := database.AuditFilter{
+		Operation: query.Get("operation"),
+		Result:    query.Get("result"),
+		Caller:    query.Get("caller"),
+		RequestID: query.Get("request_id"),
+		Search:    query.Get("q"),
+	}
+
+	var err error
+	if filter.Limit, filter.Offset, err = parsePage(query.Get("limit"), query.Get("offset")); err != nil {
+		return filter, err
+	}
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = parseLogTime(value); err != nil {
+			return filter, fmt.Errorf("parâmetro from inválido: %v", err)
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = parseLogTime(value); err != nil {
+			return filter, fmt.Errorf("parâmetro to inválido: %v", err)
+		}
+	}
+	switch filter.Result {
+	case "", database.AuditSuccess, database.AuditDenied, database.AuditFailure:
+	default:
+		return filter, fmt.Errorf("parâmetro result deve ser success, denied ou failure")
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-desktop-app/config"
+	"go-desktop-app/logging"
+)
+
+// ReloadConfigHandler relê o config.json e publica ConfigReloaded no barramento de eventos
+func ReloadConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := config.ReloadSettings(RequestIDFromContext(r.Context())); err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	logger.Info("Configurações recarregadas", "path", config.SettingsPath(), logging.RequestIDKey, RequestIDFromContext(r.Context()))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MessageResponse{Mensagem: "Configurações recarregadas com sucesso"})
+}
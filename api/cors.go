@@ -0,0 +1,153 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go-desktop-app/config"
+)
+
+// originPattern é uma origem permitida já interpretada
+type originPattern struct {
+	any      bool
+	scheme   string
+	host     string
+	wildHost bool // host no formato "*.dominio"
+	port     string
+	anyPort  bool
+}
+
+// CORSMiddleware aplica a política de CORS configurada a todas as rotas, inclusive SSE.
+// Origens são comparadas por esquema, host e porta exatos (ou pelos curingas configurados),
+// evitando que "http://localhost.evil.com" passe por "http://localhost".
+func CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := config.GetSettings().CORS
+		origin := r.Header.Get("Origin")
+
+		// A resposta depende da origem, então caches não podem reutilizá-la entre origens
+		w.Header().Add("Vary", "Origin")
+
+		allowed := origin != "" && isOriginAllowed(policy.AllowedOrigins, origin)
+		if allowed {
+			if policy.AllowCredentials || !allowsAnyOrigin(policy.AllowedOrigins) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+			if policy.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			if len(policy.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+			}
+		}
+
+		// Preflight: responde sem chamar o handler da rota
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			if !allowed {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+			if policy.MaxAgeSeconds > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAgeSeconds))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isOriginAllowed verifica a origem contra a lista de origens permitidas
+func isOriginAllowed(allowedOrigins []string, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.User != nil {
+		return false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+
+	for _, raw := range allowedOrigins {
+		pattern, ok := parseOriginPattern(raw)
+		if !ok {
+			continue
+		}
+		if pattern.any {
+			return true
+		}
+		if pattern.scheme != scheme {
+			continue
+		}
+		if !pattern.anyPort && pattern.port != port {
+			continue
+		}
+		if pattern.wildHost {
+			if strings.HasSuffix(host, "."+pattern.host) {
+				return true
+			}
+			continue
+		}
+		if pattern.host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsAnyOrigin verifica se a política contém o curinga "*"
+func allowsAnyOrigin(allowedOrigins []string) bool {
+	for _, raw := range allowedOrigins {
+		if raw == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOriginPattern interpreta uma origem permitida da configuração
+func parseOriginPattern(raw string) (originPattern, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "*" {
+		return originPattern{any: true}, true
+	}
+
+	scheme, rest, ok := strings.Cut(raw, "://")
+	if !ok || (scheme != "http" && scheme != "https") || rest == "" {
+		return originPattern{}, false
+	}
+
+	pattern := originPattern{scheme: strings.ToLower(scheme)}
+
+	host := rest
+	// Separa a porta, respeitando endereços IPv6 entre colchetes
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.HasSuffix(rest, "]") {
+		host, pattern.port = rest[:i], rest[i+1:]
+		if pattern.port == "*" {
+			pattern.anyPort = true
+			pattern.port = ""
+		}
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+	if wild, ok := strings.CutPrefix(host, "*."); ok {
+		pattern.wildHost = true
+		host = wild
+	}
+	if host == "" || strings.Contains(host, "*") {
+		return originPattern{}, false
+	}
+	pattern.host = host
+
+	return pattern, true
+}
@@ -0,0 +1,40 @@
+package api
+
+import "testing"
+
+func TestIsOriginAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"origem exata", []string{"http://localhost:8080"}, "http://localhost:8080", true},
+		{"host em maiúsculas", []string{"http://localhost:8080"}, "http://LOCALHOST:8080", true},
+		{"porta diferente", []string{"http://localhost:8080"}, "http://localhost:9090", false},
+		{"sem porta no padrão", []string{"http://localhost"}, "http://localhost:8080", false},
+		{"esquema diferente", []string{"https://app.example.com"}, "http://app.example.com", false},
+		{"sufixo do host", []string{"http://localhost"}, "http://localhost.evil.com", false},
+		{"prefixo do host", []string{"http://example.com"}, "http://evilexample.com", false},
+		{"porta curinga", []string{"http://localhost:*"}, "http://localhost:3000", true},
+		{"porta curinga sem porta", []string{"http://localhost:*"}, "http://localhost", true},
+		{"subdomínio curinga", []string{"https://*.example.com"}, "https://app.example.com", true},
+		{"subdomínio curinga aninhado", []string{"https://*.example.com"}, "https://a.b.example.com", true},
+		{"curinga não inclui o domínio", []string{"https://*.example.com"}, "https://example.com", false},
+		{"curinga com sufixo falso", []string{"https://*.example.com"}, "https://app.example.com.evil.com", false},
+		{"qualquer origem", []string{"*"}, "http://qualquer.coisa", true},
+		{"IPv6", []string{"http://[::1]:8080"}, "http://[::1]:8080", true},
+		{"origem com caminho", []string{"http://localhost:8080"}, "http://localhost:8080/pagina", false},
+		{"origem com usuário", []string{"http://localhost:8080"}, "http://user@localhost:8080", false},
+		{"origem null", []string{"http://localhost:8080"}, "null", false},
+		{"padrão inválido ignorado", []string{"localhost:8080", "http://*"}, "http://localhost:8080", false},
+		{"lista vazia", nil, "http://localhost:8080", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOriginAllowed(tt.allowed, tt.origin); got != tt.want {
+				t.Errorf("isOriginAllowed(%q, %q) = %v, esperado %v", tt.allowed, tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"go-desktop-app/config"
+	"go-desktop-app/core"
+	"go-desktop-app/database"
+	"go-desktop-app/license"
+	"go-desktop-app/metrics"
+)
+
+// Formatos aceitos por /api/logs/export
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+	ExportFormatText   = "text"
+)
+
+// redactedValue substitui os valores sensíveis no pacote de diagnóstico
+const redactedValue = "[REDACTED]"
+
+// exportFormats associa cada formato ao Content-Type e à extensão do arquivo
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	ExportFormatNDJSON: {"application/x-ndjson", "ndjson"},
+	ExportFormatCSV:    {"text/csv; charset=utf-8", "csv"},
+	ExportFormatText:   {"text/plain; charset=utf-8", "log"},
+}
+
+// sensitiveKeys são trechos de nomes de campos cujos valores são ocultados
+var sensitiveKeys = []string{"token", "secret", "password", "api_key", "apikey", "private_key"}
+
+// LogsExportHandler exporta os logs filtrados em NDJSON, CSV ou texto.
+// Aceita os mesmos filtros de /api/logs (exceto limit/offset) e o parâmetro format.
+func LogsExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ExportFormatNDJSON
+	}
+	spec, ok := exportFormats[format]
+	if !ok {
+		writeBadRequest(w, "parâmetro format deve ser ndjson, csv ou text")
+		return
+	}
+
+	filter, err := parseLogFilter(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("logs-%s.%s", time.Now().Format("20060102-150405"), spec.extension)
+	w.Header().Set("Content-Type", spec.contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// Cabeçalhos já enviados: um erro no meio da exportação apenas interrompe o arquivo
+	if err := writeLogExport(w, format, filter); err != nil {
+		logger.Warn("Exportação de logs interrompida", "error", err)
+	}
+}
+
+// writeLogExport escreve as entradas filtradas no formato pedido.
+// Sem banco disponível, exporta o buffer em memória.
+func writeLogExport(w io.Writer, format string, filter database.LogFilter) error {
+	var write func(LogEntry) error
+	var finish func() error
+
+	switch format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"timestamp", "type", "request_id", "content"}); err != nil {
+			return err
+		}
+		write = func(entry LogEntry) error {
+			return cw.Write([]string{entry.Timestamp, entry.Type, entry.RequestID, entry.Content})
+		}
+		finish = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case ExportFormatText:
+		write = func(entry LogEntry) error {
+			line := fmt.Sprintf("%s %-5s %s", entry.Timestamp, strings.ToUpper(entry.Type), entry.Content)
+			if entry.RequestID != "" {
+				line += " request_id=" + entry.RequestID
+			}
+			_, err := io.WriteString(w, line+"\n")
+			return err
+		}
+	default:
+		encoder := json.NewEncoder(w)
+		write = func(entry LogEntry) error {
+			return encoder.Encode(entry)
+		}
+	}
+
+	if database.PingDatabase() == nil {
+		err := database.ExportLogs(filter, func(record database.LogRecord) error {
+			return write(LogEntry{
+				Timestamp: record.Timestamp.Format(logTimestampLayout),
+				Content:   record.Content,
+				Type:      record.Type,
+				RequestID: record.RequestID,
+			})
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, entry := range memoryLogsMatching(filter) {
+			if err := write(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	if finish != nil {
+		return finish()
+	}
+	return nil
+}
+
+// memoryLogsMatching retorna todas as entradas do buffer em memória que atendem aos filtros
+func memoryLogsMatching(filter database.LogFilter) []LogEntry {
+	logsMutex.RLock()
+	defer logsMutex.RUnlock()
+
+	var matched []LogEntry
+	for _, entry := range logEntries {
+		if logEntryMatches(entry, filter) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// DiagnosticsBundleHandler gera um .zip com logs, configuração (sem segredos),
+// histórico de processos, estatísticas do banco, licença e métricas.
+// Os logs incluídos aceitam os mesmos filtros de /api/logs/export.
+func DiagnosticsBundleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseLogFilter(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("diagnostico-%s.zip", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if err := writeDiagnosticsBundle(w, filter); err != nil {
+		logger.Warn("Geração do pacote de diagnóstico interrompida", "error", err)
+	}
+}
+
+// writeDiagnosticsBundle escreve os arquivos do pacote de diagnóstico no zip
+func writeDiagnosticsBundle(w io.Writer, filter database.LogFilter) error {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+
+	jsonFile := func(value func() interface{}) func(io.Writer) error {
+		return func(fw io.Writer) error {
+			encoder := json.NewEncoder(fw)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(value())
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"system.json", jsonFile(func() interface{} {
+			return map[string]interface{}{
+				"generated_at": now.Format(time.RFC3339),
+				"hostname":     hostname,
+				"os":           runtime.GOOS,
+				"arch":         runtime.GOARCH,
+				"go_version":   runtime.Version(),
+				"goroutines":   runtime.NumGoroutine(),
+				"config_path":  config.SettingsPath(),
+				"app_dir":      config.APP_DIR,
+				"archive_dir":  config.ARCHIVE_DIR,
+			}
+		})},
+		{"config.json", jsonFile(redactedSettings)},
+		{"jobs.json", jsonFile(func() interface{} { return core.GetProcessJobs() })},
+		{"database.json", jsonFile(databaseDiagnostics)},
+		{"license.json", jsonFile(licenseDiagnostics)},
+		{"health.json", jsonFile(func() interface{} { return runHealthChecks(readinessChecks) })},
+		{"metrics.txt", func(fw io.Writer) error {
+			metrics.WriteText(fw)
+			return nil
+		}},
+		{"logs.ndjson", func(fw io.Writer) error {
+			return writeLogExport(fw, ExportFormatNDJSON, filter)
+		}},
+	}
+
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: now})
+		if err == nil {
+			err = file.write(fw)
+		}
+		if err != nil {
+			zw.Close()
+			return fmt.Errorf("erro ao gravar %s: %v", file.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// redactedSettings retorna as configurações atuais com os valores sensíveis ocultados
+func redactedSettings() interface{} {
+	data, err := json.Marshal(config.GetSettings())
+	if err != nil {
+		return map[string]string{"erro": err.Error()}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return map[string]string{"erro": err.Error()}
+	}
+	return redactValue(value)
+}
+
+// redactValue percorre o JSON decodificado ocultando os campos sensíveis
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isSensitiveKey(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child)
+		}
+	}
+	return value
+}
+
+// isSensitiveKey verifica se o nome do campo indica um segredo
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// databaseDiagnostics retorna as estatísticas do banco ou o erro obtido
+func databaseDiagnostics() interface{} {
+	stats, err := database.GetDatabaseStats()
+	if err != nil {
+		return map[string]interface{}{"erro": err.Error()}
+	}
+	return stats
+}
+
+// licenseDiagnostics retorna o estado da licença sem o token
+func licenseDiagnostics() interface{} {
+	if database.PingDatabase() != nil {
+		return map[string]interface{}{"erro": "banco de dados indisponível"}
+	}
+
+	info, err := licenseStore.Get()
+	if err != nil {
+		return map[string]interface{}{"erro": err.Error()}
+	}
+	diagnostics := map[string]interface{}{"configured": false}
+	if info != nil {
+		// LicenseInfo não serializa o token
+		diagnostics = map[string]interface{}{"configured": true, "license": info}
+	}
+
+	// A licença offline não tem segredo: vai com o resultado da verificação
+	if lic, err := license.InstalledOfflineLicense(licenseStore); lic != nil || err != nil {
+		offline := map[string]interface{}{"license": lic}
+		if err != nil {
+			offline["erro"] = err.Error()
+		}
+		diagnostics["offline_license"] = offline
+	}
+	return diagnostics
+}
+
+// writeBadRequest responde 400 com ErrorResponse
+func writeBadRequest(w http.ResponseWriter, message string) {
+	writeError(w, http.StatusBadRequest, message)
+}
+
+// writeError responde com ErrorResponse e o status informado
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Erro: message})
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"go-desktop-app/core"
 )
@@ -29,7 +31,15 @@ type MessageResponse struct {
 
 // ErrorResponse representa uma resposta de erro
 type ErrorResponse struct {
-	Erro string `json:"erro"`
+	Erro     string       `json:"erro"`
+	Detalhes []FieldError `json:"detalhes,omitempty"`
+}
+
+// ExecuteResponse representa a resposta do endpoint de execução de processo
+type ExecuteResponse struct {
+	Mensagem string `json:"mensagem"`
+	JobID    string `json:"job_id"`
+	PID      int    `json:"pid"`
 }
 
 // ExecuteRequest representa a requisição para executar processo
@@ -58,15 +68,16 @@ func ReadFileHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	var req FileRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Erro: "JSON inválido"})
+	if reqErr := decodeRequest(w, r, &req, maxFileRequestBytes); reqErr != nil {
+		auditError(r, reqErr)
+		writeRequestError(w, reqErr)
 		return
 	}
+	auditTarget(r, req.NomeArquivo, nil)
 	
-	content, err := core.ReadFileContent(req.NomeArquivo)
+	content, err := core.ReadFileContent(req.NomeArquivo, RequestIDFromContext(r.Context()))
 	if err != nil {
+		auditError(r, err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(ErrorResponse{Erro: err.Error()})
@@ -86,21 +97,23 @@ func MoveFileHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	var req FileRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Erro: "JSON inválido"})
+	if reqErr := decodeRequest(w, r, &req, maxFileRequestBytes); reqErr != nil {
+		auditError(r, reqErr)
+		writeRequestError(w, reqErr)
 		return
 	}
+	auditTarget(r, req.NomeArquivo, nil)
 	
-	destPath, err := core.MoveFile(req.NomeArquivo)
+	destPath, err := core.MoveFile(req.NomeArquivo, RequestIDFromContext(r.Context()))
 	if err != nil {
+		auditError(r, err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(ErrorResponse{Erro: err.Error()})
 		return
 	}
 	
+	auditTarget(r, "", map[string]string{"destino": destPath})
 	response := MessageResponse{Mensagem: "Arquivo movido com sucesso para " + destPath}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -114,21 +127,28 @@ func ExecuteProcessHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	var req ExecuteRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Erro: "JSON inválido"})
+	if reqErr := decodeRequest(w, r, &req, maxExecuteRequestBytes); reqErr != nil {
+		auditError(r, reqErr)
+		writeRequestError(w, reqErr)
 		return
 	}
+	auditTarget(r, req.CaminhoExecutavel, nil)
 	
-	if err := core.ExecuteProcess(req.CaminhoExecutavel); err != nil {
+	job, err := core.ExecuteProcess(req.CaminhoExecutavel, RequestIDFromContext(r.Context()))
+	if err != nil {
+		auditError(r, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, core.ErrExecutorBusy) {
+			status = http.StatusServiceUnavailable
+		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(ErrorResponse{Erro: err.Error()})
 		return
 	}
 	
-	response := MessageResponse{Mensagem: "Processo iniciado com sucesso"}
+	auditTarget(r, "", map[string]string{"job_id": job.ID, "pid": strconv.Itoa(job.PID)})
+	response := ExecuteResponse{Mensagem: "Processo iniciado com sucesso", JobID: job.ID, PID: job.PID}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"go-desktop-app/config"
+	"go-desktop-app/core"
+	"go-desktop-app/database"
+	"go-desktop-app/license"
+)
+
+// Status possíveis de um health check
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+)
+
+// HealthCheckResult representa o resultado de uma verificação individual
+type HealthCheckResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Message    string  `json:"message"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// HealthResponse representa a resposta de /healthz e /readyz
+type HealthResponse struct {
+	Status    string              `json:"status"`
+	Timestamp string              `json:"timestamp"`
+	Checks    []HealthCheckResult `json:"checks"`
+}
+
+// healthCheck é uma verificação nomeada; retorna a mensagem de sucesso ou um erro
+type healthCheck struct {
+	name  string
+	check func() (string, error)
+}
+
+// processStart é o horário de início do processo, informado pela verificação de liveness
+var processStart = time.Now()
+
+// livenessChecks indicam apenas que o processo responde. Dependências (banco de dados,
+// executor) ficam só em readinessChecks: um banco travado por instantes ou o executor
+// ocupado não devem fazer o supervisor reiniciar um processo saudável.
+var livenessChecks = []healthCheck{
+	{"process", checkProcess},
+}
+
+// readinessChecks indicam se a aplicação está pronta para atender as operações da API
+var readinessChecks = []healthCheck{
+	{"database", checkDatabase},
+	{"app_dir", func() (string, error) { return checkDirectory(config.APP_DIR) }},
+	{"archive_dir", func() (string, error) { return checkDirectory(config.ARCHIVE_DIR) }},
+	{"disk_space", checkDiskSpace},
+	{"license", checkLicense},
+	{"executor", checkExecutor},
+}
+
+// HealthzHandler implementa a verificação de liveness
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthResponse(w, r, livenessChecks)
+}
+
+// ReadyzHandler implementa a verificação de readiness
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthResponse(w, r, readinessChecks)
+}
+
+// writeHealthResponse executa as verificações e responde 503 se alguma falhar
+func writeHealthResponse(w http.ResponseWriter, r *http.Request, checks []healthCheck) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := runHealthChecks(checks)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if response.Status != HealthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// runHealthChecks executa as verificações em sequência
+func runHealthChecks(checks []healthCheck) HealthResponse {
+	response := HealthResponse{
+		Status:    HealthStatusOK,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Checks:    make([]HealthCheckResult, 0, len(checks)),
+	}
+
+	for _, hc := range checks {
+		start := time.Now()
+		message, err := hc.check()
+		result := HealthCheckResult{
+			Name:       hc.name,
+			Status:     HealthStatusOK,
+			Message:    message,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if err != nil {
+			result.Status = HealthStatusDegraded
+			result.Message = err.Error()
+			response.Status = HealthStatusDegraded
+		}
+		response.Checks = append(response.Checks, result)
+	}
+
+	return response
+}
+
+// checkProcess responde enquanto o servidor HTTP atende requisições
+func checkProcess() (string, error) {
+	return fmt.Sprintf("pid %d em execução há %s", os.Getpid(), time.Since(processStart).Round(time.Second)), nil
+}
+
+// checkDatabase verifica a conectividade com o banco de dados
+func checkDatabase() (string, error) {
+	if err := database.PingDatabase(); err != nil {
+		return "", err
+	}
+	return "banco de dados acessível", nil
+}
+
+// checkDirectory verifica se o diretório existe e permite escrita. Um diretório ausente é
+// informado, não criado: a verificação de readiness não altera o sistema de arquivos
+// (o ARCHIVE_DIR é criado por core.MoveFile no primeiro arquivamento).
+func checkDirectory(dir string) (string, error) {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("diretório %s não existe", dir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("diretório %s inacessível: %v", dir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s não é um diretório", dir)
+	}
+
+	// Testa a escrita criando e removendo um arquivo temporário
+	probe, err := os.CreateTemp(dir, ".healthcheck-*")
+	if err != nil {
+		return "", fmt.Errorf("diretório %s sem permissão de escrita: %v", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return fmt.Sprintf("diretório %s acessível para escrita", dir), nil
+}
+
+// checkDiskSpace verifica o espaço livre no volume do APP_DIR
+func checkDiskSpace() (string, error) {
+	freeBytes, err := core.FreeDiskSpace(config.APP_DIR)
+	if err != nil {
+		return "", err
+	}
+
+	freeMB := freeBytes / (1024 * 1024)
+	minMB := config.GetSettings().Health.MinFreeDiskMB
+	if freeMB < minMB {
+		return "", fmt.Errorf("espaço livre insuficiente: %d MB (mínimo %d MB)", freeMB, minMB)
+	}
+	return fmt.Sprintf("%d MB livres", freeMB), nil
+}
+
+// checkLicense verifica se há uma licença ativa configurada (do servidor ou offline)
+func checkLicense() (string, error) {
+	if database.PingDatabase() != nil {
+		return "", fmt.Errorf("banco de dados indisponível para verificar a licença")
+	}
+
+	info, err := licenseStore.Get()
+	if err != nil {
+		return "", err
+	}
+	var hostErr error
+	if info != nil {
+		hostErr = license.CheckHost(info.Fingerprint)
+	}
+	if info == nil || !info.IsActive || hostErr != nil {
+		// Sem licença ativa do servidor, vale a licença offline instalada
+		if lic, err := license.InstalledOfflineLicense(licenseStore); lic != nil && err == nil {
+			return "licença offline ativa até " + lic.ExpiresAt.Format(time.RFC3339), nil
+		}
+	}
+	if info == nil {
+		return "", fmt.Errorf("licença não configurada")
+	}
+	if hostErr != nil {
+		return "", hostErr
+	}
+	if !info.IsActive {
+		return "", fmt.Errorf("licença inativa (última verificação: %s)", info.LastCheck)
+	}
+	return "licença ativa", nil
+}
+
+// checkExecutor verifica se ainda há capacidade para iniciar processos
+func checkExecutor() (string, error) {
+	running := core.RunningProcessCount()
+	max := config.GetSettings().Executor.MaxConcurrentProcesses
+	if max > 0 && running >= max {
+		return "", fmt.Errorf("%d processos em execução (limite %d)", running, max)
+	}
+	return fmt.Sprintf("%d processos em execução", running), nil
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckDirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "arquivo.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr bool
+	}{
+		{"diretório gravável", dir, false},
+		{"diretório ausente", filepath.Join(dir, "arquivo_morto"), true},
+		{"arquivo no lugar do diretório", file, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := checkDirectory(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkDirectory(%s) erro = %v, esperado erro: %v", tt.dir, err, tt.wantErr)
+			}
+		})
+	}
+
+	// A verificação não cria o diretório ausente nem deixa arquivos de teste
+	if _, err := os.Stat(filepath.Join(dir, "arquivo_morto")); !os.IsNotExist(err) {
+		t.Errorf("checkDirectory criou o diretório ausente: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("checkDirectory deixou arquivos em %s: %v", dir, entries)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go-desktop-app/metrics"
+)
+
+// RouteLatency representa o histograma de latência de uma rota
+type RouteLatency struct {
+	Route      string                    `json:"route"`
+	Count      uint64                    `json:"count"`
+	SumSeconds float64                   `json:"sum_seconds"`
+	Buckets    []metrics.HistogramBucket `json:"buckets"`
+}
+
+// LatencyStatsResponse representa a resposta do endpoint de latências
+type LatencyStatsResponse struct {
+	Routes []RouteLatency `json:"routes"`
+}
+
+// observeLatency registra a duração de uma requisição no histograma da rota
+func observeLatency(route string, duration time.Duration) {
+	metrics.HTTPRequestDuration.Observe(duration.Seconds(), route)
+}
+
+// GetRouteLatencies retorna uma cópia dos histogramas de latência por rota
+func GetRouteLatencies() []RouteLatency {
+	snapshots := metrics.HTTPRequestDuration.Snapshot()
+
+	result := make([]RouteLatency, 0, len(snapshots))
+	for _, snap := range snapshots {
+		result = append(result, RouteLatency{
+			Route:      snap.LabelValues[0],
+			Count:      snap.Count,
+			SumSeconds: snap.Sum,
+			Buckets:    snap.Buckets,
+		})
+	}
+	return result
+}
+
+// LatencyStatsHandler retorna os histogramas de latência por rota
+func LatencyStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LatencyStatsResponse{Routes: GetRouteLatencies()})
+}
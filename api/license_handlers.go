@@ -2,18 +2,55 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"go-desktop-app/database"
 	"go-desktop-app/license"
 )
 
+// licenseStore guarda a licença consultada e alterada pelos handlers.
+// Até SetLicenseStore ser chamado, as operações falham com database.ErrLicenseStoreNotConfigured.
+var licenseStore database.LicenseStore = database.UnconfiguredLicenseStore{Owner: "api"}
+
+// SetLicenseStore define o store de licença usado pela API
+func SetLicenseStore(store database.LicenseStore) {
+	licenseStore = store
+}
+
 // LicenseStatusResponse representa a resposta do status da licença
 type LicenseStatusResponse struct {
 	HasLicense bool                   `json:"has_license"`
 	IsValid    bool                   `json:"is_valid"`
 	Info       *database.LicenseInfo  `json:"info,omitempty"`
 	Message    string                 `json:"message"`
+	// Source é a origem do estado: "server" (token) ou "offline" (arquivo de licença)
+	Source string `json:"source,omitempty"`
+	// Error detalha por que a licença não é válida (outra máquina, assinatura, expiração)
+	Error string `json:"error,omitempty"`
+	// OfflineGraceUntil é até quando a licença ativa continua válida sem o servidor de licenças
+	OfflineGraceUntil string `json:"offline_grace_until,omitempty"`
+	// OfflineLicense é a licença offline instalada (também quando vencida)
+	OfflineLicense *license.OfflineLicense `json:"offline_license,omitempty"`
+	// NextCheck é quando a licença será verificada de novo em segundo plano
+	NextCheck string `json:"next_check,omitempty"`
+}
+
+// ImportLicenseRequest é o arquivo de licença offline assinado (license.SignedLicense)
+type ImportLicenseRequest struct {
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// ImportLicenseResponse representa a resposta da importação da licença offline
+type ImportLicenseResponse struct {
+	Success bool                    `json:"success"`
+	Message string                  `json:"message"`
+	License *license.OfflineLicense `json:"license,omitempty"`
+	Errors  []FieldError            `json:"errors,omitempty"`
 }
 
 // SetupLicenseRequest representa a requisição para configurar licença
@@ -24,8 +61,9 @@ type SetupLicenseRequest struct {
 
 // SetupLicenseResponse representa a resposta da configuração de licença
 type SetupLicenseResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors,omitempty"`
 }
 
 // VerifyLicenseResponse representa a resposta da verificação de licença
@@ -34,37 +72,38 @@ type VerifyLicenseResponse struct {
 	Message string `json:"message"`
 }
 
-// LicenseStatusHandler retorna o status atual da licença
+// LicenseHistoryResponse representa a resposta de GET /api/license/history
+type LicenseHistoryResponse struct {
+	Events []database.LicenseEvent `json:"events"`
+	Total  int                     `json:"total"`
+	Limit  int                     `json:"limit"`
+	Offset int                     `json:"offset"`
+}
+
+// LicenseStatusHandler retorna o status atual da licença. O estado vem de license.CurrentStatus,
+// o mesmo usado pela verificação periódica, pelo /readyz e pelo evento LicenseChanged.
 func LicenseStatusHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Verifica se há informações de licença
-	hasLicense := database.HasLicenseInfo()
-	
+	status := license.CurrentStatus(licenseStore)
 	response := LicenseStatusResponse{
-		HasLicense: hasLicense,
-		IsValid:    false,
-		Message:    "Licença não configurada",
-	}
-
-	if hasLicense {
-		// Recupera as informações de licença
-		info, err := database.GetLicenseInfo()
-		if err != nil {
-			response.Message = "Erro ao recuperar informações de licença"
-		} else {
-			response.Info = info
-			response.IsValid = info.IsActive
-			if info.IsActive {
-				response.Message = "Licença ativa"
-			} else {
-				response.Message = "Licença inativa"
-			}
-		}
+		HasLicense:        status.HasLicense,
+		IsValid:           status.IsValid,
+		Message:           status.Message,
+		Source:            status.Source,
+		Error:             status.Error,
+		OfflineGraceUntil: status.OfflineGraceUntil,
+		NextCheck:         status.NextCheck,
+	}
+
+	// Detalhes da licença do servidor e da licença offline instalada (também quando vencida)
+	if info, err := licenseStore.Get(); err == nil {
+		response.Info = info
 	}
+	response.OfflineLicense, _ = license.InstalledOfflineLicense(licenseStore)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -77,40 +116,37 @@ func SetupLicenseHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Decodifica e valida os campos da requisição
 	var req SetupLicenseRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if reqErr := decodeRequest(w, r, &req, maxLicenseRequestBytes); reqErr != nil {
+		auditError(r, reqErr)
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(reqErr.status)
 		json.NewEncoder(w).Encode(SetupLicenseResponse{
 			Success: false,
-			Message: "JSON inválido",
+			Message: reqErr.message,
+			Errors:  reqErr.detalhes,
 		})
 		return
 	}
 
-	// Valida os campos obrigatórios
-	if req.Token == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(SetupLicenseResponse{
-			Success: false,
-			Message: "Token é obrigatório",
-		})
-		return
-	}
-
-	// Define URL padrão se não fornecida
-	apiURL := req.APIUrl
-	if apiURL == "" {
-		apiURL = "http://localhost:8000" // URL padrão da API de licenciamento
+	// Usa a URL configurada da API de licenciamento (api_url só passa pela validação
+	// no modo de desenvolvimento)
+	apiURL := license.CurrentPolicy().ServerURL
+	if req.APIUrl != "" {
+		apiURL = req.APIUrl
 	}
+	// O token nunca é gravado na auditoria
+	auditTarget(r, apiURL, nil)
 
 	// Cria o cliente de licenciamento
-	client := license.NewLicenseClient(apiURL)
+	client := license.NewLicenseClient(apiURL, licenseStore)
+	client.RequestID = RequestIDFromContext(r.Context())
 
 	// Configura a licença
 	err := client.SetupLicense(req.Token)
 	if err != nil {
+		auditError(r, err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(SetupLicenseResponse{
@@ -135,8 +171,9 @@ func VerifyLicenseHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verifica se há informações de licença
-	if !database.HasLicenseInfo() {
+	// Verifica se há informações de licença (do servidor ou offline)
+	if !license.CurrentStatus(licenseStore).HasLicense {
+		auditError(r, errors.New("licença não configurada"))
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(VerifyLicenseResponse{
@@ -146,12 +183,15 @@ func VerifyLicenseHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Cria o cliente de licenciamento (usando URL padrão)
-	client := license.NewLicenseClient("http://localhost:8000")
+	// Cria o cliente de licenciamento (usando a URL configurada)
+	client := license.NewLicenseClient(license.CurrentPolicy().ServerURL, licenseStore)
+	client.RequestID = RequestIDFromContext(r.Context())
+	auditTarget(r, client.BaseURL, nil)
 
 	// Verifica a licença
 	valid, err := client.CheckLicense()
 	if err != nil {
+		auditError(r, err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(VerifyLicenseResponse{
@@ -161,6 +201,8 @@ func VerifyLicenseHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	auditTarget(r, "", map[string]string{"valid": strconv.FormatBool(valid)})
+
 	// Resposta de sucesso
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(VerifyLicenseResponse{
@@ -169,6 +211,49 @@ func VerifyLicenseHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ImportLicenseHandler instala um arquivo de licença offline assinado. O corpo é o
+// conteúdo do arquivo: {"payload": "...", "signature": "..."}
+func ImportLicenseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ImportLicenseRequest
+	if reqErr := decodeRequest(w, r, &req, maxLicenseRequestBytes); reqErr != nil {
+		auditError(r, reqErr)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(reqErr.status)
+		json.NewEncoder(w).Encode(ImportLicenseResponse{
+			Success: false,
+			Message: reqErr.message,
+			Errors:  reqErr.detalhes,
+		})
+		return
+	}
+
+	file := license.SignedLicense{Payload: req.Payload, Signature: req.Signature}
+	lic, err := license.ImportLicense(licenseStore, file, RequestIDFromContext(r.Context()))
+	if err != nil {
+		auditError(r, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ImportLicenseResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	auditTarget(r, lic.LicenseID, map[string]string{"device_uuid": lic.DeviceUUID, "expires_at": lic.ExpiresAt.Format(time.RFC3339)})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImportLicenseResponse{
+		Success: true,
+		Message: "Licença offline importada com sucesso",
+		License: lic,
+	})
+}
+
 // ClearLicenseHandler remove as informações de licença
 func ClearLicenseHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -177,8 +262,9 @@ func ClearLicenseHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Remove as informações de licença
-	err := database.ClearLicenseInfo()
+	err := license.ClearLicense(licenseStore, RequestIDFromContext(r.Context()))
 	if err != nil {
+		auditError(r, err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(SetupLicenseResponse{
@@ -195,3 +281,53 @@ func ClearLicenseHandler(w http.ResponseWriter, r *http.Request) {
 		Message: "Licença removida com sucesso",
 	})
 }
+
+// LicenseHistoryHandler consulta o histórico da licença, da entrada mais recente para a mais antiga.
+// Filtros: type (setup, verify, activated, deactivated, cleared ou imported), from, to, limit e offset.
+func LicenseHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseLicenseEventFilter(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	history, total, err := licenseStore.History(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LicenseHistoryResponse{Events: history, Total: total, Limit: filter.Limit, Offset: filter.Offset})
+}
+
+// parseLicenseEventFilter lê os filtros de /api/license/history
+func parseLicenseEventFilter(r *http.Request) (database.LicenseEventFilter, error) {
+	query := r.URL.Query()
+	filter := database.LicenseEventFilter{Type: query.Get("type")}
+
+	var err error
+	if filter.Limit, filter.Offset, err = parsePage(query.Get("limit"), query.Get("offset")); err != nil {
+		return filter, err
+	}
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = parseLogTime(value); err != nil {
+			return filter, fmt.Errorf("parâmetro from inválido: %v", err)
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = parseLogTime(value); err != nil {
+			return filter, fmt.Errorf("parâmetro to inválido: %v", err)
+		}
+	}
+	if filter.Type != "" && !database.IsLicenseEventType(filter.Type) {
+		return filter, fmt.Errorf("parâmetro type deve ser setup, verify, activated, deactivated, cleared ou imported")
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-desktop-app/database"
+)
+
+// useLicenseStore injeta o store nos handlers e restaura o anterior ao fim do teste
+func useLicenseStore(t *testing.T, store database.LicenseStore) {
+	t.Helper()
+	previous := licenseStore
+	SetLicenseStore(store)
+	t.Cleanup(func() { SetLicenseStore(previous) })
+}
+
+func TestLicenseHandlersWithoutStore(t *testing.T) {
+	useLicenseStore(t, database.UnconfiguredLicenseStore{Owner: "api"})
+
+	rec := httptest.NewRecorder()
+	LicenseHistoryHandler(rec, httptest.NewRequest(http.MethodGet, "/api/license/history", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, esperado 500", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), database.ErrLicenseStoreNotConfigured.Error()) {
+		t.Errorf("resposta sem o erro de store não configurado: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	LicenseStatusHandler(rec, httptest.NewRequest(http.MethodGet, "/api/license/status", nil))
+	if body := rec.Body.String(); strings.Contains(body, `"is_valid":true`) {
+		t.Errorf("licença válida sem store configurado: %s", body)
+	}
+}
+
+func TestLicenseHistoryHandlerFilters(t *testing.T) {
+	store := database.NewMemoryLicenseStore()
+	useLicenseStore(t, store)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, eventType := range []string{database.LicenseEventSetup, database.LicenseEventVerify, database.LicenseEventVerify} {
+		store.RecordEvent(database.LicenseEvent{Timestamp: base.Add(time.Duration(i) * time.Hour), Type: eventType})
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		count  int
+		total  int
+	}{
+		{"sem filtros", "", http.StatusOK, 3, 3},
+		{"por tipo", "?type=verify", http.StatusOK, 2, 2},
+		{"desde", "?from=2026-03-01T13:00:00Z", http.StatusOK, 2, 2},
+		{"até", "?to=2026-03-01T12:30:00Z", http.StatusOK, 1, 1},
+		{"paginação", "?limit=1&offset=1", http.StatusOK, 1, 3},
+		{"tipo desconhecido", "?type=outro", http.StatusBadRequest, 0, 0},
+		{"data inválida", "?from=ontem", http.StatusBadRequest, 0, 0},
+		{"limit inválido", "?limit=0", http.StatusBadRequest, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			LicenseHistoryHandler(rec, httptest.NewRequest(http.MethodGet, "/api/license/history"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, esperado %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var response LicenseHistoryResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("resposta inválida: %v", err)
+			}
+			if len(response.Events) != tt.count || response.Total != tt.total {
+				t.Errorf("%d eventos (total %d), esperado %d (total %d)", len(response.Events), response.Total, tt.count, tt.total)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"go-desktop-app/logging"
+)
+
+// LogFilesResponse representa a resposta de /api/logs/files
+type LogFilesResponse struct {
+	Directory string            `json:"directory"`
+	Files     []logging.LogFile `json:"files"`
+}
+
+// LogFilesHandler lista o arquivo de log atual e os arquivos rotacionados
+func LogFilesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	file := logging.ActiveLogFile()
+	if file == nil {
+		writeError(w, http.StatusNotFound, "Arquivo de log desativado")
+		return
+	}
+
+	files, err := file.Files()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if files == nil {
+		files = []logging.LogFile{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(LogFilesResponse{Directory: file.Directory(), Files: files})
+}
+
+// LogFileDownloadHandler envia um arquivo de log (atual ou rotacionado) para download
+func LogFileDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	file := logging.ActiveLogFile()
+	if file == nil {
+		writeError(w, http.StatusNotFound, "Arquivo de log desativado")
+		return
+	}
+
+	name := r.PathValue("name")
+	path, err := file.Path(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Erro ao abrir arquivo de log")
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Erro ao abrir arquivo de log")
+		return
+	}
+
+	// O arquivo de log tem um objeto JSON por linha
+	contentType := "application/x-ndjson"
+	if strings.HasSuffix(name, ".gz") {
+		contentType = "application/gzip"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
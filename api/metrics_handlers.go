@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"go-desktop-app/metrics"
+)
+
+// Métricas calculadas a partir do estado do pacote api
+var (
+	_ = metrics.NewGaugeFunc("godesktop_sse_clients",
+		"Clientes SSE conectados ao stream de logs.", func() float64 {
+			clientsMutex.RLock()
+			defer clientsMutex.RUnlock()
+			return float64(len(logClients))
+		})
+
+	_ = metrics.NewGaugeFunc("godesktop_websocket_clients",
+		"Clientes conectados ao WebSocket de eventos.", func() float64 {
+			wsClientsMutex.RLock()
+			defer wsClientsMutex.RUnlock()
+			return float64(len(wsClients))
+		})
+
+	_ = metrics.NewGaugeFunc("godesktop_log_buffer_entries",
+		"Entradas no buffer de logs em memória.", func() float64 {
+			logsMutex.RLock()
+			defer logsMutex.RUnlock()
+			return float64(len(logEntries))
+		})
+)
+
+// MetricsHandler expõe as métricas no formato texto do Prometheus
+func MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.WriteText(w)
+}
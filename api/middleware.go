@@ -1,79 +1,171 @@
 package api
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+
+	"go-desktop-app/config"
+	"go-desktop-app/logging"
+	"go-desktop-app/metrics"
 )
 
-// CORSMiddleware adiciona headers CORS permitindo apenas localhost e 127.0.0.1
-func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		
-		// Permite apenas origens localhost e 127.0.0.1
-		if strings.HasPrefix(origin, "http://localhost") || strings.HasPrefix(origin, "http://127.0.0.1") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-		}
-		
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-		
-		next.ServeHTTP(w, r)
-	})
+// accessLogger registra o log de acesso HTTP
+var accessLogger = logging.Component("http")
+
+// routeContextKey é a chave da rota atendida no contexto da requisição
+type routeContextKey struct{}
+
+// routeHolder recebe a rota atendida. O ServeMux preenche r.Pattern apenas na cópia da
+// requisição que recebe; os middlewares que trocam o contexto (como a auditoria) criam
+// cópias, então a rota volta ao log de acesso por este ponteiro.
+type routeHolder struct {
+	pattern string
+}
+
+// setRoute informa ao log de acesso a rota que atendeu (ou recusou) a requisição
+func setRoute(r *http.Request, pattern string) {
+	if holder, ok := r.Context().Value(routeContextKey{}).(*routeHolder); ok {
+		holder.pattern = pattern
+	}
+}
+
+// serveMux atende a requisição pelo mux e informa a rota registrada ao log de acesso
+func serveMux(mux *http.ServeMux, w http.ResponseWriter, r *http.Request) {
+	mux.ServeHTTP(w, r)
+	setRoute(r, r.Pattern)
 }
 
-// LoggingMiddleware registra todas as requisições
+// LoggingMiddleware registra as requisições no log de acesso estruturado e alimenta os histogramas de latência
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		
-		// Cria um ResponseWriter customizado para capturar o status code
+		// Cria um ResponseWriter customizado para capturar o status code e os bytes
 		lrw := &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 		
+		holder := &routeHolder{}
+		r = r.WithContext(context.WithValue(r.Context(), routeContextKey{}, holder))
 		next.ServeHTTP(lrw, r)
 
 		duration := time.Since(start)
 
-		// Gera log apenas para rotas específicas da API
-		apiRoutes := []string{"/status", "/escreve_arquivo", "/move_arquivo", "/executar_terceiros"}
-		shouldLog := false
-		for _, route := range apiRoutes {
-			if r.RequestURI == route || strings.HasPrefix(r.RequestURI, route+"?") {
-				shouldLog = true
-				break
-			}
+		// A rota registrada que atendeu a requisição (informada por serveMux ou pelo rate limit)
+		route := holder.pattern
+		if route == "" {
+			route = r.Pattern
+		}
+		if route == "" {
+			route = "unmatched"
 		}
 
-		if shouldLog {
-			logMessage := fmt.Sprintf("[%s] %s %s - %d - %v",
-				r.Method,
-				r.RequestURI,
-				r.RemoteAddr,
-				lrw.statusCode,
-				duration)
+		observeLatency(route, duration)
+		metrics.HTTPRequests.Inc(route, r.Method, strconv.Itoa(lrw.statusCode))
 
-			log.Print(logMessage)
-			AddLogEntry(logMessage)
+		if !shouldLogRoute(route) {
+			return
 		}
+
+		accessLogger.LogAttrs(r.Context(), statusLogLevel(lrw.statusCode),
+			fmt.Sprintf("%s %s %d", r.Method, r.URL.Path, lrw.statusCode),
+			slog.String(logging.RequestIDKey, RequestIDFromContext(r.Context())),
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", lrw.statusCode),
+			slog.Int64("bytes", lrw.bytes),
+			slog.Float64("duration_ms", float64(duration.Microseconds())/1000),
+			slog.String("client", clientAddress(r)),
+		)
 	})
 }
 
-// loggingResponseWriter é um wrapper para capturar o status code
+// shouldLogRoute aplica as listas de inclusão e exclusão configuradas
+func shouldLogRoute(route string) bool {
+	settings := config.GetSettings().AccessLog
+
+	if len(settings.IncludeRoutes) > 0 && !matchRoute(settings.IncludeRoutes, route) {
+		return false
+	}
+	return !matchRoute(settings.ExcludeRoutes, route)
+}
+
+// matchRoute verifica se a rota casa com algum dos padrões (sufixo "*" = prefixo)
+func matchRoute(patterns []string, route string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(route, prefix) {
+				return true
+			}
+		} else if route == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// statusLogLevel converte o status HTTP no nível do log de acesso
+func statusLogLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// clientAddress retorna o endereço do cliente sem a porta
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// loggingResponseWriter é um wrapper para capturar o status code e o tamanho da resposta
 type loggingResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int64
 }
 
 func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.statusCode = code
 	lrw.ResponseWriter.WriteHeader(code)
 }
+
+func (lrw *loggingResponseWriter) Write(b []byte) (int, error) {
+	n, err := lrw.ResponseWriter.Write(b)
+	lrw.bytes += int64(n)
+	return n, err
+}
+
+// Flush repassa o flush para o writer original (necessário para SSE)
+func (lrw *loggingResponseWriter) Flush() {
+	if flusher, ok := lrw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack repassa o hijack para o writer original (necessário para WebSocket)
+func (lrw *loggingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(lrw.ResponseWriter).Hijack()
+	if err == nil {
+		lrw.statusCode = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap permite que http.ResponseController acesse o writer original
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
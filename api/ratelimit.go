@@ -0,0 +1,157 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-desktop-app/config"
+	"go-desktop-app/metrics"
+)
+
+// APIKeyHeader identifica o cliente para o rate limit e a auditoria quando traz uma key
+// cadastrada em api_keys
+const APIKeyHeader = "X-API-Key"
+
+// bucketIdleTimeout define quando um bucket sem uso pode ser descartado
+const bucketIdleTimeout = 10 * time.Minute
+
+// tokenBucket guarda os tokens disponíveis de um cliente em um grupo
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+var (
+	buckets      = make(map[string]*tokenBucket)
+	bucketsMutex sync.Mutex
+	lastSweep    time.Time
+)
+
+// RateLimitMiddleware aplica o token bucket configurado para o grupo da rota.
+// Recebe o mux para descobrir o padrão da rota antes de atendê-la, de forma
+// que os grupos usem os mesmos padrões do log de acesso.
+func RateLimitMiddleware(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		settings := config.GetSettings().RateLimit
+		if !settings.Enabled {
+			serveMux(mux, w, r)
+			return
+		}
+
+		_, route := mux.Handler(r)
+		group, ok := findRateLimitGroup(settings.Groups, route)
+		if !ok {
+			serveMux(mux, w, r)
+			return
+		}
+
+		allowed, retryAfter := takeToken(group, clientIdentity(r))
+		if !allowed {
+			metrics.RateLimitRejected.Inc(group.Name)
+			// Informa a rota para que o log de acesso identifique a requisição rejeitada
+			setRoute(r, route)
+
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Erro: fmt.Sprintf("Limite de requisições excedido, tente novamente em %d s", seconds),
+			})
+			return
+		}
+
+		metrics.RateLimitAllowed.Inc(group.Name)
+		serveMux(mux, w, r)
+	})
+}
+
+// findRateLimitGroup retorna o primeiro grupo válido que contém a rota
+func findRateLimitGroup(groups []config.RateLimitGroup, route string) (config.RateLimitGroup, bool) {
+	for _, group := range groups {
+		if group.RequestsPerSecond > 0 && group.Burst > 0 && matchRoute(group.Routes, route) {
+			return group, true
+		}
+	}
+	return config.RateLimitGroup{}, false
+}
+
+// clientIdentity identifica o cliente pela API key (hash) quando ela está cadastrada em
+// api_keys, senão pelo endereço remoto. Uma key desconhecida não muda a identidade: do
+// contrário, trocar o header a cada requisição escaparia do rate limit.
+func clientIdentity(r *http.Request) string {
+	if hash, ok := authenticatedAPIKey(r); ok {
+		return "key:" + hash[:16]
+	}
+	return "addr:" + clientAddress(r)
+}
+
+// authenticatedAPIKey retorna o SHA-256 (hex) da API key da requisição se ela estiver
+// cadastrada em api_keys
+func authenticatedAPIKey(r *http.Request) (string, bool) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	for _, allowed := range config.GetSettings().APIKeys {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(strings.ToLower(allowed))) == 1 {
+			return hash, true
+		}
+	}
+	return "", false
+}
+
+// takeToken consome um token do bucket do cliente; se não houver, retorna a espera necessária
+func takeToken(group config.RateLimitGroup, identity string) (bool, time.Duration) {
+	now := time.Now()
+	key := group.Name + "|" + identity
+
+	bucketsMutex.Lock()
+	defer bucketsMutex.Unlock()
+
+	sweepBuckets(now)
+
+	bucket, ok := buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(group.Burst), lastSeen: now}
+		buckets[key] = bucket
+	}
+
+	// Repõe os tokens proporcionalmente ao tempo decorrido
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens = math.Min(float64(group.Burst), bucket.tokens+elapsed*group.RequestsPerSecond)
+	bucket.lastSeen = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	missing := 1 - bucket.tokens
+	return false, time.Duration(missing / group.RequestsPerSecond * float64(time.Second))
+}
+
+// sweepBuckets remove buckets ociosos (chamado com bucketsMutex travado)
+func sweepBuckets(now time.Time) {
+	if now.Sub(lastSweep) < time.Minute {
+		return
+	}
+	lastSweep = now
+
+	for key, bucket := range buckets {
+		if now.Sub(bucket.lastSeen) > bucketIdleTimeout {
+			delete(buckets, key)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-desktop-app/config"
+)
+
+// loadTestSettings carrega o config.json informado e volta aos padrões ao fim do teste
+func loadTestSettings(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.LoadSettings(path); err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	t.Cleanup(func() { config.LoadSettings(filepath.Join(t.TempDir(), "ausente.json")) })
+}
+
+// resetBuckets descarta os buckets do rate limit deixados por outros testes
+func resetBuckets() {
+	bucketsMutex.Lock()
+	defer bucketsMutex.Unlock()
+	buckets = make(map[string]*tokenBucket)
+}
+
+func TestTakeToken(t *testing.T) {
+	group := config.RateLimitGroup{Name: "teste", RequestsPerSecond: 2, Burst: 3}
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration // tempo simulado antes da próxima requisição
+		allowed bool
+	}{
+		{"1ª requisição do burst", 0, true},
+		{"2ª requisição do burst", 0, true},
+		{"3ª requisição do burst", 0, true},
+		{"burst esgotado", 0, false},
+		{"meio segundo repõe um token", 500 * time.Millisecond, true},
+		{"sem tempo para repor", 0, false},
+		{"a reposição não passa do burst", time.Hour, true},
+		{"2ª depois da pausa", 0, true},
+		{"3ª depois da pausa", 0, true},
+		{"burst esgotado de novo", 0, false},
+	}
+	resetBuckets()
+	for _, tt := range tests {
+		if tt.elapsed > 0 {
+			// Simula a passagem do tempo recuando o último uso do bucket
+			bucketsMutex.Lock()
+			buckets[group.Name+"|addr:10.0.0.1"].lastSeen = time.Now().Add(-tt.elapsed)
+			bucketsMutex.Unlock()
+		}
+		allowed, retryAfter := takeToken(group, "addr:10.0.0.1")
+		if allowed != tt.allowed {
+			t.Fatalf("%s: takeToken = %v, esperado %v", tt.name, allowed, tt.allowed)
+		}
+		if !allowed && (retryAfter <= 0 || retryAfter > 500*time.Millisecond) {
+			t.Errorf("%s: espera = %v, esperado até 500ms (2 tokens/s)", tt.name, retryAfter)
+		}
+	}
+
+	// Outro cliente tem o próprio bucket
+	if allowed, _ := takeToken(group, "addr:10.0.0.2"); !allowed {
+		t.Error("bucket de um cliente afetou outro cliente")
+	}
+}
+
+func TestClientIdentity(t *testing.T) {
+	const registered = "chave-cadastrada"
+	sum := sha256.Sum256([]byte(registered))
+	loadTestSettings(t, `{"api_keys":["`+hex.EncodeToString(sum[:])+`"]}`)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		apiKey     string
+		want       string
+	}{
+		{"sem API key usa o endereço", "192.0.2.10:51000", "", "addr:192.0.2.10"},
+		{"a porta de origem não muda a identidade", "192.0.2.10:51999", "", "addr:192.0.2.10"},
+		{"key desconhecida usa o endereço", "192.0.2.10:51000", "qualquer", "addr:192.0.2.10"},
+		{"outra key desconhecida, mesmo cliente", "192.0.2.10:51000", "outra-qualquer", "addr:192.0.2.10"},
+		{"key cadastrada", "192.0.2.10:51000", registered, "key:" + hex.EncodeToString(sum[:])[:16]},
+		{"key cadastrada em outro endereço", "198.51.100.7:40000", registered, "key:" + hex.EncodeToString(sum[:])[:16]},
+		{"IPv6", "[2001:db8::1]:8080", "", "addr:2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/logs", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.apiKey != "" {
+				r.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			if got := clientIdentity(r); got != tt.want {
+				t.Errorf("clientIdentity = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	loadTestSettings(t, `{"rate_limit":{"enabled":true,"groups":[
+		{"name":"execucao","routes":["POST /executar_terceiros"],"requests_per_second":0.001,"burst":2}]}}`)
+	resetBuckets()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /executar_terceiros", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {})
+	handler := RateLimitMiddleware(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		apiKey string
+		status int
+	}{
+		{"1ª execução", http.MethodPost, "/executar_terceiros", "", http.StatusOK},
+		{"2ª execução", http.MethodPost, "/executar_terceiros", "", http.StatusOK},
+		{"burst esgotado", http.MethodPost, "/executar_terceiros", "", http.StatusTooManyRequests},
+		{"trocar a key não escapa do limite", http.MethodPost, "/executar_terceiros", "nova-key", http.StatusTooManyRequests},
+		{"rota fora do grupo", http.MethodGet, "/status", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		r.RemoteAddr = "192.0.2.10:51000"
+		if tt.apiKey != "" {
+			r.Header.Set(APIKeyHeader, tt.apiKey)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Fatalf("%s: status = %d, esperado %d", tt.name, w.Code, tt.status)
+		}
+		if tt.status == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: resposta 429 sem Retry-After", tt.name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader é o header usado para propagar o ID da requisição
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limita o tamanho de IDs recebidos de clientes
+const maxRequestIDLength = 128
+
+type contextKey string
+
+const requestIDKey contextKey = "request_id"
+
+// RequestIDMiddleware garante que toda requisição tenha um X-Request-ID.
+// Um ID enviado pelo cliente é reaproveitado se for válido; caso contrário um novo é gerado.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := context.WithValue(r.Context(), requestIDKey, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext retorna o ID da requisição armazenado no contexto
+func RequestIDFromContext(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDKey).(string); ok {
+		return requestID
+	}
+	return ""
+}
+
+// isValidRequestID aceita apenas IDs curtos com caracteres seguros para logs e headers
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -2,14 +2,19 @@ package api
 
 import (
 	"embed"
-	"log"
 	"net/http"
+	"os"
 
 	"go-desktop-app/config"
+	"go-desktop-app/events"
+	"go-desktop-app/logging"
 )
 
 var webFiles embed.FS
 
+// logger é o logger do componente api
+var logger = logging.Component("api")
+
 // SetWebFiles define os arquivos web embarcados
 func SetWebFiles(files embed.FS) {
 	webFiles = files
@@ -22,6 +27,8 @@ func StartServer() {
 
 	// Registra as rotas da API
 	mux.HandleFunc("/status", StatusHandler)
+	mux.HandleFunc("/healthz", HealthzHandler)
+	mux.HandleFunc("/readyz", ReadyzHandler)
 	mux.HandleFunc("/escreve_arquivo", ReadFileHandler)
 	mux.HandleFunc("/move_arquivo", MoveFileHandler)
 	mux.HandleFunc("/executar_terceiros", ExecuteProcessHandler)
@@ -30,24 +37,50 @@ func StartServer() {
 	mux.HandleFunc("/api/logs", LogsAPIHandler)
 	mux.HandleFunc("/api/logs/clear", ClearLogsHandler)
 	mux.HandleFunc("/api/logs/stream", LogsStreamHandler)
+	mux.HandleFunc("/api/ws", WebSocketHandler)
+	mux.HandleFunc("/api/logs/export", LogsExportHandler)
+	mux.HandleFunc("/api/logs/files", LogFilesHandler)
+	mux.HandleFunc("/api/logs/files/{name}", LogFileDownloadHandler)
+	mux.HandleFunc("/api/diagnostics/bundle", DiagnosticsBundleHandler)
+	mux.HandleFunc("/api/stats/latency", LatencyStatsHandler)
+	mux.HandleFunc("/metrics", MetricsHandler)
+	mux.HandleFunc("/api/config/reload", ReloadConfigHandler)
+
+	// Registra as rotas de webhooks
+	mux.HandleFunc("/api/webhooks", WebhooksHandler)
+	mux.HandleFunc("/api/webhooks/{id}", WebhookHandler)
+	mux.HandleFunc("/api/webhooks/deliveries", WebhookDeliveriesHandler)
+	mux.HandleFunc("/api/webhooks/deliveries/{id}/replay", ReplayWebhookDeliveryHandler)
+	mux.HandleFunc("/api/webhooks/dead-letters", WebhookDeadLettersHandler)
+
+	// Registra as rotas da trilha de auditoria
+	mux.HandleFunc("/api/audit", AuditHandler)
+	mux.HandleFunc("/api/audit/export", AuditExportHandler)
+	mux.HandleFunc("/api/audit/verify", AuditVerifyHandler)
 
 	// Registra as rotas de licenciamento
 	mux.HandleFunc("/api/license/status", LicenseStatusHandler)
 	mux.HandleFunc("/api/license/setup", SetupLicenseHandler)
 	mux.HandleFunc("/api/license/verify", VerifyLicenseHandler)
+	mux.HandleFunc("/api/license/import", ImportLicenseHandler)
 	mux.HandleFunc("/api/license/clear", ClearLicenseHandler)
+	mux.HandleFunc("/api/license/history", LicenseHistoryHandler)
 
 	// Registra o handler para servir arquivos estáticos (deve ser o último)
 	mux.HandleFunc("/", WebHandler)
 
+	// Repassa os eventos do barramento aos clientes WebSocket
+	events.Subscribe("websocket", forwardBusEvent)
+
 	// Aplica os middlewares
-	handler := LoggingMiddleware(CORSMiddleware(mux))
+	handler := RequestIDMiddleware(LoggingMiddleware(CORSMiddleware(AuditMiddleware(RateLimitMiddleware(mux)))))
 
-	log.Printf("Servidor API iniciado na porta %s", config.API_PORT)
-	log.Printf("Interface web disponível em: http://localhost%s", config.API_PORT)
+	logger.Info("Servidor API iniciado", "port", config.API_PORT)
+	logger.Info("Interface web disponível", "url", "http://localhost"+config.API_PORT)
 
 	// Inicia o servidor
 	if err := http.ListenAndServe(config.API_PORT, handler); err != nil {
-		log.Fatalf("Erro ao iniciar servidor: %v", err)
+		logger.Error("Erro ao iniciar servidor", "error", err)
+		os.Exit(1)
 	}
 }
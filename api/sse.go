@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-desktop-app/database"
+	"go-desktop-app/metrics"
+)
+
+// Parâmetros do stream SSE de logs
+const (
+	sseClientBuffer      = 100
+	sseHeartbeatInterval = 15 * time.Second
+	sseRetryMillis       = 3000
+)
+
+// logClient é um cliente conectado ao stream SSE com seus filtros
+type logClient struct {
+	entries chan LogEntry
+	filter  database.LogFilter
+	dropped atomic.Int64
+}
+
+var (
+	logClients   []*logClient
+	clientsMutex sync.RWMutex
+)
+
+// notifyClients envia o novo log para os clientes SSE cujos filtros aceitam a entrada.
+// Clientes lentos não bloqueiam quem registrou o log: a entrada é descartada e contada,
+// e o cliente recebe um aviso "dropped" com a quantidade perdida.
+func notifyClients(entry LogEntry) {
+	clientsMutex.RLock()
+	defer clientsMutex.RUnlock()
+
+	for _, client := range logClients {
+		if !logEntryMatches(entry, client.filter) {
+			continue
+		}
+		select {
+		case client.entries <- entry:
+		default:
+			client.dropped.Add(1)
+			metrics.SSEDroppedEvents.Inc()
+		}
+	}
+
+	publishLogEvent(entry)
+}
+
+// LogsStreamHandler implementa Server-Sent Events para logs em tempo real.
+// Cada evento tem o ID da entrada; ao reconectar com Last-Event-ID (header ou
+// parâmetro last_event_id) o stream continua a partir da entrada seguinte.
+// Sem Last-Event-ID, replay=N reenvia as N entradas mais recentes.
+// Os filtros level, q e request_id funcionam como em /api/logs.
+func LogsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseLogFilter(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	lastEventID, replay, err := parseStreamPosition(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming não suportado", http.StatusInternalServerError)
+		return
+	}
+
+	// Configura headers para SSE
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Registra o cliente antes do replay para não perder entradas no intervalo;
+	// duplicatas são descartadas comparando com o último ID enviado
+	client := &logClient{entries: make(chan LogEntry, sseClientBuffer), filter: filter}
+	clientsMutex.Lock()
+	logClients = append(logClients, client)
+	clientCount := len(logClients)
+	clientsMutex.Unlock()
+
+	logger.Debug("Novo cliente SSE conectado", "client", clientAddress(r), "clients", clientCount,
+		"last_event_id", lastEventID)
+
+	// Remove o cliente quando a conexão fechar
+	defer func() {
+		clientsMutex.Lock()
+		for i, c := range logClients {
+			if c == client {
+				logClients = append(logClients[:i], logClients[i+1:]...)
+				break
+			}
+		}
+		clientsMutex.Unlock()
+	}()
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+
+	backlog, missed := replayEntries(filter, lastEventID, replay)
+	if missed > 0 {
+		// As entradas após o Last-Event-ID já saíram do buffer em memória
+		writeDroppedEvent(w, missed)
+	}
+
+	sent := lastEventID
+	for _, entry := range backlog {
+		writeLogEvent(w, entry)
+		sent = entry.ID
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Escuta por novos logs, heartbeat ou desconexão
+	for {
+		select {
+		case entry := <-client.entries:
+			if entry.ID <= sent {
+				continue
+			}
+			if dropped := client.dropped.Swap(0); dropped > 0 {
+				writeDroppedEvent(w, dropped)
+			}
+			writeLogEvent(w, entry)
+			sent = entry.ID
+			flusher.Flush()
+		case <-heartbeat.C:
+			if dropped := client.dropped.Swap(0); dropped > 0 {
+				writeDroppedEvent(w, dropped)
+			}
+			// Comentário SSE: mantém a conexão viva através de proxies
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			// Cliente desconectou
+			logger.Debug("Cliente SSE desconectou", "client", clientAddress(r))
+			return
+		}
+	}
+}
+
+// parseStreamPosition lê o Last-Event-ID (header ou parâmetro) e o parâmetro replay
+func parseStreamPosition(r *http.Request) (int64, int, error) {
+	var lastEventID int64
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 0 {
+			return 0, 0, fmt.Errorf("Last-Event-ID inválido")
+		}
+		lastEventID = id
+	}
+
+	replay := 0
+	if value := r.URL.Query().Get("replay"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > maxLogBuffer {
+			return 0, 0, fmt.Errorf("parâmetro replay deve estar entre 0 e %d", maxLogBuffer)
+		}
+		replay = n
+	}
+
+	return lastEventID, replay, nil
+}
+
+// replayEntries seleciona as entradas do buffer a reenviar na conexão.
+// Com lastEventID, retorna as entradas posteriores e quantas já saíram do buffer;
+// sem ele, as replay entradas mais recentes que atendem aos filtros.
+func replayEntries(filter database.LogFilter, lastEventID int64, replay int) ([]LogEntry, int64) {
+	logsMutex.RLock()
+	defer logsMutex.RUnlock()
+
+	var backlog []LogEntry
+	var missed int64
+
+	if lastEventID > 0 {
+		// IDs são sequenciais nesta execução: a lacuna até a entrada mais antiga do buffer
+		// foi perdida. IDs de uma execução anterior não permitem calcular a lacuna.
+		if len(logEntries) > 0 && lastEventID >= logSequenceStart && logEntries[0].ID > lastEventID+1 {
+			missed = logEntries[0].ID - lastEventID - 1
+		}
+		for _, entry := range logEntries {
+			if entry.ID > lastEventID && logEntryMatches(entry, filter) {
+				backlog = append(backlog, entry)
+			}
+		}
+		return backlog, missed
+	}
+
+	for i := len(logEntries) - 1; i >= 0 && len(backlog) < replay; i-- {
+		if logEntryMatches(logEntries[i], filter) {
+			backlog = append(backlog, logEntries[i])
+		}
+	}
+	for i, j := 0, len(backlog)-1; i < j; i, j = i+1, j-1 {
+		backlog[i], backlog[j] = backlog[j], backlog[i]
+	}
+	return backlog, 0
+}
+
+// writeLogEvent escreve uma entrada como evento SSE com ID
+func writeLogEvent(w http.ResponseWriter, entry LogEntry) {
+	data, _ := json.Marshal(entry)
+	fmt.Fprintf(w, "id: %d\ndata: %s\n\n", entry.ID, data)
+}
+
+// writeDroppedEvent avisa o cliente de que entradas foram descartadas
+func writeDroppedEvent(w http.ResponseWriter, dropped int64) {
+	fmt.Fprintf(w, "event: dropped\ndata: {\"dropped\":%d}\n\n", dropped)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setLogBuffer substitui o buffer em memória por n entradas com IDs sequenciais a partir de
+// first (relativo a logSequenceStart); o buffer original é restaurado ao fim do teste
+func setLogBuffer(t *testing.T, first, n int64) {
+	t.Helper()
+	logsMutex.Lock()
+	previous := logEntries
+	logEntries = nil
+	for i := int64(0); i < n; i++ {
+		logType := "info"
+		if i%2 == 1 {
+			logType = "error"
+		}
+		logEntries = append(logEntries, LogEntry{
+			ID:      logSequenceStart + first + i,
+			Content: fmt.Sprintf("entrada %d", first+i),
+			Type:    logType,
+		})
+	}
+	logsMutex.Unlock()
+
+	t.Cleanup(func() {
+		logsMutex.Lock()
+		logEntries = previous
+		logsMutex.Unlock()
+	})
+}
+
+// streamLogs executa o stream com a conexão já encerrada: o handler envia o replay e retorna
+func streamLogs(t *testing.T, target string, lastEventID string) string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	rec := httptest.NewRecorder()
+	LogsStreamHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, esperado 200: %s", rec.Code, rec.Body.String())
+	}
+	return rec.Body.String()
+}
+
+// eventIDs extrai os IDs dos eventos do stream, relativos a logSequenceStart
+func eventIDs(body string) []int64 {
+	var ids []int64
+	for _, line := range strings.Split(body, "\n") {
+		var id int64
+		if _, err := fmt.Sscanf(line, "id: %d", &id); err == nil {
+			ids = append(ids, id-logSequenceStart)
+		}
+	}
+	return ids
+}
+
+func TestLogsStreamResume(t *testing.T) {
+	setLogBuffer(t, 1, 6) // IDs 1 a 6; os pares são error
+
+	id := func(n int64) string { return fmt.Sprint(logSequenceStart + n) }
+	tests := []struct {
+		name        string
+		target      string
+		lastEventID string
+		expected    string
+	}{
+		{"sem Last-Event-ID nem replay", "/api/logs/stream", "", "[]"},
+		{"replay das mais recentes", "/api/logs/stream?replay=2", "", "[5 6]"},
+		{"continua após o Last-Event-ID", "/api/logs/stream", id(3), "[4 5 6]"},
+		{"Last-Event-ID por parâmetro", "/api/logs/stream?last_event_id=" + id(4), "", "[5 6]"},
+		{"Last-Event-ID ignora replay", "/api/logs/stream?replay=6", id(5), "[6]"},
+		{"Last-Event-ID com filtro", "/api/logs/stream?level=error", id(1), "[2 4 6]"},
+		{"Last-Event-ID atualizado", "/api/logs/stream", id(6), "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := streamLogs(t, tt.target, tt.lastEventID)
+			if got := fmt.Sprint(eventIDs(body)); got != tt.expected {
+				t.Errorf("eventos = %s, esperado %s", got, tt.expected)
+			}
+			if strings.Contains(body, "event: dropped") {
+				t.Errorf("aviso de descarte inesperado: %s", body)
+			}
+		})
+	}
+}
+
+func TestLogsStreamResumeAfterBufferLoss(t *testing.T) {
+	setLogBuffer(t, 5, 3) // IDs 5 a 7; 3 e 4 já saíram do buffer
+
+	body := streamLogs(t, "/api/logs/stream", fmt.Sprint(logSequenceStart+2))
+	if !strings.Contains(body, `event: dropped`+"\n"+`data: {"dropped":2}`) {
+		t.Errorf("esperado aviso de 2 entradas perdidas: %s", body)
+	}
+	if got := fmt.Sprint(eventIDs(body)); got != "[5 6 7]" {
+		t.Errorf("eventos = %s, esperado [5 6 7]", got)
+	}
+
+	// Um ID de uma execução anterior não permite calcular a lacuna
+	body = streamLogs(t, "/api/logs/stream", "42")
+	if strings.Contains(body, "event: dropped") {
+		t.Errorf("aviso de descarte inesperado para ID de outra execução: %s", body)
+	}
+}
+
+func TestLogsStreamInvalidPosition(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		lastEventID string
+	}{
+		{"Last-Event-ID não numérico", "/api/logs/stream", "abc"},
+		{"Last-Event-ID negativo", "/api/logs/stream", "-1"},
+		{"replay acima do buffer", fmt.Sprintf("/api/logs/stream?replay=%d", maxLogBuffer+1), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			rec := httptest.NewRecorder()
+			LogsStreamHandler(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, esperado 400", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"go-desktop-app/license"
+)
+
+// Limites de tamanho do corpo das requisições
+const (
+	maxFileRequestBytes    = 4 << 10
+	maxExecuteRequestBytes = 8 << 10
+	maxLicenseRequestBytes = 16 << 10
+	maxWebhookRequestBytes = 8 << 10
+)
+
+// Limites de tamanho dos campos
+const (
+	maxFileNameLength   = 255
+	maxExecutableLength = 1024
+	maxTokenLength      = 512
+	maxURLLength        = 2048
+	maxSecretLength     = 256
+)
+
+// FieldError descreve um erro de validação em um campo da requisição
+type FieldError struct {
+	Campo    string `json:"campo"`
+	Mensagem string `json:"mensagem"`
+}
+
+// validatable é implementado pelos tipos de requisição que sabem se validar
+type validatable interface {
+	Validate() []FieldError
+}
+
+// requestError representa uma falha ao decodificar ou validar uma requisição
+type requestError struct {
+	status   int
+	message  string
+	detalhes []FieldError
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// decodeRequest lê o corpo JSON com limite de tamanho, rejeita campos desconhecidos
+// e executa a validação do tipo de destino
+func decodeRequest(w http.ResponseWriter, r *http.Request, dst validatable, maxBytes int64) *requestError {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err, maxBytes)
+	}
+
+	// O corpo deve conter um único objeto JSON
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return &requestError{status: http.StatusBadRequest, message: "JSON inválido: conteúdo extra após o objeto"}
+	}
+
+	if detalhes := dst.Validate(); len(detalhes) > 0 {
+		return &requestError{status: http.StatusBadRequest, message: "Requisição inválida", detalhes: detalhes}
+	}
+
+	return nil
+}
+
+// decodeError converte erros do decoder em respostas com detalhes por campo
+func decodeError(err error, maxBytes int64) *requestError {
+	var maxBytesErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &requestError{
+			status:  http.StatusRequestEntityTooLarge,
+			message: fmt.Sprintf("Corpo da requisição excede %d bytes", maxBytes),
+		}
+	case errors.Is(err, io.EOF):
+		return &requestError{status: http.StatusBadRequest, message: "Corpo da requisição vazio"}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return &requestError{status: http.StatusBadRequest, message: "JSON inválido"}
+	case errors.As(err, &typeErr):
+		return &requestError{
+			status:  http.StatusBadRequest,
+			message: "JSON inválido",
+			detalhes: []FieldError{{
+				Campo:    typeErr.Field,
+				Mensagem: fmt.Sprintf("tipo inválido, esperado %s", typeErr.Type),
+			}},
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &requestError{
+			status:   http.StatusBadRequest,
+			message:  "JSON inválido",
+			detalhes: []FieldError{{Campo: field, Mensagem: "campo desconhecido"}},
+		}
+	default:
+		return &requestError{status: http.StatusBadRequest, message: "JSON inválido"}
+	}
+}
+
+// writeRequestError responde com ErrorResponse para erros de decodificação/validação
+func writeRequestError(w http.ResponseWriter, reqErr *requestError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(reqErr.status)
+	json.NewEncoder(w).Encode(ErrorResponse{Erro: reqErr.message, Detalhes: reqErr.detalhes})
+}
+
+// Validate valida o nome do arquivo: obrigatório e sem componentes de caminho
+func (req *FileRequest) Validate() []FieldError {
+	var errs []FieldError
+	name := req.NomeArquivo
+
+	switch {
+	case strings.TrimSpace(name) == "":
+		errs = append(errs, FieldError{"nome_arquivo", "campo obrigatório"})
+	case len(name) > maxFileNameLength:
+		errs = append(errs, FieldError{"nome_arquivo", fmt.Sprintf("máximo de %d caracteres", maxFileNameLength)})
+	case name == "." || name == "..":
+		errs = append(errs, FieldError{"nome_arquivo", "nome de arquivo inválido"})
+	case strings.ContainsAny(name, `/\:<>"|?*`):
+		errs = append(errs, FieldError{"nome_arquivo", `não pode conter caminhos nem os caracteres / \ : < > " | ? *`})
+	case hasControlChars(name):
+		errs = append(errs, FieldError{"nome_arquivo", "não pode conter caracteres de controle"})
+	}
+
+	return errs
+}
+
+// Validate valida o caminho do executável: obrigatório e absoluto
+func (req *ExecuteRequest) Validate() []FieldError {
+	var errs []FieldError
+	path := req.CaminhoExecutavel
+
+	switch {
+	case strings.TrimSpace(path) == "":
+		errs = append(errs, FieldError{"caminho_executavel", "campo obrigatório"})
+	case len(path) > maxExecutableLength:
+		errs = append(errs, FieldError{"caminho_executavel", fmt.Sprintf("máximo de %d caracteres", maxExecutableLength)})
+	case hasControlChars(path):
+		errs = append(errs, FieldError{"caminho_executavel", "não pode conter caracteres de controle"})
+	case !filepath.IsAbs(path):
+		errs = append(errs, FieldError{"caminho_executavel", "deve ser um caminho absoluto"})
+	}
+
+	return errs
+}
+
+// Validate valida o token (obrigatório) e a URL da API de licenças (opcional). A URL só é
+// aceita no modo de desenvolvimento: fora dele a licença é sempre validada pelo servidor
+// configurado em license.server_url, e não por um servidor escolhido por quem faz a requisição.
+func (req *SetupLicenseRequest) Validate() []FieldError {
+	var errs []FieldError
+
+	switch {
+	case req.Token == "":
+		errs = append(errs, FieldError{"token", "campo obrigatório"})
+	case len(req.Token) > maxTokenLength:
+		errs = append(errs, FieldError{"token", fmt.Sprintf("máximo de %d caracteres", maxTokenLength)})
+	case strings.IndexFunc(req.Token, unicode.IsSpace) >= 0 || hasControlChars(req.Token):
+		errs = append(errs, FieldError{"token", "não pode conter espaços ou caracteres de controle"})
+	}
+
+	if req.APIUrl != "" {
+		if !license.CurrentPolicy().DevelopmentMode {
+			errs = append(errs, FieldError{"api_url", "permitido apenas no modo de desenvolvimento (use license.server_url no config.json)"})
+		} else if len(req.APIUrl) > maxURLLength {
+			errs = append(errs, FieldError{"api_url", fmt.Sprintf("máximo de %d caracteres", maxURLLength)})
+		} else if u, err := url.Parse(req.APIUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, FieldError{"api_url", "deve ser uma URL http:// ou https:// válida"})
+		}
+	}
+
+	return errs
+}
+
+// Validate valida os campos do arquivo de licença offline: obrigatórios e em base64
+func (req *ImportLicenseRequest) Validate() []FieldError {
+	var errs []FieldError
+
+	for _, field := range []struct{ name, value string }{{"payload", req.Payload}, {"signature", req.Signature}} {
+		switch {
+		case field.value == "":
+			errs = append(errs, FieldError{field.name, "campo obrigatório"})
+		case !isBase64(field.value):
+			errs = append(errs, FieldError{field.name, "deve estar em base64"})
+		}
+	}
+
+	return errs
+}
+
+// isBase64 informa se o valor é base64 padrão (com preenchimento)
+func isBase64(value string) bool {
+	_, err := base64.StdEncoding.DecodeString(value)
+	return err == nil
+}
+
+// Validate valida a URL (obrigatória, http ou https), os tipos de evento e o segredo
+func (req *WebhookRequest) Validate() []FieldError {
+	var errs []FieldError
+
+	switch {
+	case req.URL == "":
+		errs = append(errs, FieldError{"url", "campo obrigatório"})
+	case len(req.URL) > maxURLLength:
+		errs = append(errs, FieldError{"url", fmt.Sprintf("máximo de %d caracteres", maxURLLength)})
+	default:
+		if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, FieldError{"url", "deve ser uma URL http:// ou https:// válida"})
+		}
+	}
+
+	if len(req.Events) == 0 {
+		errs = append(errs, FieldError{"events", "informe ao menos um tipo de evento"})
+	}
+	for _, event := range req.Events {
+		if !isWebhookEvent(event) {
+			errs = append(errs, FieldError{"events", "tipo de evento desconhecido: " + event})
+		}
+	}
+
+	switch {
+	case len(req.Secret) > maxSecretLength:
+		errs = append(errs, FieldError{"secret", fmt.Sprintf("máximo de %d caracteres", maxSecretLength)})
+	case hasControlChars(req.Secret):
+		errs = append(errs, FieldError{"secret", "não pode conter caracteres de controle"})
+	}
+
+	return errs
+}
+
+// hasControlChars verifica se o texto contém caracteres de controle
+func hasControlChars(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-desktop-app/license"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int // 0 quando a requisição é aceita
+		campo  string
+	}{
+		{"válida", `{"nome_arquivo":"relatorio.txt"}`, 0, ""},
+		{"corpo vazio", ``, http.StatusBadRequest, ""},
+		{"JSON malformado", `{"nome_arquivo":`, http.StatusBadRequest, ""},
+		{"sintaxe inválida", `{nome_arquivo: 1}`, http.StatusBadRequest, ""},
+		{"tipo errado", `{"nome_arquivo":123}`, http.StatusBadRequest, "nome_arquivo"},
+		{"campo desconhecido", `{"nome_arquivo":"a.txt","extra":true}`, http.StatusBadRequest, "extra"},
+		{"conteúdo extra", `{"nome_arquivo":"a.txt"}{"nome_arquivo":"b.txt"}`, http.StatusBadRequest, ""},
+		{"falha na validação", `{"nome_arquivo":"../a.txt"}`, http.StatusBadRequest, "nome_arquivo"},
+		{"acima do limite", `{"nome_arquivo":"` + strings.Repeat("a", maxFileRequestBytes) + `"}`, http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/mover_arquivo", strings.NewReader(tt.body))
+			var req FileRequest
+			reqErr := decodeRequest(httptest.NewRecorder(), r, &req, maxFileRequestBytes)
+
+			if tt.status == 0 {
+				if reqErr != nil {
+					t.Fatalf("decodeRequest = %v (%v), esperado sucesso", reqErr, reqErr.detalhes)
+				}
+				return
+			}
+			if reqErr == nil {
+				t.Fatalf("decodeRequest aceitou %q", tt.body)
+			}
+			if reqErr.status != tt.status {
+				t.Errorf("status = %d, esperado %d (%s)", reqErr.status, tt.status, reqErr.message)
+			}
+			if tt.campo != "" && (len(reqErr.detalhes) == 0 || reqErr.detalhes[0].Campo != tt.campo) {
+				t.Errorf("detalhes = %v, esperado o campo %s", reqErr.detalhes, tt.campo)
+			}
+		})
+	}
+}
+
+func TestFileRequestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"nome simples", "relatorio.txt", true},
+		{"nome com espaços e acentos", "relatório final.txt", true},
+		{"vazio", "", false},
+		{"só espaços", "   ", false},
+		{"ponto", ".", false},
+		{"dois pontos", "..", false},
+		{"caminho relativo", "../segredo.txt", false},
+		{"caminho do Windows", `C:\app\a.txt`, false},
+		{"barra", "pasta/a.txt", false},
+		{"caractere reservado", "a?.txt", false},
+		{"caractere de controle", "a\x00.txt", false},
+		{"longo demais", strings.Repeat("a", maxFileNameLength+1), false},
+		{"no limite", strings.Repeat("a", maxFileNameLength), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := FileRequest{NomeArquivo: tt.value}
+			if errs := req.Validate(); (len(errs) == 0) != tt.valid {
+				t.Errorf("Validate(%q) = %v, esperado válido: %v", tt.value, errs, tt.valid)
+			}
+		})
+	}
+}
+
+func TestExecuteRequestValidate(t *testing.T) {
+	absolute, err := filepath.Abs(filepath.Join("bin", "programa.exe"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"caminho absoluto", absolute, true},
+		{"vazio", "", false},
+		{"caminho relativo", filepath.Join("bin", "programa.exe"), false},
+		{"caractere de controle", absolute + "\n", false},
+		{"longo demais", absolute + strings.Repeat("a", maxExecutableLength), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := ExecuteRequest{CaminhoExecutavel: tt.value}
+			if errs := req.Validate(); (len(errs) == 0) != tt.valid {
+				t.Errorf("Validate(%q) = %v, esperado válido: %v", tt.value, errs, tt.valid)
+			}
+		})
+	}
+}
+
+func TestSetupLicenseRequestAPIURL(t *testing.T) {
+	previous := license.CurrentPolicy()
+	t.Cleanup(func() { license.SetPolicy(previous) })
+
+	tests := []struct {
+		name        string
+		development bool
+		apiURL      string
+		valid       bool
+	}{
+		{"sem api_url", false, "", true},
+		{"fora do modo de desenvolvimento", false, "http://localhost:9000", false},
+		{"modo de desenvolvimento", true, "http://localhost:9000", true},
+		{"https", true, "https://licencas.exemplo.com", true},
+		{"esquema inválido", true, "ftp://licencas.exemplo.com", false},
+		{"sem host", true, "http://", false},
+		{"longa demais", true, "http://exemplo.com/" + strings.Repeat("a", maxURLLength), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			license.SetPolicy(license.Policy{DevelopmentMode: tt.development})
+			req := SetupLicenseRequest{Token: "token-teste", APIUrl: tt.apiURL}
+			if errs := req.Validate(); (len(errs) == 0) != tt.valid {
+				t.Errorf("Validate(%q) = %v, esperado válido: %v", tt.apiURL, errs, tt.valid)
+			}
+		})
+	}
+}
@@ -4,82 +4,104 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"go-desktop-app/database"
+	"go-desktop-app/logging"
 )
 
-// LogEntry representa uma entrada de log
+// LogEntry representa uma entrada de log.
+// ID é o número de sequência do buffer em memória, usado como ID dos eventos SSE.
 type LogEntry struct {
+	ID        int64  `json:"id,omitempty"`
 	Timestamp string `json:"timestamp"`
 	Content   string `json:"content"`
 	Type      string `json:"type"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // LogsResponse representa a resposta do endpoint de logs
 type LogsResponse struct {
-	Logs []LogEntry `json:"logs"`
+	Logs   []LogEntry `json:"logs"`
+	Total  int        `json:"total"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
+	Source string     `json:"source"`
 }
 
-// Sistema de logs em memória
+// logTimestampLayout é o formato de data/hora das entradas de log
+const logTimestampLayout = "2006-01-02T15:04:05.000"
+
+// Limites de paginação de /api/logs
+const (
+	defaultLogsLimit = 1000
+	maxLogsLimit     = 5000
+)
+
+// maxLogBuffer é a quantidade de entradas mantidas no buffer em memória
+const maxLogBuffer = 1000
+
+// Sistema de logs em memória.
+// A sequência parte do horário de início em microssegundos para que os IDs
+// continuem crescentes depois de um reinício (Last-Event-ID antigo não casa com IDs novos).
 var (
-	logEntries []LogEntry
-	logsMutex  sync.RWMutex
-	logClients []chan LogEntry
-	clientsMutex sync.RWMutex
+	logEntries       []LogEntry
+	logsMutex        sync.RWMutex
+	logSequenceStart = time.Now().UnixMicro()
+	logSequence      = logSequenceStart
 )
 
-// AddLogEntry adiciona uma nova entrada de log
-func AddLogEntry(content string) {
+// LogSink retorna o sink do logger central que alimenta o buffer da interface web,
+// o banco de dados e os clientes SSE. O tipo da entrada é o nível real do log.
+func LogSink() logging.Sink {
+	return logging.SinkFunc(func(rec logging.Record) {
+		content := rec.Text()
+		if rec.Component != "" {
+			content = "[" + rec.Component + "] " + content
+		}
+		addLogEntry(rec.Time, content, logging.LevelName(rec.Level), rec.RequestID)
+	})
+}
+
+// addLogEntry adiciona uma nova entrada de log.
+// Não deve registrar logs: é chamada de dentro do logger central.
+func addLogEntry(timestamp time.Time, content, logType, requestID string) {
 	logsMutex.Lock()
 	defer logsMutex.Unlock()
 
+	logSequence++
 	entry := LogEntry{
-		Timestamp: time.Now().Format("2006-01-02T15:04:05.000"),
+		ID:        logSequence,
+		Timestamp: timestamp.Format(logTimestampLayout),
 		Content:   content,
-		Type:      determineLogType(content),
+		Type:      logType,
+		RequestID: requestID,
 	}
 
+	// Persiste no banco de forma assíncrona (não bloqueia a requisição)
+	database.EnqueueLog(database.LogRecord{
+		Timestamp: timestamp,
+		Type:      logType,
+		Content:   content,
+		RequestID: requestID,
+	})
+
 	logEntries = append(logEntries, entry)
 
-	// Manter apenas os últimos 1000 logs
-	if len(logEntries) > 1000 {
-		logEntries = logEntries[len(logEntries)-1000:]
+	// Manter apenas os últimos logs
+	if len(logEntries) > maxLogBuffer {
+		logEntries = logEntries[len(logEntries)-maxLogBuffer:]
 	}
 
 	// Notifica todos os clientes conectados
 	notifyClients(entry)
 }
 
-// notifyClients envia o novo log para todos os clientes SSE conectados
-func notifyClients(entry LogEntry) {
-	clientsMutex.RLock()
-	defer clientsMutex.RUnlock()
-
-	fmt.Printf("DEBUG: Notificando %d clientes SSE sobre novo log\n", len(logClients))
-
-	for i, client := range logClients {
-		select {
-		case client <- entry:
-			fmt.Printf("Log enviado para cliente %d\n", i)
-		default:
-			fmt.Printf("Cliente %d não está respondendo, ignorando\n", i)
-		}
-	}
-}
-
-// determineLogType determina o tipo do log baseado no conteúdo
-func determineLogType(content string) string {
-	contentLower := strings.ToLower(content)
-	if strings.Contains(contentLower, "erro") || strings.Contains(contentLower, "error") || strings.Contains(contentLower, "failed") {
-		return "error"
-	} else if strings.Contains(contentLower, "sucesso") || strings.Contains(contentLower, "success") || strings.Contains(contentLower, "200") {
-		return "success"
-	}
-	return "info"
-}
-
 // WebHandler serve a interface web principal usando arquivos embarcados
 func WebHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -130,123 +152,192 @@ func WebHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-// LogsAPIHandler retorna os logs em formato JSON
+// LogsAPIHandler retorna os logs em formato JSON.
+// Filtros (query string): from, to, level (lista separada por vírgulas), q,
+// request_id, limit e offset. Sem offset são retornadas as entradas mais recentes.
 func LogsAPIHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
-	logsMutex.RLock()
-	defer logsMutex.RUnlock()
-
-	// Cria uma cópia dos logs para evitar problemas de concorrência
-	logsCopy := make([]LogEntry, len(logEntries))
-	copy(logsCopy, logEntries)
+	filter, err := parseLogFilter(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Erro: err.Error()})
+		return
+	}
 
-	response := LogsResponse{
-		Logs: logsCopy,
+	response := LogsResponse{Limit: filter.Limit, Offset: filter.Offset, Source: "database"}
+
+	records, total, err := database.QueryLogs(filter)
+	if err == nil {
+		response.Total = total
+		response.Logs = make([]LogEntry, len(records))
+		for i, record := range records {
+			response.Logs[i] = LogEntry{
+				Timestamp: record.Timestamp.Format(logTimestampLayout),
+				Content:   record.Content,
+				Type:      record.Type,
+				RequestID: record.RequestID,
+			}
+		}
+	} else {
+		// Sem banco disponível, filtra o buffer em memória
+		response.Source = "memory"
+		response.Logs, response.Total = filterMemoryLogs(filter)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// ClearLogsHandler limpa todos os logs
-func ClearLogsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
-	logsMutex.Lock()
-	defer logsMutex.Unlock()
+// parseLogFilter interpreta os filtros da query string
+func parseLogFilter(r *http.Request) (database.LogFilter, error) {
+	return parseLogFilterValues(r.URL.Query())
+}
 
-	logEntries = []LogEntry{}
+// parseLogFilterValues interpreta os filtros a partir dos parâmetros informados
+func parseLogFilterValues(query url.Values) (database.LogFilter, error) {
+	filter := database.LogFilter{
+		Search:    query.Get("q"),
+		RequestID: query.Get("request_id"),
+		Limit:     defaultLogsLimit,
+	}
 
-	response := MessageResponse{
-		Mensagem: "Logs limpos com sucesso",
+	var err error
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = parseLogTime(value); err != nil {
+			return filter, fmt.Errorf("parâmetro from inválido: %v", err)
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = parseLogTime(value); err != nil {
+			return filter, fmt.Errorf("parâmetro to inválido: %v", err)
+		}
+	}
+	if value := query.Get("level"); value != "" {
+		for _, level := range strings.Split(value, ",") {
+			if level = strings.TrimSpace(strings.ToLower(level)); level != "" {
+				filter.Types = append(filter.Types, level)
+			}
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 || filter.Limit > maxLogsLimit {
+			return filter, fmt.Errorf("parâmetro limit deve estar entre 1 e %d", maxLogsLimit)
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if filter.Offset, err = strconv.Atoi(value); err != nil || filter.Offset < 0 {
+			return filter, fmt.Errorf("parâmetro offset inválido")
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return filter, nil
 }
 
-// LogsStreamHandler implementa Server-Sent Events para logs em tempo real
-func LogsStreamHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Printf("DEBUG: Nova conexão SSE de %s\n", r.RemoteAddr)
-
-	if r.Method != http.MethodGet {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
+// parseLogTime aceita RFC 3339, data/hora local (2006-01-02T15:04:05) ou apenas a data
+func parseLogTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
 	}
+	for _, layout := range []string{logTimestampLayout, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("formato de data não reconhecido: %s", value)
+}
 
-	// Configura headers para SSE
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
-
-	fmt.Printf("DEBUG: Headers SSE configurados\n")
+// filterMemoryLogs aplica os filtros ao buffer em memória
+func filterMemoryLogs(filter database.LogFilter) ([]LogEntry, int) {
+	logsMutex.RLock()
+	defer logsMutex.RUnlock()
 
-	// Cria um canal para este cliente
-	clientChan := make(chan LogEntry, 100)
+	var matched []LogEntry
+	for _, entry := range logEntries {
+		if logEntryMatches(entry, filter) {
+			matched = append(matched, entry)
+		}
+	}
 
-	// Adiciona o cliente à lista
-	clientsMutex.Lock()
-	logClients = append(logClients, clientChan)
-	clientCount := len(logClients)
-	clientsMutex.Unlock()
+	// Página contada a partir das entradas mais recentes, em ordem cronológica
+	total := len(matched)
+	end := total - filter.Offset
+	if end <= 0 {
+		return []LogEntry{}, total
+	}
+	start := end - filter.Limit
+	if start < 0 {
+		start = 0
+	}
 
-	fmt.Printf("Novo cliente SSE conectado. Total de clientes: %d\n", clientCount)
+	page := make([]LogEntry, end-start)
+	copy(page, matched[start:end])
+	return page, total
+}
 
-	// Remove o cliente quando a conexão fechar
-	defer func() {
-		clientsMutex.Lock()
-		for i, client := range logClients {
-			if client == clientChan {
-				logClients = append(logClients[:i], logClients[i+1:]...)
+// logEntryMatches verifica se uma entrada em memória atende aos filtros
+func logEntryMatches(entry LogEntry, filter database.LogFilter) bool {
+	if filter.RequestID != "" && entry.RequestID != filter.RequestID {
+		return false
+	}
+	// Sem diferenciar maiúsculas, como o LIKE da consulta no SQLite
+	if filter.Search != "" && !strings.Contains(strings.ToLower(entry.Content), strings.ToLower(filter.Search)) {
+		return false
+	}
+	if len(filter.Types) > 0 {
+		found := false
+		for _, t := range filter.Types {
+			if entry.Type == t {
+				found = true
 				break
 			}
 		}
-		clientsMutex.Unlock()
-		close(clientChan)
-	}()
-
-	// Envia logs existentes primeiro
-	logsMutex.RLock()
-	existingCount := len(logEntries)
-	for _, entry := range logEntries {
-		data, _ := json.Marshal(entry)
-		fmt.Fprintf(w, "data: %s\n\n", data)
-	}
-	logsMutex.RUnlock()
-
-	fmt.Printf("DEBUG: Enviados %d logs existentes para novo cliente SSE\n", existingCount)
-
-	// Flush para enviar os dados imediatamente
-	flusher, ok := w.(http.Flusher)
-	if ok {
-		flusher.Flush()
-	}
-
-	// Escuta por novos logs ou desconexão
-	for {
-		select {
-		case entry := <-clientChan:
-			// Novo log recebido, envia para o cliente
-			data, _ := json.Marshal(entry)
-			fmt.Printf("DEBUG: Enviando log via SSE: %s\n", string(data))
-			fmt.Fprintf(w, "data: %s\n\n", data)
-			if ok {
-				flusher.Flush()
-				fmt.Printf("DEBUG: Flush realizado\n")
-			}
-		case <-r.Context().Done():
-			// Cliente desconectou
-			fmt.Printf("DEBUG: Cliente SSE desconectou\n")
-			return
+		if !found {
+			return false
 		}
 	}
+	if !filter.From.IsZero() || !filter.To.IsZero() {
+		timestamp, err := time.ParseInLocation(logTimestampLayout, entry.Timestamp, time.Local)
+		if err != nil {
+			return false
+		}
+		if !filter.From.IsZero() && timestamp.Before(filter.From) {
+			return false
+		}
+		if !filter.To.IsZero() && timestamp.After(filter.To) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClearLogsHandler limpa todos os logs
+func ClearLogsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	logsMutex.Lock()
+	logEntries = []LogEntry{}
+	logsMutex.Unlock()
+
+	// Remove também os logs persistidos
+	if err := database.ClearLogs(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Erro: err.Error()})
+		return
+	}
+
+	response := MessageResponse{
+		Mensagem: "Logs limpos com sucesso",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-desktop-app/database"
+	"go-desktop-app/events"
+	"go-desktop-app/logging"
+	"go-desktop-app/webhooks"
+)
+
+// Limites de paginação das entregas de webhook
+const (
+	defaultDeliveriesLimit = 100
+	maxDeliveriesLimit     = 1000
+)
+
+// WebhookRequest representa a requisição para criar um webhook.
+// events aceita os tipos do barramento (ex.: file.moved) ou "*" para todos.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// WebhookResponse representa um webhook sem o segredo
+type WebhookResponse struct {
+	database.Webhook
+	HasSecret bool `json:"has_secret"`
+}
+
+// WebhooksResponse representa a resposta de GET /api/webhooks
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveriesResponse representa a resposta de /api/webhooks/deliveries
+type WebhookDeliveriesResponse struct {
+	Deliveries []database.WebhookDelivery `json:"deliveries"`
+	Total      int                        `json:"total"`
+	Limit      int                        `json:"limit"`
+	Offset     int                        `json:"offset"`
+}
+
+// WebhookDeadLettersResponse representa a resposta de /api/webhooks/dead-letters
+type WebhookDeadLettersResponse struct {
+	DeadLetters []database.WebhookDeadLetter `json:"dead_letters"`
+	Limit       int                          `json:"limit"`
+	Offset      int                          `json:"offset"`
+}
+
+// WebhooksHandler lista (GET) ou cria (POST) webhooks
+func WebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		hooks, err := database.ListWebhooks()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		response := WebhooksResponse{Webhooks: make([]WebhookResponse, len(hooks))}
+		for i, hook := range hooks {
+			response.Webhooks[i] = newWebhookResponse(hook)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	case http.MethodPost:
+		var req WebhookRequest
+		if reqErr := decodeRequest(w, r, &req, maxWebhookRequestBytes); reqErr != nil {
+			writeRequestError(w, reqErr)
+			return
+		}
+
+		hook, err := database.CreateWebhook(req.URL, req.Events, req.Secret)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		logger.Info("Webhook criado", "webhook_id", hook.ID, "url", hook.URL, "events", req.Events,
+			logging.RequestIDKey, RequestIDFromContext(r.Context()))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(newWebhookResponse(*hook))
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// WebhookHandler consulta (GET) ou remove (DELETE) um webhook
+func WebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, "ID de webhook inválido")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		hook, err := database.GetWebhook(id)
+		if err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newWebhookResponse(*hook))
+	case http.MethodDelete:
+		if err := database.DeleteWebhook(id); err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		logger.Info("Webhook removido", "webhook_id", id, logging.RequestIDKey, RequestIDFromContext(r.Context()))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MessageResponse{Mensagem: "Webhook removido com sucesso"})
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// WebhookDeliveriesHandler lista as entregas, das mais recentes para as mais antigas.
+// Filtros: webhook_id, status (pending, retrying, delivered, failed), limit e offset.
+func WebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, err := parsePage(query.Get("limit"), query.Get("offset"))
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	filter := database.DeliveryFilter{Status: query.Get("status"), Limit: limit, Offset: offset}
+	switch filter.Status {
+	case "", database.DeliveryPending, database.DeliveryRetrying, database.DeliveryDelivered, database.DeliveryFailed:
+	default:
+		writeBadRequest(w, "parâmetro status deve ser pending, retrying, delivered ou failed")
+		return
+	}
+	if value := query.Get("webhook_id"); value != "" {
+		if filter.WebhookID, err = strconv.ParseInt(value, 10, 64); err != nil || filter.WebhookID < 1 {
+			writeBadRequest(w, "parâmetro webhook_id inválido")
+			return
+		}
+	}
+
+	deliveries, total, err := database.ListWebhookDeliveries(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WebhookDeliveriesResponse{Deliveries: deliveries, Total: total, Limit: limit, Offset: offset})
+}
+
+// WebhookDeadLettersHandler lista as entregas que esgotaram as tentativas
+func WebhookDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, err := parsePage(query.Get("limit"), query.Get("offset"))
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	letters, err := database.ListWebhookDeadLetters(limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WebhookDeadLettersResponse{DeadLetters: letters, Limit: limit, Offset: offset})
+}
+
+// ReplayWebhookDeliveryHandler reenvia uma entrega (inclusive da dead letter) com as tentativas zeradas
+func ReplayWebhookDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, "ID de entrega inválido")
+		return
+	}
+
+	if err := database.ReplayWebhookDelivery(id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	webhooks.Wake()
+	logger.Info("Entrega de webhook reagendada", "delivery_id", id, logging.RequestIDKey, RequestIDFromContext(r.Context()))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(MessageResponse{Mensagem: "Entrega reagendada"})
+}
+
+// newWebhookResponse monta a resposta de um webhook sem expor o segredo
+func newWebhookResponse(hook database.Webhook) WebhookResponse {
+	return WebhookResponse{Webhook: hook, HasSecret: hook.Secret != ""}
+}
+
+// isWebhookEvent verifica se o tipo pode ser assinado por um webhook
+func isWebhookEvent(name string) bool {
+	if name == "*" {
+		return true
+	}
+	for _, t := range events.Types {
+		if string(t) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePage interpreta limit e offset das listagens de webhooks e da auditoria
+func parsePage(limitValue, offsetValue string) (int, int, error) {
+	limit, offset := defaultDeliveriesLimit, 0
+	var err error
+	if limitValue != "" {
+		if limit, err = strconv.Atoi(limitValue); err != nil || limit < 1 || limit > maxDeliveriesLimit {
+			return 0, 0, errors.New("parâmetro limit deve estar entre 1 e " + strconv.Itoa(maxDeliveriesLimit))
+		}
+	}
+	if offsetValue != "" {
+		if offset, err = strconv.Atoi(offsetValue); err != nil || offset < 0 {
+			return 0, 0, errors.New("parâmetro offset inválido")
+		}
+	}
+	return limit, offset, nil
+}
+
+// writeWebhookError responde 404 para webhook ou entrega inexistente e 500 para os demais erros
+func writeWebhookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, database.ErrWebhookNotFound) || errors.Is(err, database.ErrDeliveryNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-desktop-app/config"
+	"go-desktop-app/database"
+	"go-desktop-app/events"
+	"go-desktop-app/metrics"
+
+	"golang.org/x/net/websocket"
+)
+
+// Tópicos disponíveis no WebSocket /api/ws
+const (
+	TopicLogs    = "logs"
+	TopicJobs    = "jobs"
+	TopicFiles   = "files"
+	TopicLicense = "license"
+	TopicConfig  = "config"
+)
+
+// Parâmetros das conexões WebSocket
+const (
+	wsClientBuffer      = 256
+	wsHeartbeatInterval = 30 * time.Second
+	wsMaxMessageBytes   = 4 << 10
+)
+
+// wsTopics lista os tópicos aceitos nas mensagens de inscrição
+var wsTopics = []string{TopicLogs, TopicJobs, TopicFiles, TopicLicense, TopicConfig}
+
+// wsEventTopics associa os eventos do barramento aos tópicos do WebSocket
+var wsEventTopics = map[events.Type]string{
+	events.FileRead:       TopicFiles,
+	events.FileMoved:      TopicFiles,
+	events.ProcessStarted: TopicJobs,
+	events.ProcessExited:  TopicJobs,
+	events.LicenseChanged: TopicLicense,
+	events.ConfigReloaded: TopicConfig,
+}
+
+// WSClientMessage é a mensagem enviada pelo cliente: subscribe, unsubscribe ou ping.
+// Em subscribe, topics vazio inscreve em todos os tópicos e filters substitui os filtros atuais.
+type WSClientMessage struct {
+	Action  string    `json:"action"`
+	Topics  []string  `json:"topics"`
+	Filters WSFilters `json:"filters"`
+}
+
+// WSFilters são os filtros da inscrição. level e q valem para o tópico logs;
+// request_id vale para todos os tópicos.
+type WSFilters struct {
+	Level     string `json:"level"`
+	Q         string `json:"q"`
+	RequestID string `json:"request_id"`
+}
+
+// WSServerMessage é a mensagem enviada pelo servidor.
+// Em eventos do barramento, event traz o tipo (ex.: file.moved).
+type WSServerMessage struct {
+	Type      string      `json:"type"`
+	Topic     string      `json:"topic,omitempty"`
+	Event     events.Type `json:"event,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Topics    []string    `json:"topics,omitempty"`
+	Dropped   int64       `json:"dropped,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	Timestamp string      `json:"timestamp"`
+}
+
+// wsClient é uma conexão WebSocket com seus tópicos e filtros
+type wsClient struct {
+	send    chan WSServerMessage
+	done    chan struct{}
+	mutex   sync.RWMutex
+	topics  map[string]bool
+	filter  database.LogFilter
+	dropped atomic.Int64
+}
+
+var (
+	wsClients      []*wsClient
+	wsClientsMutex sync.RWMutex
+)
+
+// WebSocketHandler multiplexa logs, processos, operações de arquivo e licença em uma conexão.
+// A origem é validada com a mesma lista de origens do CORS.
+func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	server := websocket.Server{Handshake: checkWebSocketOrigin, Handler: serveWebSocket}
+	server.ServeHTTP(w, r)
+}
+
+// checkWebSocketOrigin aceita clientes sem Origin (não navegadores) e origens permitidas no CORS
+func checkWebSocketOrigin(cfg *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if !isOriginAllowed(config.GetSettings().CORS.AllowedOrigins, origin) {
+		return fmt.Errorf("origem não permitida: %s", origin)
+	}
+	return nil
+}
+
+// serveWebSocket lê as mensagens do cliente enquanto uma goroutine envia os eventos
+func serveWebSocket(ws *websocket.Conn) {
+	ws.MaxPayloadBytes = wsMaxMessageBytes
+	client := &wsClient{
+		send:   make(chan WSServerMessage, wsClientBuffer),
+		done:   make(chan struct{}),
+		topics: make(map[string]bool),
+	}
+
+	wsClientsMutex.Lock()
+	wsClients = append(wsClients, client)
+	clientCount := len(wsClients)
+	wsClientsMutex.Unlock()
+
+	r := ws.Request()
+	logger.Debug("Novo cliente WebSocket conectado", "client", clientAddress(r), "clients", clientCount)
+
+	defer func() {
+		wsClientsMutex.Lock()
+		for i, c := range wsClients {
+			if c == client {
+				wsClients = append(wsClients[:i], wsClients[i+1:]...)
+				break
+			}
+		}
+		wsClientsMutex.Unlock()
+		close(client.done)
+		ws.Close()
+		logger.Debug("Cliente WebSocket desconectou", "client", clientAddress(r))
+	}()
+
+	go client.writeLoop(ws)
+
+	client.reply(WSServerMessage{Type: "hello", Topics: wsTopics})
+
+	for {
+		var msg WSClientMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+		client.handle(msg)
+	}
+}
+
+// writeLoop envia os eventos, os avisos de descarte e o heartbeat
+func (c *wsClient) writeLoop(ws *websocket.Conn) {
+	heartbeat := time.NewTicker(wsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var msg WSServerMessage
+		select {
+		case msg = <-c.send:
+		case <-heartbeat.C:
+			msg = WSServerMessage{Type: "heartbeat"}
+		case <-c.done:
+			return
+		}
+
+		if dropped := c.dropped.Swap(0); dropped > 0 {
+			notice := WSServerMessage{Type: "dropped", Dropped: dropped, Timestamp: time.Now().Format(time.RFC3339Nano)}
+			if websocket.JSON.Send(ws, notice) != nil {
+				ws.Close()
+				return
+			}
+		}
+		if msg.Timestamp == "" {
+			msg.Timestamp = time.Now().Format(time.RFC3339Nano)
+		}
+		if websocket.JSON.Send(ws, msg) != nil {
+			// Fecha a conexão para encerrar também o loop de leitura
+			ws.Close()
+			return
+		}
+	}
+}
+
+// handle processa uma mensagem do cliente
+func (c *wsClient) handle(msg WSClientMessage) {
+	switch msg.Action {
+	case "subscribe":
+		topics := msg.Topics
+		if len(topics) == 0 {
+			topics = wsTopics
+		}
+		for _, topic := range topics {
+			if !isWSTopic(topic) {
+				c.reply(WSServerMessage{Type: "error", Message: "tópico desconhecido: " + topic})
+				return
+			}
+		}
+
+		filter, err := wsLogFilter(msg.Filters)
+		if err != nil {
+			c.reply(WSServerMessage{Type: "error", Message: err.Error()})
+			return
+		}
+
+		c.mutex.Lock()
+		for _, topic := range topics {
+			c.topics[topic] = true
+		}
+		c.filter = filter
+		c.mutex.Unlock()
+		c.reply(WSServerMessage{Type: "subscribed", Topics: c.subscribedTopics()})
+	case "unsubscribe":
+		c.mutex.Lock()
+		if len(msg.Topics) == 0 {
+			c.topics = make(map[string]bool)
+		}
+		for _, topic := range msg.Topics {
+			delete(c.topics, topic)
+		}
+		c.mutex.Unlock()
+		c.reply(WSServerMessage{Type: "subscribed", Topics: c.subscribedTopics()})
+	case "ping":
+		c.reply(WSServerMessage{Type: "pong"})
+	default:
+		c.reply(WSServerMessage{Type: "error", Message: "ação desconhecida: " + msg.Action})
+	}
+}
+
+// reply enfileira uma resposta ao cliente, aguardando espaço na fila
+func (c *wsClient) reply(msg WSServerMessage) {
+	select {
+	case c.send <- msg:
+	case <-c.done:
+	}
+}
+
+// subscribedTopics retorna os tópicos inscritos na ordem de wsTopics
+func (c *wsClient) subscribedTopics() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	topics := []string{}
+	for _, topic := range wsTopics {
+		if c.topics[topic] {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// accepts verifica se o cliente está inscrito no tópico e se os filtros aceitam o evento
+func (c *wsClient) accepts(topic, requestID string, entry *LogEntry) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if !c.topics[topic] {
+		return false
+	}
+	if entry != nil {
+		return logEntryMatches(*entry, c.filter)
+	}
+	return c.filter.RequestID == "" || c.filter.RequestID == requestID
+}
+
+// wsLogFilter converte os filtros da inscrição no filtro de logs
+func wsLogFilter(filters WSFilters) (database.LogFilter, error) {
+	query := url.Values{}
+	query.Set("level", filters.Level)
+	query.Set("q", filters.Q)
+	query.Set("request_id", filters.RequestID)
+	return parseLogFilterValues(query)
+}
+
+// isWSTopic verifica se o tópico existe
+func isWSTopic(topic string) bool {
+	for _, t := range wsTopics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastWS envia a mensagem aos clientes inscritos no tópico sem bloquear quem publicou.
+// Não deve registrar logs: é chamada de dentro do logger central para o tópico logs.
+func broadcastWS(topic, requestID string, msg WSServerMessage, entry *LogEntry) {
+	msg.Type = "event"
+	msg.Topic = topic
+
+	wsClientsMutex.RLock()
+	defer wsClientsMutex.RUnlock()
+
+	for _, client := range wsClients {
+		if !client.accepts(topic, requestID, entry) {
+			continue
+		}
+		select {
+		case client.send <- msg:
+		default:
+			client.dropped.Add(1)
+			metrics.WebSocketDroppedEvents.Inc()
+		}
+	}
+}
+
+// publishLogEvent publica uma entrada de log no tópico logs
+func publishLogEvent(entry LogEntry) {
+	broadcastWS(TopicLogs, entry.RequestID, WSServerMessage{Data: entry}, &entry)
+}
+
+// forwardBusEvent repassa um evento do barramento ao tópico correspondente
+func forwardBusEvent(event events.Event) {
+	topic, ok := wsEventTopics[event.Type]
+	if !ok {
+		return
+	}
+	broadcastWS(topic, event.RequestID, WSServerMessage{
+		Event:     event.Type,
+		RequestID: event.RequestID,
+		Data:      event.Data,
+		Timestamp: event.Time.Format(time.RFC3339Nano),
+	}, nil)
+}
@@ -0,0 +1,149 @@
+// Package bootstrap reúne a inicialização comum ao modo interativo (main) e ao serviço do
+// Windows: configurações, arquivo de log, banco de dados e tarefas em segundo plano.
+package bootstrap
+
+import (
+	"time"
+
+	"go-desktop-app/config"
+	"go-desktop-app/database"
+	"go-desktop-app/events"
+	"go-desktop-app/license"
+	"go-desktop-app/logging"
+	"go-desktop-app/secrets"
+	"go-desktop-app/webhooks"
+)
+
+// logger registra as etapas da inicialização
+var logger = logging.Component("bootstrap")
+
+// LoadSettings carrega o config.json, aplica o nível de log e a política da licença e os
+// reaplica sempre que o arquivo é recarregado. Sem o arquivo, ficam os padrões.
+func LoadSettings() {
+	if err := config.LoadSettings(config.SettingsPath()); err != nil {
+		logger.Warn("Erro ao carregar configurações, usando padrões", "error", err)
+	}
+	ApplyLogLevel()
+	ApplyLicensePolicy()
+
+	// Reaplica o nível de log e a política da licença quando o config.json é recarregado
+	events.Subscribe("log-level", func(events.Event) { ApplyLogLevel() }, events.ConfigReloaded)
+	events.Subscribe("license-policy", func(events.Event) { ApplyLicensePolicy() }, events.ConfigReloaded)
+}
+
+// ApplyLogLevel aplica o nível de log configurado em config.json
+func ApplyLogLevel() {
+	level, err := logging.ParseLevel(config.GetSettings().Logs.Level)
+	if err != nil {
+		logger.Warn("Nível de log inválido em config.json, usando info", "error", err)
+	}
+	logging.SetLevel(level)
+}
+
+// ApplyLicensePolicy aplica a seção license do config.json à verificação da licença
+func ApplyLicensePolicy() {
+	settings := config.GetSettings().License
+	if settings.DevelopmentMode {
+		logger.Warn("Modo de desenvolvimento da licença ativo: falhas de comunicação com o servidor de licenças geram respostas simuladas")
+	}
+	license.SetPolicy(license.Policy{
+		ServerURL:       settings.ServerURL,
+		DevelopmentMode: settings.DevelopmentMode,
+		OfflineGrace:    time.Duration(settings.OfflineGraceHours) * time.Hour,
+	})
+	license.SetVerifierSchedule(license.VerifierSchedule{
+		Interval:       time.Duration(settings.CheckIntervalHours) * time.Hour,
+		InitialBackoff: time.Duration(settings.InitialBackoffSeconds) * time.Second,
+		MaxBackoff:     time.Duration(settings.MaxBackoffSeconds) * time.Second,
+	})
+}
+
+// EnableLogFile ativa o arquivo de log com rotação configurado em config.json.
+// Retorna false se o arquivo está desativado ou não pôde ser aberto.
+func EnableLogFile() bool {
+	fileSettings := config.GetSettings().Logs.File
+	if !fileSettings.Enabled {
+		return false
+	}
+
+	file, err := logging.EnableFileSink(logging.FileOptions{
+		Directory:  config.LogDirectory(),
+		Name:       "go-desktop-app.log",
+		MaxSizeMB:  fileSettings.MaxSizeMB,
+		MaxAgeDays: fileSettings.MaxAgeDays,
+		MaxBackups: fileSettings.MaxBackups,
+		Compress:   fileSettings.Compress,
+	})
+	if err != nil {
+		logger.Error("Erro ao abrir arquivo de log", "error", err)
+		return false
+	}
+	logger.Info("Gravando logs em arquivo", "directory", file.Directory())
+	return true
+}
+
+// DatabaseOptions monta as opções do banco de dados a partir da seção database do config.json.
+// Com o caminho padrão, prepara antes o diretório de dados (no Windows, com o ACL que permite
+// ao serviço e ao modo interativo gravar nos mesmos arquivos).
+func DatabaseOptions() database.Options {
+	settings := config.GetSettings().Database
+	if settings.Path == "" {
+		if err := config.PrepareDataDirectory(); err != nil {
+			logger.Warn("Erro ao preparar diretório de dados", "error", err)
+		}
+	}
+	options := database.Options{
+		Path:            config.DatabasePath(),
+		JournalMode:     settings.JournalMode,
+		BusyTimeout:     time.Duration(settings.BusyTimeoutMs) * time.Millisecond,
+		ForeignKeys:     settings.ForeignKeys,
+		MaxOpenConns:    settings.MaxOpenConns,
+		MaxIdleConns:    settings.MaxIdleConns,
+		ConnMaxLifetime: time.Duration(settings.ConnMaxLifetimeMinutes) * time.Minute,
+		IntegrityCheck:  settings.IntegrityCheck,
+		BackupDirectory: config.BackupDirectory(),
+		AutoRestore:     settings.Backup.AutoRestore,
+		Secrets:         secrets.NewMachineStore(config.SecretKeyPath()),
+	}
+	// Sem caminho configurado, instalações antigas guardavam o banco em data/license.db
+	if settings.Path == "" {
+		options.LegacyPaths = config.LegacyDatabasePaths()
+	}
+	return options
+}
+
+// StartDatabase abre o banco de dados e inicia as tarefas que dependem dele: gravação dos
+// logs, entrega dos webhooks, backups periódicos e verificação periódica da licença.
+// Em caso de erro a aplicação continua sem o banco (as operações de licença retornam erro).
+func StartDatabase() {
+	if err := database.InitDatabase(DatabaseOptions()); err != nil {
+		logger.Error("Erro ao inicializar banco de dados", "error", err)
+		return
+	}
+
+	// Persiste os logs da interface web em lote
+	retention := time.Duration(config.GetSettings().Logs.RetentionDays) * 24 * time.Hour
+	database.StartLogWriter(retention)
+
+	// Entrega os eventos aos webhooks cadastrados
+	webhooks.Start()
+
+	// Backups periódicos do banco de dados
+	startBackups()
+
+	// Verificação periódica da licença
+	license.StartVerifier(database.Licenses())
+}
+
+// startBackups inicia os backups periódicos configurados em database.backup
+func startBackups() {
+	settings := config.GetSettings().Database.Backup
+	if !settings.Enabled {
+		return
+	}
+	database.StartBackupScheduler(database.BackupSchedule{
+		Directory: config.BackupDirectory(),
+		Interval:  time.Duration(settings.IntervalHours) * time.Hour,
+		Keep:      settings.Keep,
+	})
+}
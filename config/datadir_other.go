@@ -0,0 +1,18 @@
+//go:build !windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// PrepareDataDirectory cria o diretório de dados. Fora do Windows o diretório é do usuário
+// e só ele tem acesso.
+func PrepareDataDirectory() error {
+	dir := DataDirectory()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("erro ao criar diretório de dados %s: %v", dir, err)
+	}
+	return nil
+}
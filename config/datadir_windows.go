@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// dataDirectorySDDL é o ACL do diretório de dados: controle total para o SYSTEM (serviço) e
+// os administradores e modificação para os usuários autenticados (modo interativo), herdado
+// pelos arquivos (license.db, -wal/-shm, license.key e backups). Com o ACL padrão do
+// %ProgramData% o criador é o único com escrita, e a outra conta abriria o banco somente leitura.
+const dataDirectorySDDL = "D:PAI(A;OICI;FA;;;SY)(A;OICI;FA;;;BA)(A;OICI;0x1301bf;;;AU)"
+
+// PrepareDataDirectory cria o diretório de dados e aplica o ACL compartilhado pelo serviço e
+// pelo modo interativo. O ACL também é propagado aos arquivos já existentes.
+func PrepareDataDirectory() error {
+	dir := DataDirectory()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de dados %s: %v", dir, err)
+	}
+
+	desired, err := windows.SecurityDescriptorFromString(dataDirectorySDDL)
+	if err != nil {
+		return err
+	}
+	current, err := windows.GetNamedSecurityInfo(dir, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err == nil && current.String() == desired.String() {
+		return nil
+	}
+
+	dacl, _, err := desired.DACL()
+	if err != nil {
+		return err
+	}
+	err = windows.SetNamedSecurityInfo(dir, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, dacl, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao definir permissões do diretório de dados %s: %v", dir, err)
+	}
+	return nil
+}
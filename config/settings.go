@@ -0,0 +1,388 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"go-desktop-app/events"
+)
+
+// SETTINGS_ENV é a variável de ambiente que permite apontar para outro arquivo de configuração
+const SETTINGS_ENV = "GO_DESKTOP_APP_CONFIG"
+
+// Settings reúne as configurações ajustáveis da aplicação (config.json)
+type Settings struct {
+	AccessLog AccessLogSettings `json:"access_log"`
+	Health    HealthSettings    `json:"health"`
+	Executor  ExecutorSettings  `json:"executor"`
+	RateLimit RateLimitSettings `json:"rate_limit"`
+	CORS      CORSSettings      `json:"cors"`
+	Logs      LogSettings       `json:"logs"`
+	Webhooks  WebhookSettings   `json:"webhooks"`
+	Database  DatabaseSettings  `json:"database"`
+	License   LicenseSettings   `json:"license"`
+	// APIKeys lista o SHA-256 (hex) das API keys reconhecidas no header X-API-Key.
+	// Só uma key da lista identifica o cliente no rate limit e na auditoria.
+	APIKeys []string `json:"api_keys"`
+}
+
+// AccessLogSettings controla quais rotas geram log de acesso.
+// Padrões terminados em "*" casam por prefixo; os demais casam exatamente.
+// Uma lista de inclusão vazia significa "todas as rotas".
+type AccessLogSettings struct {
+	IncludeRoutes []string `json:"include_routes"`
+	ExcludeRoutes []string `json:"exclude_routes"`
+}
+
+// HealthSettings define os limites usados por /healthz e /readyz
+type HealthSettings struct {
+	MinFreeDiskMB uint64 `json:"min_free_disk_mb"`
+}
+
+// ExecutorSettings limita a execução de processos externos
+type ExecutorSettings struct {
+	MaxConcurrentProcesses int `json:"max_concurrent_processes"`
+}
+
+// RateLimitSettings configura o limite de requisições por cliente
+type RateLimitSettings struct {
+	Enabled bool             `json:"enabled"`
+	Groups  []RateLimitGroup `json:"groups"`
+}
+
+// RateLimitGroup aplica um token bucket por cliente às rotas do grupo.
+// As rotas usam a mesma sintaxe de padrões do log de acesso.
+type RateLimitGroup struct {
+	Name              string   `json:"name"`
+	Routes            []string `json:"routes"`
+	RequestsPerSecond float64  `json:"requests_per_second"`
+	Burst             int      `json:"burst"`
+}
+
+// CORSSettings define a política de CORS aplicada a todas as rotas.
+// Cada origem permitida é "esquema://host[:porta]"; a porta "*" aceita qualquer
+// porta (inclusive a padrão), o host "*.dominio" aceita subdomínios e "*" sozinho
+// aceita qualquer origem.
+type CORSSettings struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	MaxAgeSeconds    int      `json:"max_age_seconds"`
+}
+
+// LogSettings controla o nível do logger central e a persistência dos logs no banco de dados
+type LogSettings struct {
+	Level         string          `json:"level"`
+	RetentionDays int             `json:"retention_days"`
+	File          LogFileSettings `json:"file"`
+}
+
+// LogFileSettings configura o arquivo de log com rotação.
+// Directory vazio usa a pasta "logs" ao lado do executável.
+type LogFileSettings struct {
+	Enabled    bool   `json:"enabled"`
+	Directory  string `json:"directory"`
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxAgeDays int    `json:"max_age_days"`
+	MaxBackups int    `json:"max_backups"`
+	Compress   bool   `json:"compress"`
+}
+
+// WebhookSettings configura a entrega dos webhooks.
+// Uma entrega que falha é repetida com espera exponencial (initial_backoff_seconds,
+// dobrando a cada tentativa até max_backoff_seconds); após max_attempts vai para a dead letter.
+type WebhookSettings struct {
+	Enabled               bool `json:"enabled"`
+	MaxAttempts           int  `json:"max_attempts"`
+	InitialBackoffSeconds int  `json:"initial_backoff_seconds"`
+	MaxBackoffSeconds     int  `json:"max_backoff_seconds"`
+	TimeoutSeconds        int  `json:"timeout_seconds"`
+}
+
+// DatabaseSettings configura o banco de dados SQLite.
+// Path vazio usa license.db no diretório de dados do sistema operacional (DataDirectory);
+// um caminho relativo é resolvido a partir da pasta do executável.
+// IntegrityCheck define a verificação feita ao abrir: "quick", "full" ou "off".
+type DatabaseSettings struct {
+	Path                   string         `json:"path"`
+	JournalMode            string         `json:"journal_mode"`
+	BusyTimeoutMs          int            `json:"busy_timeout_ms"`
+	ForeignKeys            bool           `json:"foreign_keys"`
+	MaxOpenConns           int            `json:"max_open_conns"`
+	MaxIdleConns           int            `json:"max_idle_conns"`
+	ConnMaxLifetimeMinutes int            `json:"conn_max_lifetime_minutes"`
+	IntegrityCheck         string         `json:"integrity_check"`
+	Backup                 BackupSettings `json:"backup"`
+}
+
+// BackupSettings configura as cópias de segurança do banco de dados.
+// Directory vazio usa a pasta backups ao lado do banco. Com AutoRestore, um banco que
+// falha na verificação de integridade ao iniciar é substituído pelo último backup válido.
+type BackupSettings struct {
+	Enabled       bool   `json:"enabled"`
+	Directory     string `json:"directory"`
+	IntervalHours int    `json:"interval_hours"`
+	Keep          int    `json:"keep"`
+	AutoRestore   bool   `json:"auto_restore"`
+}
+
+// LicenseSettings define como a verificação trata o servidor de licenças inacessível.
+// Fora do modo de desenvolvimento a licença continua válida offline por OfflineGraceHours
+// desde a última verificação bem-sucedida; depois disso passa a inválida. DevelopmentMode
+// troca essa regra por uma resposta simulada ("Empresa Simulada") e não deve ser usado em produção.
+// A licença é verificada de novo em segundo plano a cada CheckIntervalHours (0 desativa); se o
+// servidor não responder, a nova tentativa espera initial_backoff_seconds, dobrando a cada falha
+// até max_backoff_seconds, com variação aleatória.
+type LicenseSettings struct {
+	ServerURL             string `json:"server_url"`
+	DevelopmentMode       bool   `json:"development_mode"`
+	OfflineGraceHours     int    `json:"offline_grace_hours"`
+	CheckIntervalHours    int    `json:"check_interval_hours"`
+	InitialBackoffSeconds int    `json:"initial_backoff_seconds"`
+	MaxBackoffSeconds     int    `json:"max_backoff_seconds"`
+}
+
+var (
+	settings      = DefaultSettings()
+	settingsMutex sync.RWMutex
+)
+
+// DefaultSettings retorna as configurações padrão
+func DefaultSettings() *Settings {
+	return &Settings{
+		AccessLog: AccessLogSettings{
+			IncludeRoutes: []string{},
+			// Os endpoints de logs são consultados pela interface web a cada
+			// poucos segundos, /metrics e os health checks por monitoramento,
+			// e os arquivos estáticos não interessam ao log
+			ExcludeRoutes: []string{"/api/logs*", "/metrics", "/healthz", "/readyz", "/"},
+		},
+		Health: HealthSettings{
+			MinFreeDiskMB: 100,
+		},
+		Executor: ExecutorSettings{
+			MaxConcurrentProcesses: 10,
+		},
+		RateLimit: RateLimitSettings{
+			Enabled: true,
+			Groups: []RateLimitGroup{
+				{Name: "files", Routes: []string{"/escreve_arquivo", "/move_arquivo"}, RequestsPerSecond: 10, Burst: 20},
+				{Name: "exec", Routes: []string{"/executar_terceiros"}, RequestsPerSecond: 1, Burst: 5},
+				// verify e setup consultam o servidor remoto de licenças a cada chamada
+				{Name: "license_remote", Routes: []string{"/api/license/verify", "/api/license/setup"}, RequestsPerSecond: 0.2, Burst: 3},
+			},
+		},
+		CORS: CORSSettings{
+			AllowedOrigins: []string{
+				"http://localhost:*",
+				"http://127.0.0.1:*",
+				"https://localhost:*",
+				"https://127.0.0.1:*",
+			},
+			AllowCredentials: false,
+			AllowedMethods:   []string{"GET", "POST", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Content-Type", "Cache-Control", "Last-Event-ID", "X-Request-ID", "X-API-Key"},
+			ExposedHeaders:   []string{"X-Request-ID", "Retry-After"},
+			MaxAgeSeconds:    600,
+		},
+		Logs: LogSettings{
+			Level:         "info",
+			RetentionDays: 30,
+			File: LogFileSettings{
+				Enabled:    true,
+				MaxSizeMB:  10,
+				MaxAgeDays: 30,
+				MaxBackups: 10,
+				Compress:   true,
+			},
+		},
+		Webhooks: WebhookSettings{
+			Enabled:               true,
+			MaxAttempts:           8,
+			InitialBackoffSeconds: 10,
+			MaxBackoffSeconds:     3600,
+			TimeoutSeconds:        10,
+		},
+		Database: DatabaseSettings{
+			JournalMode:            "wal",
+			BusyTimeoutMs:          5000,
+			ForeignKeys:            true,
+			MaxOpenConns:           4,
+			MaxIdleConns:           2,
+			ConnMaxLifetimeMinutes: 30,
+			IntegrityCheck:         "quick",
+			Backup: BackupSettings{
+				Enabled:       true,
+				IntervalHours: 24,
+				Keep:          7,
+				AutoRestore:   true,
+			},
+		},
+		License: LicenseSettings{
+			ServerURL:             "http://localhost:8000",
+			DevelopmentMode:       false,
+			OfflineGraceHours:     72,
+			CheckIntervalHours:    6,
+			InitialBackoffSeconds: 30,
+			MaxBackoffSeconds:     1800,
+		},
+	}
+}
+
+// SettingsPath retorna o caminho do arquivo de configuração.
+// Usa a variável GO_DESKTOP_APP_CONFIG se definida, senão config.json ao lado do executável.
+func SettingsPath() string {
+	if path := os.Getenv(SETTINGS_ENV); path != "" {
+		return path
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "config.json"
+	}
+	return filepath.Join(filepath.Dir(exe), "config.json")
+}
+
+// LogDirectory retorna o diretório dos arquivos de log.
+// Usa logs.file.directory se definido, senão a pasta "logs" ao lado do executável.
+func LogDirectory() string {
+	if dir := GetSettings().Logs.File.Directory; dir != "" {
+		return dir
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "logs"
+	}
+	return filepath.Join(filepath.Dir(exe), "logs")
+}
+
+// DataDirectory retorna o diretório de dados da aplicação no padrão do sistema operacional:
+// %ProgramData%\GoDesktopApp no Windows (compartilhado pelo serviço e pelo modo interativo),
+// ~/Library/Application Support/go-desktop-app no macOS e $XDG_DATA_HOME/go-desktop-app
+// (ou ~/.local/share/go-desktop-app) nos demais sistemas.
+func DataDirectory() string {
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			return filepath.Join(dir, "GoDesktopApp")
+		}
+		return filepath.Join(`C:\ProgramData`, "GoDesktopApp")
+	case "darwin":
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, "Library", "Application Support", "go-desktop-app")
+		}
+	default:
+		if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+			return filepath.Join(dir, "go-desktop-app")
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, ".local", "share", "go-desktop-app")
+		}
+	}
+	return executableRelative("data")
+}
+
+// DatabasePath retorna o caminho do banco de dados configurado em database.path
+// ou, por padrão, license.db no diretório de dados
+func DatabasePath() string {
+	path := GetSettings().Database.Path
+	if path == "" {
+		return filepath.Join(DataDirectory(), "license.db")
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return executableRelative(path)
+}
+
+// BackupDirectory retorna o diretório de backups configurado em database.backup.directory
+// ou, por padrão, a pasta backups ao lado do banco de dados
+func BackupDirectory() string {
+	dir := GetSettings().Database.Backup.Directory
+	if dir == "" {
+		return filepath.Join(filepath.Dir(DatabasePath()), "backups")
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return executableRelative(dir)
+}
+
+// SecretKeyPath retorna o arquivo com o segredo da máquina que cifra o token de licença,
+// guardado ao lado do banco de dados
+func SecretKeyPath() string {
+	return filepath.Join(filepath.Dir(DatabasePath()), "license.key")
+}
+
+// LegacyDatabasePaths retorna os locais usados antes de database.path existir
+// (data/license.db no diretório atual e ao lado do executável)
+func LegacyDatabasePaths() []string {
+	paths := []string{executableRelative(filepath.Join("data", "license.db"))}
+	if cwd, err := os.Getwd(); err == nil {
+		paths = append(paths, filepath.Join(cwd, "data", "license.db"))
+	}
+	return paths
+}
+
+// executableRelative resolve um caminho relativo à pasta do executável
+func executableRelative(path string) string {
+	exe, err := os.Executable()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(filepath.Dir(exe), path)
+}
+
+// LoadSettings carrega as configurações do arquivo informado.
+// Se o arquivo não existir, mantém as configurações padrão.
+func LoadSettings(path string) error {
+	loaded := DefaultSettings()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			setSettings(loaded)
+			return nil
+		}
+		return fmt.Errorf("erro ao ler configuração: %v", err)
+	}
+
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return fmt.Errorf("erro ao interpretar configuração %s: %v", path, err)
+	}
+
+	setSettings(loaded)
+	return nil
+}
+
+// ReloadSettings relê o arquivo de configuração e publica ConfigReloaded.
+// Em caso de erro, as configurações atuais são mantidas.
+func ReloadSettings(requestID string) error {
+	path := SettingsPath()
+	if err := LoadSettings(path); err != nil {
+		return err
+	}
+	events.Publish(events.Event{Type: events.ConfigReloaded, RequestID: requestID, Data: events.Config{Path: path}})
+	return nil
+}
+
+// GetSettings retorna as configurações atuais
+func GetSettings() *Settings {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
+	return settings
+}
+
+// setSettings substitui as configurações atuais
+func setSettings(s *Settings) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+	settings = s
+}
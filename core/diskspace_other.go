@@ -0,0 +1,18 @@
+//go:build !windows
+
+package core
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// FreeDiskSpace retorna os bytes livres disponíveis no volume que contém o caminho
+func FreeDiskSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("erro ao consultar espaço em disco: %v", err)
+	}
+
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// FreeDiskSpace retorna os bytes livres disponíveis no volume que contém o caminho
+func FreeDiskSpace(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, fmt.Errorf("caminho inválido: %v", err)
+	}
+
+	var freeBytes, totalBytes, totalFreeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytes, &totalBytes, &totalFreeBytes); err != nil {
+		return 0, fmt.Errorf("erro ao consultar espaço em disco: %v", err)
+	}
+
+	return freeBytes, nil
+}
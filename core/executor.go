@@ -1,25 +1,232 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
+	"sync"
+	"time"
+
+	"go-desktop-app/config"
+	"go-desktop-app/events"
+	"go-desktop-app/logging"
+	"go-desktop-app/metrics"
+
+	"github.com/google/uuid"
+)
+
+// Status possíveis de um processo iniciado
+const (
+	JobStatusRunning = "running"
+	JobStatusExited  = "exited"
+	JobStatusFailed  = "failed"
 )
 
-// ExecuteProcess executa um processo externo de forma assíncrona
-func ExecuteProcess(executablePath string) error {
+// maxJobHistory é a quantidade de processos mantidos no histórico em memória
+const maxJobHistory = 100
+
+// logger registra o ciclo de vida dos processos iniciados
+var logger = logging.Component("core")
+
+// ErrExecutorBusy indica que o limite de processos simultâneos foi atingido
+var ErrExecutorBusy = errors.New("limite de processos simultâneos atingido")
+
+// ProcessJob representa um processo externo iniciado pela API
+type ProcessJob struct {
+	ID         string     `json:"id"`
+	RequestID  string     `json:"request_id,omitempty"`
+	Executable string     `json:"executable"`
+	PID        int        `json:"pid"`
+	Status     string     `json:"status"`
+	ExitCode   int        `json:"exit_code"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+}
+
+var (
+	jobs []*ProcessJob
+	// startingJobs conta os processos que passaram pelo limite e ainda não estão em jobs
+	startingJobs int
+	jobsMutex    sync.RWMutex
+)
+
+// publishJobEvent publica o início ou o término do processo no barramento de eventos
+func publishJobEvent(eventType events.Type, job *ProcessJob) {
+	snapshot := job.snapshot()
+	events.Publish(events.Event{
+		Type:      eventType,
+		RequestID: snapshot.RequestID,
+		Data: events.Process{
+			JobID:      snapshot.ID,
+			Executable: snapshot.Executable,
+			PID:        snapshot.PID,
+			Status:     snapshot.Status,
+			ExitCode:   snapshot.ExitCode,
+			Error:      snapshot.Error,
+			StartedAt:  snapshot.StartedAt,
+			EndedAt:    snapshot.EndedAt,
+		},
+	})
+}
+
+// ExecuteProcess executa um processo externo de forma assíncrona.
+// O ID da requisição é repassado ao processo pela variável de ambiente REQUEST_ID.
+func ExecuteProcess(executablePath, requestID string) (*ProcessJob, error) {
 	// Verifica se o arquivo executável existe
 	if _, err := os.Stat(executablePath); os.IsNotExist(err) {
-		return fmt.Errorf("executável não encontrado: %s", executablePath)
+		metrics.ProcessesFailed.Inc("start")
+		return nil, fmt.Errorf("executável não encontrado: %s", executablePath)
 	}
-	
+
+	// Respeita o limite de processos simultâneos: a vaga fica reservada até o job entrar no
+	// histórico, para que requisições simultâneas não passem todas pela verificação
+	if !reserveSlot(config.GetSettings().Executor.MaxConcurrentProcesses) {
+		metrics.ProcessesFailed.Inc("start")
+		return nil, ErrExecutorBusy
+	}
+	defer releaseSlot()
+
 	// Cria o comando
 	cmd := exec.Command(executablePath)
-	
+	cmd.Env = append(os.Environ(), "REQUEST_ID="+requestID)
+
 	// Inicia o processo de forma assíncrona (não bloqueia)
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("erro ao iniciar processo: %v", err)
+		metrics.ProcessesFailed.Inc("start")
+		return nil, fmt.Errorf("erro ao iniciar processo: %v", err)
+	}
+	metrics.ProcessesLaunched.Inc()
+	metrics.ProcessesRunning.Inc()
+
+	job := &ProcessJob{
+		ID:         uuid.New().String(),
+		RequestID:  requestID,
+		Executable: executablePath,
+		PID:        cmd.Process.Pid,
+		Status:     JobStatusRunning,
+		StartedAt:  time.Now(),
+	}
+	addJob(job)
+	publishJobEvent(events.ProcessStarted, job)
+
+	logger.Info("Processo iniciado",
+		"executable", executablePath, "pid", job.PID, "job_id", job.ID, logging.RequestIDKey, requestID)
+
+	// Aguarda o término em segundo plano para registrar o resultado
+	go waitProcess(cmd, job)
+
+	return job.snapshot(), nil
+}
+
+// waitProcess aguarda o término do processo e atualiza o job
+func waitProcess(cmd *exec.Cmd, job *ProcessJob) {
+	err := cmd.Wait()
+	metrics.ProcessesRunning.Dec()
+	if err != nil {
+		metrics.ProcessesFailed.Inc("exit")
 	}
-	
-	return nil
+
+	jobsMutex.Lock()
+	now := time.Now()
+	job.EndedAt = &now
+	job.ExitCode = cmd.ProcessState.ExitCode()
+	if err != nil {
+		job.Status = JobStatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = JobStatusExited
+	}
+	jobsMutex.Unlock()
+
+	level := slog.LevelInfo
+	if job.Status == JobStatusFailed {
+		level = slog.LevelWarn
+	}
+	publishJobEvent(events.ProcessExited, job)
+
+	logger.Log(context.Background(), level, "Processo finalizado",
+		"executable", job.Executable, "job_id", job.ID, logging.RequestIDKey, job.RequestID,
+		"exit_code", job.ExitCode, "status", job.Status)
+}
+
+// reserveSlot reserva uma vaga para um processo a iniciar. Retorna false se os processos em
+// execução e os que estão iniciando já atingiram max (0 desativa o limite).
+func reserveSlot(max int) bool {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+	if max > 0 && runningCountLocked()+startingJobs >= max {
+		return false
+	}
+	startingJobs++
+	return true
+}
+
+// releaseSlot libera a vaga reservada por reserveSlot (processo já no histórico ou que falhou ao iniciar)
+func releaseSlot() {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+	startingJobs--
+}
+
+// addJob adiciona um job ao histórico, descartando os mais antigos já finalizados
+func addJob(job *ProcessJob) {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	jobs = append(jobs, job)
+	for len(jobs) > maxJobHistory {
+		removed := false
+		for i, j := range jobs {
+			if j.Status != JobStatusRunning {
+				jobs = append(jobs[:i], jobs[i+1:]...)
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			break
+		}
+	}
+}
+
+// RunningProcessCount retorna quantos processos iniciados ainda estão em execução
+func RunningProcessCount() int {
+	jobsMutex.RLock()
+	defer jobsMutex.RUnlock()
+	return runningCountLocked()
+}
+
+// runningCountLocked conta os processos em execução; exige jobsMutex
+func runningCountLocked() int {
+	count := 0
+	for _, job := range jobs {
+		if job.Status == JobStatusRunning {
+			count++
+		}
+	}
+	return count
+}
+
+// GetProcessJobs retorna uma cópia do histórico de processos iniciados
+func GetProcessJobs() []ProcessJob {
+	jobsMutex.RLock()
+	defer jobsMutex.RUnlock()
+
+	result := make([]ProcessJob, len(jobs))
+	for i, job := range jobs {
+		result[i] = *job
+	}
+	return result
+}
+
+// snapshot retorna uma cópia do job protegida pelo mutex
+func (j *ProcessJob) snapshot() *ProcessJob {
+	jobsMutex.RLock()
+	defer jobsMutex.RUnlock()
+	copyJob := *j
+	return &copyJob
 }
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReserveSlotConcurrent(t *testing.T) {
+	tests := []struct {
+		name     string
+		max      int
+		callers  int
+		reserved int
+	}{
+		{"limite menor que as requisições", 3, 50, 3},
+		{"limite maior que as requisições", 10, 4, 4},
+		{"sem limite", 0, 20, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reserved atomic.Int32
+			var wg sync.WaitGroup
+			for range tt.callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if reserveSlot(tt.max) {
+						reserved.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+			if int(reserved.Load()) != tt.reserved {
+				t.Errorf("vagas reservadas = %d, esperado %d", reserved.Load(), tt.reserved)
+			}
+			for range reserved.Load() {
+				releaseSlot()
+			}
+			if !reserveSlot(1) {
+				t.Fatal("vaga não foi liberada por releaseSlot")
+			}
+			releaseSlot()
+		})
+	}
+}
@@ -1,16 +1,55 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	
 	"go-desktop-app/config"
+	"go-desktop-app/events"
+	"go-desktop-app/metrics"
 )
 
-// ReadFileContent lê o conteúdo de um arquivo no diretório APP_DIR
-func ReadFileContent(filename string) (string, error) {
+// fileEventTypes associa cada operação de arquivo ao tipo de evento publicado
+var fileEventTypes = map[string]events.Type{
+	"read": events.FileRead,
+	"move": events.FileMoved,
+}
+
+// recordFileOperation contabiliza uma operação de arquivo nas métricas e publica o evento
+func recordFileOperation(operation, filename, destination, requestID string, err error) {
+	data := events.File{File: filename, Destination: destination, Success: err == nil}
+	result := "success"
+	if err != nil {
+		result = "error"
+		data.Error = err.Error()
+	}
+	metrics.FileOperations.Inc(operation, result)
+	events.Publish(events.Event{Type: fileEventTypes[operation], RequestID: requestID, Data: data})
+}
+
+// ErrInvalidFileName indica um nome de arquivo vazio ou que aponta para fora do diretório
+var ErrInvalidFileName = errors.New("nome de arquivo inválido")
+
+// validateFileName garante que o nome se refere a um arquivo diretamente dentro do diretório base
+func validateFileName(filename string) error {
+	if filename == "" || filename != filepath.Base(filename) || filename == "." || filename == ".." {
+		return ErrInvalidFileName
+	}
+	return nil
+}
+
+// ReadFileContent lê o conteúdo de um arquivo no diretório APP_DIR.
+// O ID da requisição acompanha o evento FileRead.
+func ReadFileContent(filename, requestID string) (content string, err error) {
+	defer func() { recordFileOperation("read", filename, "", requestID, err) }()
+
+	if err := validateFileName(filename); err != nil {
+		return "", err
+	}
+
 	fullPath := filepath.Join(config.APP_DIR, filename)
 	
 	// Verifica se o arquivo existe
@@ -19,18 +58,25 @@ func ReadFileContent(filename string) (string, error) {
 	}
 	
 	// Lê o conteúdo do arquivo
-	content, err := ioutil.ReadFile(fullPath)
+	data, err := ioutil.ReadFile(fullPath)
 	if err != nil {
 		return "", fmt.Errorf("erro ao ler arquivo: %v", err)
 	}
 	
-	return string(content), nil
+	return string(data), nil
 }
 
-// MoveFile move um arquivo do APP_DIR para o ARCHIVE_DIR
-func MoveFile(filename string) (string, error) {
+// MoveFile move um arquivo do APP_DIR para o ARCHIVE_DIR.
+// O ID da requisição acompanha o evento FileMoved.
+func MoveFile(filename, requestID string) (destPath string, err error) {
+	defer func() { recordFileOperation("move", filename, destPath, requestID, err) }()
+
+	if err := validateFileName(filename); err != nil {
+		return "", err
+	}
+
 	sourcePath := filepath.Join(config.APP_DIR, filename)
-	destPath := filepath.Join(config.ARCHIVE_DIR, filename)
+	destPath = filepath.Join(config.ARCHIVE_DIR, filename)
 	
 	// Verifica se o arquivo de origem existe
 	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
@@ -0,0 +1,342 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resultados registrados na trilha de auditoria
+const (
+	AuditSuccess = "success" // operação concluída (status < 400)
+	AuditDenied  = "denied"  // recusada por autorização, origem ou rate limit (401, 403, 429)
+	AuditFailure = "failure" // requisição inválida ou erro na operação
+)
+
+// AuditEntry é um registro da trilha de auditoria. Hash encadeia o registro ao anterior
+// (PrevHash), de forma que qualquer alteração ou remoção quebra a cadeia.
+type AuditEntry struct {
+	ID         int64             `json:"id"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Caller     string            `json:"caller"`
+	RemoteAddr string            `json:"remote_addr"`
+	Operation  string            `json:"operation"`
+	Target     string            `json:"target,omitempty"`
+	Arguments  map[string]string `json:"arguments,omitempty"`
+	Result     string            `json:"result"`
+	StatusCode int               `json:"status_code"`
+	Error      string            `json:"error,omitempty"`
+	RequestID  string            `json:"request_id,omitempty"`
+	PrevHash   string            `json:"prev_hash"`
+	Hash       string            `json:"hash"`
+}
+
+// AuditFilter define os filtros da consulta da trilha de auditoria
+type AuditFilter struct {
+	From      time.Time
+	To        time.Time
+	Operation string
+	Result    string
+	Caller    string
+	RequestID string
+	Search    string // trecho do alvo (arquivo ou executável)
+	Limit     int
+	Offset    int
+}
+
+// AuditVerification é o resultado da conferência da cadeia de hashes
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Message  string `json:"message"`
+	LastHash string `json:"last_hash,omitempty"`
+}
+
+// auditMutex serializa as inclusões para que cada registro encadeie com o anterior
+var auditMutex sync.Mutex
+
+// auditColumns são as colunas lidas por scanAuditEntries
+const auditColumns = `id, timestamp_ms, caller, remote_addr, operation, target, arguments, result,
+	status_code, error, request_id, prev_hash, hash`
+
+// AppendAudit grava um registro na trilha de auditoria, encadeado ao último registro.
+// Preenche ID, Timestamp (se vazio), PrevHash e Hash da entrada.
+func AppendAudit(entry *AuditEntry) error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	// O hash é calculado sobre os milissegundos gravados no banco
+	entry.Timestamp = time.UnixMilli(entry.Timestamp.UnixMilli())
+
+	arguments, err := encodeAuditArguments(entry.Arguments)
+	if err != nil {
+		recordError("append_audit")
+		return fmt.Errorf("erro ao codificar argumentos da auditoria: %v", err)
+	}
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		recordError("append_audit")
+		return fmt.Errorf("erro ao gravar auditoria: %v", err)
+	}
+	defer tx.Rollback()
+
+	var prevHash string
+	err = tx.QueryRow(`SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		recordError("append_audit")
+		return fmt.Errorf("erro ao ler último registro da auditoria: %v", err)
+	}
+
+	entry.PrevHash = prevHash
+	entry.Hash = auditHash(entry, arguments)
+
+	result, err := tx.Exec(`
+		INSERT INTO audit_log
+			(timestamp_ms, caller, remote_addr, operation, target, arguments, result,
+			 status_code, error, request_id, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Timestamp.UnixMilli(), entry.Caller, entry.RemoteAddr, entry.Operation, entry.Target, arguments,
+		entry.Result, entry.StatusCode, entry.Error, entry.RequestID, entry.PrevHash, entry.Hash)
+	if err != nil {
+		recordError("append_audit")
+		return fmt.Errorf("erro ao gravar auditoria: %v", err)
+	}
+	if entry.ID, err = result.LastInsertId(); err != nil {
+		recordError("append_audit")
+		return fmt.Errorf("erro ao gravar auditoria: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		recordError("append_audit")
+		return fmt.Errorf("erro ao gravar auditoria: %v", err)
+	}
+	return nil
+}
+
+// QueryAudit retorna os registros que atendem aos filtros, do mais recente para o mais antigo,
+// junto com o total de registros filtrados
+func QueryAudit(filter AuditFilter) ([]AuditEntry, int, error) {
+	if db == nil {
+		return nil, 0, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	where, args := auditConditions(filter)
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM audit_log "+where, args...).Scan(&total); err != nil {
+		recordError("query_audit")
+		return nil, 0, fmt.Errorf("erro ao contar registros de auditoria: %v", err)
+	}
+
+	rows, err := db.Query(`SELECT `+auditColumns+` FROM audit_log `+where+`
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		recordError("query_audit")
+		return nil, 0, fmt.Errorf("erro ao consultar auditoria: %v", err)
+	}
+
+	entries, err := scanAuditEntries(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// ExportAudit percorre em ordem cronológica todos os registros que atendem aos filtros.
+// Limit e Offset são ignorados; a leitura é feita em páginas.
+func ExportAudit(filter AuditFilter, fn func(AuditEntry) error) error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+
+	where, args := auditConditions(filter)
+	if where == "" {
+		where = "WHERE id > ?"
+	} else {
+		where += " AND id > ?"
+	}
+	query := `SELECT ` + auditColumns + ` FROM audit_log ` + where + ` ORDER BY id LIMIT ?`
+
+	var lastID int64
+	for {
+		rows, err := db.Query(query, append(args, lastID, exportPageSize)...)
+		if err != nil {
+			recordError("export_audit")
+			return fmt.Errorf("erro ao exportar auditoria: %v", err)
+		}
+		page, err := scanAuditEntries(rows)
+		if err != nil {
+			return err
+		}
+		for _, entry := range page {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(page) < exportPageSize {
+			return nil
+		}
+		lastID = page[len(page)-1].ID
+	}
+}
+
+// VerifyAuditChain recalcula os hashes de todos os registros e confere o encadeamento.
+// Detecta registros alterados, removidos ou inseridos fora da ordem; a remoção dos
+// registros finais só é detectada comparando LastHash com um valor guardado externamente.
+func VerifyAuditChain() (*AuditVerification, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	verification := &AuditVerification{Valid: true}
+	err := ExportAudit(AuditFilter{}, func(entry AuditEntry) error {
+		arguments, err := encodeAuditArguments(entry.Arguments)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.PrevHash != verification.LastHash:
+			verification.Message = fmt.Sprintf("registro %d não aponta para o registro anterior", entry.ID)
+		case entry.Hash != auditHash(&entry, arguments):
+			verification.Message = fmt.Sprintf("registro %d foi alterado", entry.ID)
+		default:
+			verification.Checked++
+			verification.LastHash = entry.Hash
+			return nil
+		}
+
+		verification.Valid = false
+		verification.BrokenAt = entry.ID
+		return errAuditChainBroken
+	})
+	if err != nil && err != errAuditChainBroken {
+		return nil, err
+	}
+
+	if verification.Valid {
+		verification.Message = fmt.Sprintf("%d registros conferidos, cadeia íntegra", verification.Checked)
+	}
+	return verification, nil
+}
+
+// errAuditChainBroken interrompe a varredura de VerifyAuditChain no primeiro registro inválido
+var errAuditChainBroken = fmt.Errorf("cadeia de auditoria quebrada")
+
+// auditHash calcula o SHA-256 do registro (com os argumentos já codificados) e do hash anterior
+func auditHash(entry *AuditEntry, arguments string) string {
+	// Os campos são serializados em uma ordem fixa para que o hash seja reproduzível
+	canonical, _ := json.Marshal([]interface{}{
+		entry.PrevHash,
+		entry.Timestamp.UnixMilli(),
+		entry.Caller,
+		entry.RemoteAddr,
+		entry.Operation,
+		entry.Target,
+		arguments,
+		entry.Result,
+		entry.StatusCode,
+		entry.Error,
+		entry.RequestID,
+	})
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeAuditArguments serializa os argumentos (chaves ordenadas pelo encoding/json)
+func encodeAuditArguments(arguments map[string]string) (string, error) {
+	if len(arguments) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// scanAuditEntries lê os registros de auditoria e fecha rows
+func scanAuditEntries(rows *sql.Rows) ([]AuditEntry, error) {
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		var timestampMs int64
+		var arguments string
+		err := rows.Scan(&entry.ID, &timestampMs, &entry.Caller, &entry.RemoteAddr, &entry.Operation,
+			&entry.Target, &arguments, &entry.Result, &entry.StatusCode, &entry.Error, &entry.RequestID,
+			&entry.PrevHash, &entry.Hash)
+		if err != nil {
+			recordError("query_audit")
+			return nil, fmt.Errorf("erro ao ler registro de auditoria: %v", err)
+		}
+		entry.Timestamp = time.UnixMilli(timestampMs)
+		if arguments != "" {
+			if err := json.Unmarshal([]byte(arguments), &entry.Arguments); err != nil {
+				recordError("query_audit")
+				return nil, fmt.Errorf("erro ao ler argumentos do registro %d: %v", entry.ID, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		recordError("query_audit")
+		return nil, fmt.Errorf("erro ao iterar auditoria: %v", err)
+	}
+	return entries, nil
+}
+
+// auditConditions monta a cláusula WHERE da consulta de auditoria
+func auditConditions(filter AuditFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "timestamp_ms >= ?")
+		args = append(args, filter.From.UnixMilli())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "timestamp_ms <= ?")
+		args = append(args, filter.To.UnixMilli())
+	}
+	if filter.Operation != "" {
+		conditions = append(conditions, "operation = ?")
+		args = append(args, filter.Operation)
+	}
+	if filter.Result != "" {
+		conditions = append(conditions, "result = ?")
+		args = append(args, filter.Result)
+	}
+	if filter.Caller != "" {
+		conditions = append(conditions, "caller = ?")
+		args = append(args, filter.Caller)
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, filter.RequestID)
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "target LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+)
+
+// appendTestAudit grava n registros de auditoria encadeados
+func appendTestAudit(t *testing.T, n int) []AuditEntry {
+	t.Helper()
+	entries := make([]AuditEntry, n)
+	for i := range entries {
+		entries[i] = AuditEntry{
+			Caller:     "api-key:teste",
+			RemoteAddr: "127.0.0.1",
+			Operation:  "execute",
+			Target:     fmt.Sprintf("C:\\app\\programa%d.exe", i+1),
+			Arguments:  map[string]string{"args": fmt.Sprint(i)},
+			Result:     AuditSuccess,
+			StatusCode: 200,
+			RequestID:  fmt.Sprintf("req-%d", i+1),
+		}
+		if err := AppendAudit(&entries[i]); err != nil {
+			t.Fatalf("AppendAudit: %v", err)
+		}
+	}
+	return entries
+}
+
+// tamperAudit altera audit_log direto no arquivo, como faria quem tem acesso ao banco
+// (os gatilhos que impedem UPDATE e DELETE são removidos antes)
+func tamperAudit(t *testing.T, statement string) {
+	t.Helper()
+	for _, drop := range []string{
+		`DROP TRIGGER IF EXISTS audit_log_no_update`,
+		`DROP TRIGGER IF EXISTS audit_log_no_delete`,
+		statement,
+	} {
+		if _, err := db.Exec(drop); err != nil {
+			t.Fatalf("%s: %v", drop, err)
+		}
+	}
+}
+
+func TestAppendAuditChainsEntries(t *testing.T) {
+	openTestDatabase(t)
+	entries := appendTestAudit(t, 3)
+
+	if entries[0].PrevHash != "" {
+		t.Errorf("primeiro registro com PrevHash %q", entries[0].PrevHash)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].PrevHash != entries[i-1].Hash {
+			t.Errorf("registro %d não aponta para o anterior", entries[i].ID)
+		}
+	}
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   string
+		valid    bool
+		brokenAt int64
+	}{
+		{"cadeia íntegra", "", true, 0},
+		{"alvo alterado", `UPDATE audit_log SET target = 'C:\outro.exe' WHERE id = 2`, false, 2},
+		{"resultado alterado", `UPDATE audit_log SET result = 'denied', status_code = 403 WHERE id = 3`, false, 3},
+		{"argumentos alterados", `UPDATE audit_log SET arguments = '{"args":"x"}' WHERE id = 1`, false, 1},
+		{"registro removido", `DELETE FROM audit_log WHERE id = 2`, false, 3},
+		{"prev_hash alterado", `UPDATE audit_log SET prev_hash = '' WHERE id = 2`, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDatabase(t)
+			entries := appendTestAudit(t, 4)
+			if tt.tamper != "" {
+				tamperAudit(t, tt.tamper)
+			}
+
+			verification, err := VerifyAuditChain()
+			if err != nil {
+				t.Fatalf("VerifyAuditChain: %v", err)
+			}
+			if verification.Valid != tt.valid || verification.BrokenAt != tt.brokenAt {
+				t.Errorf("VerifyAuditChain = valid %v, broken_at %d (%s); esperado valid %v, broken_at %d",
+					verification.Valid, verification.BrokenAt, verification.Message, tt.valid, tt.brokenAt)
+			}
+			if tt.valid && (verification.Checked != len(entries) || verification.LastHash != entries[len(entries)-1].Hash) {
+				t.Errorf("VerifyAuditChain conferiu %d registros, último hash %q", verification.Checked, verification.LastHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-desktop-app/metrics"
+)
+
+// Nome dos arquivos de backup: license-20060102-150405.000.db
+const (
+	backupPrefix     = "license-"
+	backupSuffix     = ".db"
+	backupTimeFormat = "20060102-150405.000"
+)
+
+// BackupInfo descreve um arquivo de backup do banco de dados
+type BackupInfo struct {
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupSchedule configura os backups periódicos feitos por StartBackupScheduler
+type BackupSchedule struct {
+	// Directory é a pasta dos backups
+	Directory string
+	// Interval é o intervalo entre backups
+	Interval time.Duration
+	// Keep é a quantidade de backups mantidos (0 mantém todos)
+	Keep int
+}
+
+var (
+	backupOnce sync.Once
+	// backupDirectory é a pasta dos backups informada em InitDatabase (usada nas estatísticas)
+	backupDirectory string
+	// backupMutex impede dois backups simultâneos (agendado e manual) com o mesmo nome
+	backupMutex sync.Mutex
+)
+
+// BackupTo grava uma cópia consistente do banco aberto em dest usando VACUUM INTO.
+// A cópia é feita com a aplicação em uso, é compactada e conferida com integrity_check
+// antes de receber o nome final; dest não pode existir.
+func BackupTo(dest string) error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("arquivo de backup já existe: %s", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return fmt.Errorf("erro ao criar diretório de backup %s: %v", filepath.Dir(dest), err)
+	}
+
+	// O arquivo parcial não segue o padrão de nome, então nunca é listado como backup
+	partial := dest + ".partial"
+	os.Remove(partial)
+	if _, err := db.Exec("VACUUM INTO ?", partial); err != nil {
+		os.Remove(partial)
+		recordError("backup")
+		metrics.DatabaseBackups.Inc("error")
+		return fmt.Errorf("erro ao criar backup %s: %v", dest, err)
+	}
+	if err := VerifyBackup(partial); err != nil {
+		os.Remove(partial)
+		metrics.DatabaseBackups.Inc("error")
+		return fmt.Errorf("backup %s inválido: %w", dest, err)
+	}
+	if err := os.Rename(partial, dest); err != nil {
+		os.Remove(partial)
+		metrics.DatabaseBackups.Inc("error")
+		return fmt.Errorf("erro ao gravar backup %s: %v", dest, err)
+	}
+
+	metrics.DatabaseBackups.Inc("success")
+	metrics.DatabaseLastBackup.Set(float64(time.Now().Unix()))
+	return nil
+}
+
+// CreateBackup grava um backup com data e hora no nome dentro de dir
+func CreateBackup(dir string) (*BackupInfo, error) {
+	backupMutex.Lock()
+	defer backupMutex.Unlock()
+
+	now := time.Now().UTC()
+	path := filepath.Join(dir, backupPrefix+now.Format(backupTimeFormat)+backupSuffix)
+	if err := BackupTo(path); err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler backup %s: %v", path, err)
+	}
+	logger.Info("Backup do banco de dados criado", "path", path, "size", stat.Size())
+	return &BackupInfo{Path: path, Size: stat.Size(), CreatedAt: now}, nil
+}
+
+// ListBackups lista os backups de dir, do mais recente para o mais antigo.
+// Um diretório inexistente não é erro.
+func ListBackups(dir string) ([]BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []BackupInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar backups em %s: %v", dir, err)
+	}
+
+	backups := []BackupInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{Path: filepath.Join(dir, name), Size: info.Size(), CreatedAt: createdAt})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// PruneBackups remove os backups mais antigos de dir, mantendo os keep mais recentes.
+// Retorna a quantidade removida.
+func PruneBackups(dir string, keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, backup := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(backup.Path); err != nil {
+			logger.Warn("Erro ao remover backup antigo", "path", backup.Path, "error", err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// VerifyBackup abre o arquivo somente para leitura e executa PRAGMA integrity_check.
+// Um arquivo corrompido retorna um erro que satisfaz errors.Is(err, ErrCorrupted).
+func VerifyBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("backup não encontrado: %s", path)
+	}
+
+	conn, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("erro ao abrir backup %s: %v", path, err)
+	}
+	defer conn.Close()
+
+	if err := checkIntegrity(conn, true); err != nil {
+		return err
+	}
+
+	// Um banco válido sem a tabela de licença não serve para restaurar a aplicação
+	var count int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM license_info").Scan(&count); err != nil {
+		return fmt.Errorf("backup %s sem a tabela license_info: %v", path, err)
+	}
+	return nil
+}
+
+// RestoreBackup substitui o banco em path pelo backup informado. O banco precisa estar
+// fechado. O arquivo atual (com os arquivos -wal e -shm) é renomeado para
+// path.pre-restore-<data>, e não removido, para permitir análise posterior.
+func RestoreBackup(path, backupPath string) error {
+	if db != nil && dbPath == path {
+		return fmt.Errorf("feche o banco de dados antes de restaurar um backup")
+	}
+	if err := VerifyBackup(backupPath); err != nil {
+		return err
+	}
+
+	// A cópia é feita ao lado do banco e só então renomeada, para não deixar um banco parcial
+	restoring := path + ".restoring"
+	os.Remove(restoring)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório do banco de dados %s: %v", filepath.Dir(path), err)
+	}
+	if err := copyFile(backupPath, restoring); err != nil {
+		return fmt.Errorf("erro ao copiar backup %s: %v", backupPath, err)
+	}
+
+	// Um -wal antigo seria reaplicado sobre o banco restaurado, então sai junto com o banco
+	suffix := ".pre-restore-" + time.Now().UTC().Format(backupTimeFormat)
+	for _, name := range []string{path, path + "-wal", path + "-shm"} {
+		if _, err := os.Stat(name); err != nil {
+			continue
+		}
+		if err := os.Rename(name, name+suffix); err != nil {
+			os.Remove(restoring)
+			return fmt.Errorf("erro ao preservar %s: %v", name, err)
+		}
+	}
+
+	if err := os.Rename(restoring, path); err != nil {
+		return fmt.Errorf("erro ao restaurar backup em %s: %v", path, err)
+	}
+	logger.Warn("Banco de dados restaurado de backup", "path", path, "backup", backupPath, "previous", path+suffix)
+	return nil
+}
+
+// RestoreLatestBackup restaura em path o backup mais recente de dir que passa em VerifyBackup
+func RestoreLatestBackup(path, dir string) (*BackupInfo, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, backup := range backups {
+		if err := VerifyBackup(backup.Path); err != nil {
+			logger.Warn("Backup ignorado na restauração", "path", backup.Path, "error", err)
+			continue
+		}
+		if err := RestoreBackup(path, backup.Path); err != nil {
+			return nil, err
+		}
+		return &backup, nil
+	}
+	return nil, fmt.Errorf("nenhum backup válido em %s", dir)
+}
+
+// StartBackupScheduler inicia os backups periódicos do banco aberto por InitDatabase.
+// O primeiro backup é feito quando o mais recente de schedule.Directory tiver mais de
+// schedule.Interval (imediatamente se não houver nenhum); após cada backup os mais
+// antigos que schedule.Keep são removidos.
+func StartBackupScheduler(schedule BackupSchedule) {
+	if schedule.Interval <= 0 {
+		return
+	}
+	backupOnce.Do(func() {
+		go runBackupScheduler(schedule)
+	})
+}
+
+// runBackupScheduler aguarda o vencimento do próximo backup e o executa
+func runBackupScheduler(schedule BackupSchedule) {
+	for {
+		wait := time.Duration(0)
+		if backups, err := ListBackups(schedule.Directory); err == nil && len(backups) > 0 {
+			wait = time.Until(backups[0].CreatedAt.Add(schedule.Interval))
+		}
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+
+		if _, err := CreateBackup(schedule.Directory); err != nil {
+			logger.Error("Erro no backup agendado do banco de dados", "error", err)
+			// Nova tentativa em uma hora (ou no intervalo, se menor) em vez de repetir em laço
+			retry := time.Hour
+			if schedule.Interval < retry {
+				retry = schedule.Interval
+			}
+			time.Sleep(retry)
+			continue
+		}
+		if removed, err := PruneBackups(schedule.Directory, schedule.Keep); err != nil {
+			logger.Warn("Erro ao remover backups antigos", "error", err)
+		} else if removed > 0 {
+			logger.Info("Backups antigos removidos", "removed", removed, "keep", schedule.Keep)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeBackupFile cria um arquivo com o nome de backup da data informada
+func writeBackupFile(t *testing.T, dir string, at time.Time, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, backupPrefix+at.UTC().Format(backupTimeFormat)+backupSuffix)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	options := openTestDatabase(t)
+	store := Licenses()
+	if err := store.Save("token-do-backup", "device-1"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	backup, err := CreateBackup(options.BackupDirectory)
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+	if err := VerifyBackup(backup.Path); err != nil {
+		t.Fatalf("VerifyBackup: %v", err)
+	}
+
+	// Alterações depois do backup são desfeitas pela restauração
+	if err := store.Save("token-novo", "device-2"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := RestoreBackup(options.Path, backup.Path); err == nil {
+		t.Fatal("RestoreBackup com o banco aberto deveria falhar")
+	}
+
+	CloseDatabase()
+	if err := RestoreBackup(options.Path, backup.Path); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if err := InitDatabase(options); err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+
+	info, err := Licenses().Get()
+	if err != nil || info == nil {
+		t.Fatalf("Get = %v, %v", info, err)
+	}
+	if info.Token != "token-do-backup" || info.DeviceUUID != "device-1" {
+		t.Errorf("licença restaurada = %s/%s, esperado a do backup", info.Token, info.DeviceUUID)
+	}
+
+	// O banco substituído é preservado ao lado do restaurado
+	previous, _ := filepath.Glob(options.Path + ".pre-restore-*")
+	if len(previous) == 0 {
+		t.Error("banco anterior não foi preservado")
+	}
+}
+
+func TestListAndPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	var paths []string
+	for i := 0; i < 4; i++ {
+		paths = append(paths, writeBackupFile(t, dir, base.Add(time.Duration(i)*time.Hour), "x"))
+	}
+	// Arquivos fora do padrão de nome são ignorados
+	os.WriteFile(filepath.Join(dir, "license-invalido.db"), nil, 0600)
+	os.WriteFile(filepath.Join(dir, filepath.Base(paths[0])+".partial"), nil, 0600)
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 4 || backups[0].Path != paths[3] || backups[3].Path != paths[0] {
+		t.Fatalf("backups = %v, esperado os 4 do mais recente para o mais antigo", backups)
+	}
+
+	removed, err := PruneBackups(dir, 2)
+	if err != nil || removed != 2 {
+		t.Fatalf("PruneBackups = %d, %v; esperado 2 removidos", removed, err)
+	}
+	backups, _ = ListBackups(dir)
+	if len(backups) != 2 || backups[0].Path != paths[3] || backups[1].Path != paths[2] {
+		t.Errorf("restaram %v, esperado os 2 mais recentes", backups)
+	}
+
+	if backups, err := ListBackups(filepath.Join(dir, "inexistente")); err != nil || len(backups) != 0 {
+		t.Errorf("ListBackups de diretório inexistente = %v, %v", backups, err)
+	}
+}
+
+func TestVerifyBackupRejects(t *testing.T) {
+	dir := t.TempDir()
+
+	corrupted := writeBackupFile(t, dir, time.Now(), strings.Repeat("não é um banco ", 100))
+	if err := VerifyBackup(corrupted); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("VerifyBackup(corrompido) = %v, esperado ErrCorrupted", err)
+	}
+	if err := VerifyBackup(filepath.Join(dir, "inexistente.db")); err == nil {
+		t.Error("VerifyBackup de arquivo inexistente deveria falhar")
+	}
+}
+
+func TestAutoRestoreSkipsInvalidBackups(t *testing.T) {
+	options := openTestDatabase(t)
+	if err := Licenses().Save("token-valido", "device-1"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := CreateBackup(options.BackupDirectory); err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+	// Um backup mais recente, porém corrompido, é ignorado
+	writeBackupFile(t, options.BackupDirectory, time.Now().Add(time.Hour), "corrompido")
+	CloseDatabase()
+
+	// Corrompe o banco e reabre com restauração automática
+	for _, name := range []string{options.Path + "-wal", options.Path + "-shm"} {
+		os.Remove(name)
+	}
+	if err := os.WriteFile(options.Path, []byte(strings.Repeat("lixo", 2048)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	options.IntegrityCheck = IntegrityQuick
+	options.AutoRestore = true
+	if err := InitDatabase(options); err != nil {
+		t.Fatalf("InitDatabase com restauração automática: %v", err)
+	}
+
+	info, err := Licenses().Get()
+	if err != nil || info == nil || info.Token != "token-valido" {
+		t.Errorf("licença após restauração automática = %v, %v", info, err)
+	}
+}
@@ -1,11 +1,20 @@
 package database
 
 import (
+	"crypto/subtle"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-desktop-app/logging"
+	"go-desktop-app/metrics"
+	"go-desktop-app/secrets"
 
 	_ "modernc.org/sqlite" // Pure Go SQLite driver (no CGO required)
 	// _ "github.com/mattn/go-sqlite3" // CGO-based driver (commented out)
@@ -13,248 +22,336 @@ import (
 
 // LicenseInfo representa as informações de licença armazenadas
 type LicenseInfo struct {
-	ID         int    `json:"id"`
-	Token      string `json:"token"`
+	ID int `json:"id"`
+	// Token é o token de licença já decifrado; nunca é serializado (status, exportação, eventos)
+	Token      string `json:"-"`
 	DeviceUUID string `json:"device_uuid"`
 	IsActive   bool   `json:"is_active"`
 	CreatedAt  string `json:"created_at"`
 	LastCheck  string `json:"last_check"`
+	// LastVerifiedAt é o horário da última resposta válida do servidor de licenças
+	LastVerifiedAt string `json:"last_verified_at,omitempty"`
+	// Fingerprint é a impressão digital da máquina em que a licença foi registrada
+	Fingerprint string `json:"-"`
 }
 
-var db *sql.DB
+// Modos de verificação de integridade ao abrir o banco
+const (
+	IntegrityQuick = "quick" // PRAGMA quick_check (padrão)
+	IntegrityFull  = "full"  // PRAGMA integrity_check, também confere os índices
+	IntegrityOff   = "off"
+)
 
-// InitDatabase inicializa a conexão com o banco de dados
-func InitDatabase() error {
-	// Cria o diretório de dados se não existir
-	dataDir := "data"
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de dados: %v", err)
-	}
+// ErrCorrupted indica que o banco falhou na verificação de integridade
+var ErrCorrupted = errors.New("banco de dados corrompido")
+
+// journalModes são os modos de journal aceitos em Options.JournalMode
+var journalModes = map[string]bool{"wal": true, "delete": true, "truncate": true, "persist": true}
+
+// Options configura a abertura do banco de dados
+type Options struct {
+	// Path é o caminho do arquivo do banco; o diretório é criado se não existir
+	Path string
+	// LegacyPaths são locais antigos do banco; se Path não existir, o primeiro encontrado é copiado
+	LegacyPaths []string
+	// JournalMode é o modo de journal do SQLite (wal, delete, truncate ou persist)
+	JournalMode string
+	// BusyTimeout é quanto uma conexão espera por um lock antes de falhar com SQLITE_BUSY
+	BusyTimeout time.Duration
+	// ForeignKeys ativa a verificação de chaves estrangeiras em cada conexão
+	ForeignKeys bool
+	// MaxOpenConns, MaxIdleConns e ConnMaxLifetime dimensionam o pool (0 = padrão do database/sql)
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	// IntegrityCheck é IntegrityQuick, IntegrityFull ou IntegrityOff
+	IntegrityCheck string
+	// BackupDirectory é a pasta dos backups usada pela restauração automática
+	BackupDirectory string
+	// AutoRestore restaura o último backup válido quando o banco está corrompido ao iniciar
+	AutoRestore bool
+	// Secrets cifra o token de licença gravado no banco
+	Secrets secrets.SecretStore
+}
 
-	// Caminho do banco de dados
-	dbPath := filepath.Join(dataDir, "license.db")
+var (
+	db     *sql.DB
+	dbPath string
+	// tokenSecrets cifra os tokens de license_info (Options.Secrets)
+	tokenSecrets secrets.SecretStore
+)
 
-	// Abre a conexão com o banco
-	var err error
-	db, err = sql.Open("sqlite", dbPath)
-	if err != nil {
-		return fmt.Errorf("erro ao abrir banco de dados: %v", err)
+// logger registra os eventos do banco de dados
+var logger = logging.Component("database")
+
+// InitDatabase abre o banco de dados e aplica as migrações pendentes.
+// Com options.AutoRestore, um banco corrompido é substituído pelo backup válido mais recente.
+func InitDatabase(options Options) error {
+	err := OpenDatabase(options)
+	if errors.Is(err, ErrCorrupted) && options.AutoRestore && options.BackupDirectory != "" {
+		logger.Error("Banco de dados corrompido, restaurando o último backup válido",
+			"path", options.Path, "error", err)
+		backup, restoreErr := RestoreLatestBackup(options.Path, options.BackupDirectory)
+		if restoreErr != nil {
+			return fmt.Errorf("%w (restauração automática falhou: %v)", err, restoreErr)
+		}
+		logger.Warn("Banco de dados restaurado automaticamente",
+			"backup", backup.Path, "backup_created_at", backup.CreatedAt)
+		err = OpenDatabase(options)
 	}
-
-	// Testa a conexão
-	if err := db.Ping(); err != nil {
-		return fmt.Errorf("erro ao conectar com banco de dados: %v", err)
+	if err != nil {
+		return err
 	}
+	backupDirectory = options.BackupDirectory
 
-	// Cria as tabelas se não existirem
-	if err := createTables(); err != nil {
-		return fmt.Errorf("erro ao criar tabelas: %v", err)
+	// Atualiza o esquema para a versão mais recente
+	if _, err := MigrateUp(0); err != nil {
+		return fmt.Errorf("erro ao migrar banco de dados %s: %v", dbPath, err)
 	}
 
-	log.Println("Banco de dados inicializado com sucesso")
+	logger.Info("Banco de dados inicializado com sucesso", "path", dbPath, "journal_mode", options.JournalMode)
 	return nil
 }
 
-// createTables cria as tabelas necessárias
-func createTables() error {
-	// Tabela principal para informações de licença
-	licenseTableQuery := `
-	CREATE TABLE IF NOT EXISTS license_info (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		token TEXT NOT NULL UNIQUE,
-		device_uuid TEXT NOT NULL UNIQUE,
-		is_active BOOLEAN DEFAULT FALSE,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		last_check DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-	`
+// OpenDatabase abre a conexão com o banco de dados, aplica as configurações de conexão
+// e verifica a integridade, sem aplicar migrações (usado pelos subcomandos migrate).
+// Uma falha de integridade retorna um erro que satisfaz errors.Is(err, ErrCorrupted).
+func OpenDatabase(options Options) error {
+	if options.Path == "" {
+		return fmt.Errorf("caminho do banco de dados não configurado")
+	}
+	journalMode := strings.ToLower(options.JournalMode)
+	if journalMode == "" {
+		journalMode = "wal"
+	}
+	if !journalModes[journalMode] {
+		return fmt.Errorf("database.journal_mode inválido: %q (use wal, delete, truncate ou persist)", options.JournalMode)
+	}
+	switch options.IntegrityCheck {
+	case "", IntegrityQuick, IntegrityFull, IntegrityOff:
+	default:
+		return fmt.Errorf("database.integrity_check inválido: %q (use quick, full ou off)", options.IntegrityCheck)
+	}
 
-	_, err := db.Exec(licenseTableQuery)
+	// Cria o diretório de dados se não existir
+	if err := os.MkdirAll(filepath.Dir(options.Path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório do banco de dados %s: %v", filepath.Dir(options.Path), err)
+	}
+	if err := adoptLegacyDatabase(options.Path, options.LegacyPaths); err != nil {
+		return err
+	}
+
+	// Os pragmas vão no DSN para valerem em todas as conexões do pool
+	dsn := options.Path + "?_pragma=journal_mode(" + journalMode + ")" +
+		"&_pragma=busy_timeout(" + strconv.FormatInt(options.BusyTimeout.Milliseconds(), 10) + ")" +
+		"&_txlock=immediate"
+	if options.ForeignKeys {
+		dsn += "&_pragma=foreign_keys(1)"
+	}
+
+	// Abre a conexão com o banco
+	conn, err := sql.Open("sqlite", dsn)
 	if err != nil {
-		return fmt.Errorf("erro ao criar tabela license_info: %v", err)
+		recordError("init")
+		return fmt.Errorf("erro ao abrir banco de dados %s: %v", options.Path, err)
 	}
+	conn.SetMaxOpenConns(options.MaxOpenConns)
+	conn.SetMaxIdleConns(options.MaxIdleConns)
+	conn.SetConnMaxLifetime(options.ConnMaxLifetime)
 
-	// Índices para melhor performance
-	indexQueries := []string{
-		`CREATE INDEX IF NOT EXISTS idx_license_token ON license_info(token);`,
-		`CREATE INDEX IF NOT EXISTS idx_license_uuid ON license_info(device_uuid);`,
-		`CREATE INDEX IF NOT EXISTS idx_license_active ON license_info(is_active);`,
+	// Testa a conexão
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		recordError("init")
+		if isCorruptionError(err) {
+			return fmt.Errorf("%s: %w: %v", options.Path, ErrCorrupted, err)
+		}
+		return fmt.Errorf("erro ao conectar com banco de dados %s: %v", options.Path, err)
 	}
 
-	for _, indexQuery := range indexQueries {
-		_, err := db.Exec(indexQuery)
-		if err != nil {
-			log.Printf("Aviso: erro ao criar índice: %v", err)
+	if options.IntegrityCheck != IntegrityOff {
+		if err := checkIntegrity(conn, options.IntegrityCheck == IntegrityFull); err != nil {
+			conn.Close()
+			return fmt.Errorf("%s: %w", options.Path, err)
 		}
 	}
 
-	log.Println("Tabelas e índices criados com sucesso")
+	db, dbPath, tokenSecrets = conn, options.Path, options.Secrets
 	return nil
 }
 
-// CloseDatabase fecha a conexão com o banco de dados
-func CloseDatabase() error {
-	if db != nil {
-		return db.Close()
-	}
-	return nil
+// Path retorna o caminho do banco aberto por OpenDatabase
+func Path() string {
+	return dbPath
 }
 
-// HasLicenseInfo verifica se há informações de licença armazenadas
-func HasLicenseInfo() bool {
-	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM license_info").Scan(&count)
-	if err != nil {
-		log.Printf("Erro ao verificar licença: %v", err)
-		return false
+// CheckIntegrity executa PRAGMA integrity_check (full) ou quick_check no banco aberto
+func CheckIntegrity(full bool) error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
 	}
-	return count > 0
+	return checkIntegrity(db, full)
 }
 
-// GetLicenseInfo recupera as informações de licença
-func GetLicenseInfo() (*LicenseInfo, error) {
-	var info LicenseInfo
-	query := `
-		SELECT id, token, device_uuid, is_active, created_at, last_check 
-		FROM license_info 
-		ORDER BY id DESC 
-		LIMIT 1
-	`
-
-	err := db.QueryRow(query).Scan(
-		&info.ID,
-		&info.Token,
-		&info.DeviceUUID,
-		&info.IsActive,
-		&info.CreatedAt,
-		&info.LastCheck,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
+// checkIntegrity executa a verificação e junta os problemas encontrados em um erro ErrCorrupted
+func checkIntegrity(conn *sql.DB, full bool) error {
+	pragma := "PRAGMA quick_check"
+	if full {
+		pragma = "PRAGMA integrity_check"
 	}
 
+	rows, err := conn.Query(pragma)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao recuperar informações de licença: %v", err)
+		recordError("integrity_check")
+		// "file is not a database" e similares aparecem já na consulta
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
 	}
+	defer rows.Close()
 
-	return &info, nil
-}
-
-// SaveLicenseInfo salva as informações de licença
-func SaveLicenseInfo(token, deviceUUID string) error {
-	// Valida os parâmetros
-	if token == "" {
-		return fmt.Errorf("token não pode estar vazio")
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			recordError("integrity_check")
+			return fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
 	}
-	if deviceUUID == "" {
-		return fmt.Errorf("device UUID não pode estar vazio")
+	if err := rows.Err(); err != nil {
+		recordError("integrity_check")
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
 	}
 
-	// Remove informações antigas
-	if err := ClearLicenseInfo(); err != nil {
-		log.Printf("Aviso: erro ao limpar licença antiga: %v", err)
+	if len(problems) > 0 {
+		recordError("integrity_check")
+		if len(problems) > 5 {
+			problems = append(problems[:5], fmt.Sprintf("... e mais %d problemas", len(problems)-5))
+		}
+		return fmt.Errorf("%w: %s", ErrCorrupted, strings.Join(problems, "; "))
 	}
+	return nil
+}
 
-	// Insere nova informação com timestamp atual
-	query := `
-		INSERT INTO license_info (token, device_uuid, is_active, created_at, last_check, updated_at)
-		VALUES (?, ?, TRUE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-	`
-
-	result, err := db.Exec(query, token, deviceUUID)
-	if err != nil {
-		return fmt.Errorf("erro ao salvar informações de licença: %v", err)
+// isCorruptionError identifica SQLITE_CORRUPT (11) e SQLITE_NOTADB (26), inclusive os códigos estendidos
+func isCorruptionError(err error) bool {
+	var coded interface{ Code() int }
+	if !errors.As(err, &coded) {
+		return false
 	}
+	code := coded.Code() & 0xff
+	return code == 11 || code == 26
+}
 
-	// Verifica se a inserção foi bem-sucedida
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Aviso: não foi possível verificar linhas afetadas: %v", err)
-	} else if rowsAffected == 0 {
-		return fmt.Errorf("nenhuma linha foi inserida")
+// adoptLegacyDatabase copia o banco de um local antigo quando path ainda não existe,
+// preservando a licença e o UUID do dispositivo de instalações anteriores
+func adoptLegacyDatabase(path string, legacyPaths []string) error {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return nil
 	}
 
-	log.Printf("Informações de licença salvas com sucesso para dispositivo %s (token: %s...)",
-		deviceUUID, token[:min(8, len(token))])
-	return nil
-}
-
-// min retorna o menor valor entre dois inteiros
-func min(a, b int) int {
-	if a < b {
-		return a
+	for _, legacy := range legacyPaths {
+		if legacy == path {
+			continue
+		}
+		if _, err := os.Stat(legacy); err != nil {
+			continue
+		}
+		if err := copyFile(legacy, path); err != nil {
+			return fmt.Errorf("erro ao copiar banco de dados de %s para %s: %v", legacy, path, err)
+		}
+		logger.Warn("Banco de dados copiado do local antigo", "from", legacy, "to", path)
+		return nil
 	}
-	return b
+	return nil
 }
 
-// UpdateLastCheck atualiza o timestamp da última verificação
-func UpdateLastCheck() error {
-	query := `
-		UPDATE license_info
-		SET last_check = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
-		WHERE id = (SELECT MAX(id) FROM license_info)
-	`
-
-	result, err := db.Exec(query)
+// copyFile copia src para dst (que não pode existir), removendo dst em caso de falha
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar última verificação: %v", err)
+		return err
 	}
+	defer in.Close()
 
-	rowsAffected, err := result.RowsAffected()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
 	if err != nil {
-		log.Printf("Aviso: não foi possível verificar linhas afetadas: %v", err)
-	} else if rowsAffected == 0 {
-		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
 	}
-
 	return nil
 }
 
-// UpdateActiveStatus atualiza o status ativo da licença
-func UpdateActiveStatus(isActive bool) error {
-	query := `
-		UPDATE license_info
-		SET is_active = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = (SELECT MAX(id) FROM license_info)
-	`
+// recordError contabiliza um erro do banco de dados nas métricas
+func recordError(operation string) {
+	metrics.DatabaseErrors.Inc(operation)
+}
 
-	result, err := db.Exec(query, isActive)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar status ativo: %v", err)
+// CloseDatabase encerra o gravador de logs (gravando as entradas pendentes) e fecha a
+// conexão com o banco de dados
+func CloseDatabase() error {
+	StopLogWriter()
+	if db != nil {
+		conn := db
+		db, dbPath = nil, ""
+		return conn.Close()
 	}
+	return nil
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Aviso: não foi possível verificar linhas afetadas: %v", err)
-	} else if rowsAffected == 0 {
-		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+// PingDatabase verifica se a conexão com o banco está disponível (usado pelos health checks)
+func PingDatabase() error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
 	}
 
-	return nil
-}
+	if err := db.Ping(); err != nil {
+		recordError("ping")
+		return fmt.Errorf("erro ao conectar com banco: %v", err)
+	}
 
-// ClearLicenseInfo remove todas as informações de licença
-func ClearLicenseInfo() error {
-	query := "DELETE FROM license_info"
-	_, err := db.Exec(query)
-	if err != nil {
-		return fmt.Errorf("erro ao limpar informações de licença: %v", err)
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM license_info").Scan(&count); err != nil {
+		recordError("ping")
+		return fmt.Errorf("erro ao consultar banco: %v", err)
 	}
 
-	log.Println("Informações de licença removidas")
 	return nil
 }
 
 // GetDatabaseStats retorna estatísticas do banco de dados
 func GetDatabaseStats() (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+	stats := map[string]interface{}{"path": dbPath}
+
+	var journalMode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err == nil {
+		stats["journal_mode"] = journalMode
+	}
+
+	if backupDirectory != "" {
+		if backups, err := ListBackups(backupDirectory); err == nil && len(backups) > 0 {
+			stats["last_backup"] = backups[0].CreatedAt
+			stats["backup_count"] = len(backups)
+		}
+	}
 
 	// Conta total de registros de licença
 	var licenseCount int
 	err := db.QueryRow("SELECT COUNT(*) FROM license_info").Scan(&licenseCount)
 	if err != nil {
+		recordError("stats")
 		return nil, fmt.Errorf("erro ao contar licenças: %v", err)
 	}
 	stats["license_count"] = licenseCount
@@ -263,6 +360,7 @@ func GetDatabaseStats() (map[string]interface{}, error) {
 	var activeCount int
 	err = db.QueryRow("SELECT COUNT(*) FROM license_info WHERE is_active = TRUE").Scan(&activeCount)
 	if err != nil {
+		recordError("stats")
 		return nil, fmt.Errorf("erro ao contar licenças ativas: %v", err)
 	}
 	stats["active_licenses"] = activeCount
@@ -279,41 +377,28 @@ func GetDatabaseStats() (map[string]interface{}, error) {
 	return stats, nil
 }
 
-// GetLicenseByToken recupera informações de licença pelo token
+// GetLicenseByToken recupera informações de licença pelo token.
+// Os tokens são cifrados com nonce aleatório, então a busca decifra cada registro.
 func GetLicenseByToken(token string) (*LicenseInfo, error) {
-	var info LicenseInfo
-	query := `
-		SELECT id, token, device_uuid, is_active, created_at, last_check
-		FROM license_info
-		WHERE token = ?
-		LIMIT 1
-	`
-
-	err := db.QueryRow(query, token).Scan(
-		&info.ID,
-		&info.Token,
-		&info.DeviceUUID,
-		&info.IsActive,
-		&info.CreatedAt,
-		&info.LastCheck,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-
+	licenses, err := GetAllLicenses()
 	if err != nil {
-		return nil, fmt.Errorf("erro ao recuperar licença por token: %v", err)
+		return nil, err
 	}
 
-	return &info, nil
+	for i := range licenses {
+		if subtle.ConstantTimeCompare([]byte(licenses[i].Token), []byte(token)) == 1 {
+			return &licenses[i], nil
+		}
+	}
+	return nil, nil
 }
 
 // GetLicenseByUUID recupera informações de licença pelo UUID do dispositivo
 func GetLicenseByUUID(deviceUUID string) (*LicenseInfo, error) {
 	var info LicenseInfo
+	var lastVerified sql.NullString
 	query := `
-		SELECT id, token, device_uuid, is_active, created_at, last_check
+		SELECT id, token, device_uuid, is_active, created_at, last_check, last_verified_at
 		FROM license_info
 		WHERE device_uuid = ?
 		LIMIT 1
@@ -326,6 +411,7 @@ func GetLicenseByUUID(deviceUUID string) (*LicenseInfo, error) {
 		&info.IsActive,
 		&info.CreatedAt,
 		&info.LastCheck,
+		&lastVerified,
 	)
 
 	if err == sql.ErrNoRows {
@@ -333,32 +419,27 @@ func GetLicenseByUUID(deviceUUID string) (*LicenseInfo, error) {
 	}
 
 	if err != nil {
+		recordError("get_license_by_uuid")
 		return nil, fmt.Errorf("erro ao recuperar licença por UUID: %v", err)
 	}
+	info.LastVerifiedAt = lastVerified.String
 
+	if info.Token, err = decryptToken(tokenSecrets, info.Token); err != nil {
+		return nil, err
+	}
 	return &info, nil
 }
 
 // ValidateToken verifica se um token existe e está ativo
 func ValidateToken(token string) (bool, error) {
-	var isActive bool
-	query := `
-		SELECT is_active
-		FROM license_info
-		WHERE token = ?
-		LIMIT 1
-	`
-
-	err := db.QueryRow(query, token).Scan(&isActive)
-	if err == sql.ErrNoRows {
-		return false, nil // Token não encontrado
-	}
-
+	info, err := GetLicenseByToken(token)
 	if err != nil {
-		return false, fmt.Errorf("erro ao validar token: %v", err)
+		return false, err
 	}
-
-	return isActive, nil
+	if info == nil {
+		return false, nil // Token não encontrado
+	}
+	return info.IsActive, nil
 }
 
 // ValidateUUID verifica se um UUID existe e está ativo
@@ -377,6 +458,7 @@ func ValidateUUID(deviceUUID string) (bool, error) {
 	}
 
 	if err != nil {
+		recordError("validate_uuid")
 		return false, fmt.Errorf("erro ao validar UUID: %v", err)
 	}
 
@@ -386,13 +468,14 @@ func ValidateUUID(deviceUUID string) (bool, error) {
 // GetAllLicenses retorna todas as licenças (para administração)
 func GetAllLicenses() ([]LicenseInfo, error) {
 	query := `
-		SELECT id, token, device_uuid, is_active, created_at, last_check
+		SELECT id, token, device_uuid, is_active, created_at, last_check, last_verified_at
 		FROM license_info
 		ORDER BY created_at DESC
 	`
 
 	rows, err := db.Query(query)
 	if err != nil {
+		recordError("list_licenses")
 		return nil, fmt.Errorf("erro ao recuperar todas as licenças: %v", err)
 	}
 	defer rows.Close()
@@ -400,6 +483,7 @@ func GetAllLicenses() ([]LicenseInfo, error) {
 	var licenses []LicenseInfo
 	for rows.Next() {
 		var info LicenseInfo
+		var lastVerified sql.NullString
 		err := rows.Scan(
 			&info.ID,
 			&info.Token,
@@ -407,14 +491,21 @@ func GetAllLicenses() ([]LicenseInfo, error) {
 			&info.IsActive,
 			&info.CreatedAt,
 			&info.LastCheck,
+			&lastVerified,
 		)
 		if err != nil {
+			recordError("list_licenses")
 			return nil, fmt.Errorf("erro ao escanear linha de licença: %v", err)
 		}
+		info.LastVerifiedAt = lastVerified.String
+		if info.Token, err = decryptToken(tokenSecrets, info.Token); err != nil {
+			return nil, err
+		}
 		licenses = append(licenses, info)
 	}
 
 	if err = rows.Err(); err != nil {
+		recordError("list_licenses")
 		return nil, fmt.Errorf("erro ao iterar sobre licenças: %v", err)
 	}
 
@@ -429,6 +520,7 @@ func TestDatabaseConnection() error {
 
 	// Testa a conexão
 	if err := db.Ping(); err != nil {
+		recordError("test_connection")
 		return fmt.Errorf("erro ao conectar com banco: %v", err)
 	}
 
@@ -436,10 +528,11 @@ func TestDatabaseConnection() error {
 	var count int
 	err := db.QueryRow("SELECT COUNT(*) FROM license_info").Scan(&count)
 	if err != nil {
+		recordError("test_connection")
 		return fmt.Errorf("erro ao executar query de teste: %v", err)
 	}
 
-	log.Printf("Banco de dados funcionando corretamente. Total de licenças: %d", count)
+	logger.Info("Banco de dados funcionando corretamente", "licenses", count)
 	return nil
 }
 
@@ -455,17 +548,16 @@ func CreateTestLicense() error {
 	}
 
 	if existing != nil {
-		log.Println("Licença de teste já existe")
+		logger.Info("Licença de teste já existe")
 		return nil
 	}
 
 	// Cria a licença de teste
-	err = SaveLicenseInfo(testToken, testUUID)
+	err = Licenses().Save(testToken, testUUID)
 	if err != nil {
 		return fmt.Errorf("erro ao criar licença de teste: %v", err)
 	}
 
-	log.Printf("Licença de teste criada com sucesso (Token: %s..., UUID: %s)",
-		testToken[:16], testUUID)
+	logger.Info("Licença de teste criada com sucesso", "device_uuid", testUUID)
 	return nil
 }
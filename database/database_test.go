@@ -0,0 +1,26 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go-desktop-app/secrets"
+)
+
+// openTestDatabase abre um banco migrado em um diretório temporário, fechado ao fim do teste.
+// Retorna as opções usadas, para reabrir o mesmo banco.
+func openTestDatabase(t *testing.T) Options {
+	t.Helper()
+	dir := t.TempDir()
+	options := Options{
+		Path:            filepath.Join(dir, "license.db"),
+		IntegrityCheck:  IntegrityOff,
+		Secrets:         secrets.NewMachineStore(filepath.Join(dir, "secret.key")),
+		BackupDirectory: filepath.Join(dir, "backups"),
+	}
+	if err := InitDatabase(options); err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+	t.Cleanup(func() { CloseDatabase() })
+	return options
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"go-desktop-app/secrets"
+)
+
+// encryptToken cifra o token para gravação em license_info
+func encryptToken(secretStore secrets.SecretStore, token string) (string, error) {
+	if secretStore == nil {
+		return "", fmt.Errorf("cofre de segredos não configurado para cifrar o token")
+	}
+	encrypted, err := secretStore.Encrypt(token)
+	if err != nil {
+		recordError("encrypt_token")
+		return "", fmt.Errorf("erro ao cifrar token: %v", err)
+	}
+	return encrypted, nil
+}
+
+// decryptToken decifra o token lido de license_info. Um token em texto puro
+// (banco ainda não migrado para a versão 5) é devolvido como está.
+func decryptToken(secretStore secrets.SecretStore, token string) (string, error) {
+	if !secrets.IsEncrypted(token) {
+		return token, nil
+	}
+	if secretStore == nil {
+		return "", fmt.Errorf("cofre de segredos não configurado para decifrar o token")
+	}
+	plaintext, err := secretStore.Decrypt(token)
+	if err != nil {
+		recordError("decrypt_token")
+		return "", fmt.Errorf("erro ao decifrar token: %w", err)
+	}
+	return plaintext, nil
+}
+
+// encryptLicenseTokens cifra os tokens em texto puro de license_info (migração 5)
+func encryptLicenseTokens(tx *sql.Tx) error {
+	return rewriteLicenseTokens(tx, func(token string) (string, error) {
+		if secrets.IsEncrypted(token) {
+			return token, nil
+		}
+		return encryptToken(tokenSecrets, token)
+	})
+}
+
+// decryptLicenseTokens volta os tokens de license_info para texto puro (reversão da migração 5)
+func decryptLicenseTokens(tx *sql.Tx) error {
+	return rewriteLicenseTokens(tx, func(token string) (string, error) {
+		return decryptToken(tokenSecrets, token)
+	})
+}
+
+// rewriteLicenseTokens aplica convert ao token de cada registro de license_info
+func rewriteLicenseTokens(tx *sql.Tx, convert func(string) (string, error)) error {
+	rows, err := tx.Query("SELECT id, token FROM license_info")
+	if err != nil {
+		return fmt.Errorf("erro ao ler tokens: %v", err)
+	}
+	tokens := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var token string
+		if err := rows.Scan(&id, &token); err != nil {
+			rows.Close()
+			return fmt.Errorf("erro ao ler tokens: %v", err)
+		}
+		tokens[id] = token
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao ler tokens: %v", err)
+	}
+
+	for id, token := range tokens {
+		converted, err := convert(token)
+		if err != nil {
+			return fmt.Errorf("licença %d: %w", id, err)
+		}
+		if converted == token {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE license_info SET token = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", converted, id); err != nil {
+			return fmt.Errorf("erro ao gravar token da licença %d: %v", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-desktop-app/secrets"
+)
+
+func TestTokenEncryptionRoundTrip(t *testing.T) {
+	store := secrets.NewMachineStore(filepath.Join(t.TempDir(), "secret.key"))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"token simples", "abc123"},
+		{"token com acentos", "licença-ção-ü"},
+		{"token longo", strings.Repeat("x", 4096)},
+		{"token vazio", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := encryptToken(store, tt.token)
+			if err != nil {
+				t.Fatalf("encryptToken: %v", err)
+			}
+			if !secrets.IsEncrypted(encrypted) {
+				t.Fatalf("encryptToken = %q, sem o prefixo de valor cifrado", encrypted)
+			}
+			if tt.token != "" && strings.Contains(encrypted, tt.token) {
+				t.Fatalf("valor cifrado contém o token em texto puro")
+			}
+			again, err := encryptToken(store, tt.token)
+			if err != nil || again == encrypted {
+				t.Errorf("duas cifragens do mesmo token deveriam diferir (nonce aleatório): %v", err)
+			}
+
+			decrypted, err := decryptToken(store, encrypted)
+			if err != nil {
+				t.Fatalf("decryptToken: %v", err)
+			}
+			if decrypted != tt.token {
+				t.Errorf("decryptToken = %q, esperado %q", decrypted, tt.token)
+			}
+		})
+	}
+}
+
+func TestDecryptTokenRejects(t *testing.T) {
+	dir := t.TempDir()
+	store := secrets.NewMachineStore(filepath.Join(dir, "secret.key"))
+	other := secrets.NewMachineStore(filepath.Join(dir, "other.key"))
+	encrypted, err := encryptToken(store, "token-secreto")
+	if err != nil {
+		t.Fatalf("encryptToken: %v", err)
+	}
+	// Troca um caractere do conteúdo cifrado (depois do prefixo)
+	tampered := []byte(encrypted)
+	tampered[len(tampered)-3] ^= 0x01
+
+	tests := []struct {
+		name    string
+		store   secrets.SecretStore
+		value   string
+		want    string
+		wantErr error
+	}{
+		{"texto puro é devolvido como está", store, "token-antigo", "token-antigo", nil},
+		{"chave de outra máquina", other, encrypted, "", secrets.ErrDecrypt},
+		{"valor alterado", store, string(tampered), "", secrets.ErrDecrypt},
+		{"base64 inválido", store, "enc:v1:%%%", "", secrets.ErrDecrypt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decryptToken(tt.store, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decryptToken erro = %v, esperado %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decryptToken = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := decryptToken(nil, encrypted); err == nil {
+		t.Error("decryptToken sem cofre de segredos deveria falhar")
+	}
+	if _, err := encryptToken(nil, "token"); err == nil {
+		t.Error("encryptToken sem cofre de segredos deveria falhar")
+	}
+}
+
+func TestLicenseInfoJSONOmitsToken(t *testing.T) {
+	data, err := json.Marshal(LicenseInfo{ID: 1, Token: "token-secreto", DeviceUUID: "device-1"})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if strings.Contains(string(data), "token-secreto") || strings.Contains(string(data), `"token"`) {
+		t.Errorf("LicenseInfo serializado contém o token: %s", data)
+	}
+}
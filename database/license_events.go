@@ -0,0 +1,141 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tipos de evento do histórico da licença
+const (
+	LicenseEventSetup       = "setup"       // configuração de um token (bem-sucedida ou não)
+	LicenseEventVerify      = "verify"      // verificação na API de licenças
+	LicenseEventActivated   = "activated"   // a licença passou a ativa
+	LicenseEventDeactivated = "deactivated" // a licença passou a inativa
+	LicenseEventCleared     = "cleared"     // as informações de licença foram removidas
+	LicenseEventImported    = "imported"    // importação de um arquivo de licença offline (aceito ou não)
+)
+
+// Resultados de configuração e verificação registrados no histórico
+const (
+	LicenseResultValid   = "valid"   // token aceito pelo servidor
+	LicenseResultInvalid = "invalid" // token recusado pelo servidor
+	LicenseResultError   = "error"   // servidor inacessível ou resposta ilegível
+)
+
+// LicenseEvent é uma entrada do histórico da licença
+type LicenseEvent struct {
+	ID         int64     `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Type       string    `json:"type"`
+	DeviceUUID string    `json:"device_uuid,omitempty"`
+	Result     string    `json:"result,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	HTTPStatus int       `json:"http_status,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+}
+
+// LicenseEventFilter define os filtros da consulta do histórico da licença
+type LicenseEventFilter struct {
+	From   time.Time
+	To     time.Time
+	Type   string
+	Limit  int
+	Offset int
+}
+
+// IsLicenseEventType informa se o tipo é um dos tipos de evento do histórico
+func IsLicenseEventType(eventType string) bool {
+	switch eventType {
+	case LicenseEventSetup, LicenseEventVerify, LicenseEventActivated, LicenseEventDeactivated, LicenseEventCleared,
+		LicenseEventImported:
+		return true
+	}
+	return false
+}
+
+// RecordEvent grava uma entrada no histórico da licença (Timestamp vazio usa o horário atual)
+func (s *SQLiteLicenseStore) RecordEvent(event LicenseEvent) error {
+	if err := s.checkDB(); err != nil {
+		return err
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO license_events
+			(timestamp_ms, type, device_uuid, result, message, http_status, error, request_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.Timestamp.UnixMilli(), event.Type, event.DeviceUUID, event.Result, event.Message,
+		event.HTTPStatus, event.Error, event.RequestID)
+	if err != nil {
+		recordError("record_license_event")
+		return fmt.Errorf("erro ao gravar histórico da licença: %v", err)
+	}
+	return nil
+}
+
+// History retorna as entradas do histórico que atendem aos filtros, da mais recente para
+// a mais antiga, junto com o total de entradas filtradas
+func (s *SQLiteLicenseStore) History(filter LicenseEventFilter) ([]LicenseEvent, int, error) {
+	if err := s.checkDB(); err != nil {
+		return nil, 0, err
+	}
+
+	var conditions []string
+	var args []interface{}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "timestamp_ms >= ?")
+		args = append(args, filter.From.UnixMilli())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "timestamp_ms <= ?")
+		args = append(args, filter.To.UnixMilli())
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM license_events "+where, args...).Scan(&total); err != nil {
+		recordError("license_history")
+		return nil, 0, fmt.Errorf("erro ao contar histórico da licença: %v", err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, timestamp_ms, type, device_uuid, result, message, http_status, error, request_id
+		FROM license_events `+where+`
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		recordError("license_history")
+		return nil, 0, fmt.Errorf("erro ao consultar histórico da licença: %v", err)
+	}
+	defer rows.Close()
+
+	history := []LicenseEvent{}
+	for rows.Next() {
+		var event LicenseEvent
+		var timestampMs int64
+		err := rows.Scan(&event.ID, &timestampMs, &event.Type, &event.DeviceUUID, &event.Result,
+			&event.Message, &event.HTTPStatus, &event.Error, &event.RequestID)
+		if err != nil {
+			recordError("license_history")
+			return nil, 0, fmt.Errorf("erro ao ler histórico da licença: %v", err)
+		}
+		event.Timestamp = time.UnixMilli(timestampMs)
+		history = append(history, event)
+	}
+	if err := rows.Err(); err != nil {
+		recordError("license_history")
+		return nil, 0, fmt.Errorf("erro ao iterar histórico da licença: %v", err)
+	}
+	return history, total, nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLicenseHistoryFilters(t *testing.T) {
+	openTestDatabase(t)
+	stores := map[string]LicenseStore{
+		"sqlite":  Licenses(),
+		"memória": NewMemoryLicenseStore(),
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	types := []string{LicenseEventSetup, LicenseEventVerify, LicenseEventVerify, LicenseEventDeactivated, LicenseEventVerify}
+	for _, store := range stores {
+		for i, eventType := range types {
+			event := LicenseEvent{Timestamp: base.Add(time.Duration(i) * time.Hour), Type: eventType, Message: fmt.Sprint(i)}
+			if err := store.RecordEvent(event); err != nil {
+				t.Fatalf("RecordEvent: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		filter   LicenseEventFilter
+		expected string // mensagens na ordem retornada
+		total    int
+	}{
+		{"sem filtros, mais recente primeiro", LicenseEventFilter{Limit: 10}, "[4 3 2 1 0]", 5},
+		{"por tipo", LicenseEventFilter{Type: LicenseEventVerify, Limit: 10}, "[4 2 1]", 3},
+		{"desde", LicenseEventFilter{From: base.Add(3 * time.Hour), Limit: 10}, "[4 3]", 2},
+		{"até", LicenseEventFilter{To: base.Add(time.Hour), Limit: 10}, "[1 0]", 2},
+		{"intervalo e tipo", LicenseEventFilter{From: base.Add(time.Hour), To: base.Add(3 * time.Hour), Type: LicenseEventVerify, Limit: 10}, "[2 1]", 2},
+		{"paginação", LicenseEventFilter{Limit: 2, Offset: 1}, "[3 2]", 5},
+		{"offset além do total", LicenseEventFilter{Limit: 2, Offset: 5}, "[]", 5},
+		{"tipo sem entradas", LicenseEventFilter{Type: LicenseEventImported, Limit: 10}, "[]", 0},
+	}
+
+	for storeName, store := range stores {
+		for _, tt := range tests {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				events, total, err := store.History(tt.filter)
+				if err != nil {
+					t.Fatalf("History: %v", err)
+				}
+				var messages []string
+				for _, event := range events {
+					messages = append(messages, event.Message)
+				}
+				if got := fmt.Sprint(messages); got != tt.expected || total != tt.total {
+					t.Errorf("History = %s (total %d), esperado %s (total %d)", got, total, tt.expected, tt.total)
+				}
+			})
+		}
+	}
+}
+
+func TestLicenseHistorySurvivesClear(t *testing.T) {
+	openTestDatabase(t)
+	store := Licenses()
+	if err := store.RecordEvent(LicenseEvent{Type: LicenseEventSetup}); err != nil {
+		t.Fatalf("RecordEvent: %v", err)
+	}
+	if err := store.Save("token", "device-1"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+
+	if _, total, err := store.History(LicenseEventFilter{Limit: 10}); err != nil || total != 1 {
+		t.Errorf("History após Clear = %d, %v; esperado a entrada mantida", total, err)
+	}
+}
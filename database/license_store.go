@@ -0,0 +1,300 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"go-desktop-app/secrets"
+)
+
+// LicenseStore persiste as informações de licença da máquina.
+// O pacote license e os handlers da API recebem o store por injeção, o que permite
+// usar o SQLite em produção e MemoryLicenseStore nos testes.
+type LicenseStore interface {
+	// HasLicense verifica se há uma licença armazenada
+	HasLicense() (bool, error)
+	// Get retorna a licença mais recente (nil se não houver), com o token decifrado
+	Get() (*LicenseInfo, error)
+	// Save substitui a licença armazenada por uma nova, ativa
+	Save(token, deviceUUID string) error
+	// UpdateLastCheck registra o horário da última verificação
+	UpdateLastCheck() error
+	// MarkVerified registra uma resposta válida do servidor (última verificação e última validação)
+	MarkVerified() error
+	// UpdateActiveStatus atualiza o status ativo da licença
+	UpdateActiveStatus(isActive bool) error
+	// UpdateFingerprint grava a impressão digital da máquina junto da licença
+	UpdateFingerprint(fingerprint string) error
+	// Clear remove todas as informações de licença, inclusive a licença offline
+	Clear() error
+	// SaveOfflineLicense substitui a licença offline (arquivo assinado) armazenada
+	SaveOfflineLicense(record OfflineLicenseRecord) error
+	// GetOfflineLicense retorna a licença offline armazenada (nil se não houver)
+	GetOfflineLicense() (*OfflineLicenseRecord, error)
+	// RecordEvent grava uma entrada no histórico da licença, que sobrevive a Save e Clear
+	RecordEvent(event LicenseEvent) error
+	// History consulta o histórico da licença, da entrada mais recente para a mais antiga
+	History(filter LicenseEventFilter) ([]LicenseEvent, int, error)
+}
+
+// SQLiteLicenseStore implementa LicenseStore sobre a tabela license_info.
+// O token é gravado cifrado pelo SecretStore e decifrado em Get.
+type SQLiteLicenseStore struct {
+	db      *sql.DB
+	secrets secrets.SecretStore
+}
+
+var _ LicenseStore = (*SQLiteLicenseStore)(nil)
+
+// NewSQLiteLicenseStore cria um LicenseStore sobre a conexão e o cofre de segredos informados
+func NewSQLiteLicenseStore(db *sql.DB, secretStore secrets.SecretStore) *SQLiteLicenseStore {
+	return &SQLiteLicenseStore{db: db, secrets: secretStore}
+}
+
+// Licenses retorna o LicenseStore do banco aberto por InitDatabase, cifrando os tokens
+// com Options.Secrets. Sem banco aberto, os métodos do store retornam erro.
+func Licenses() LicenseStore {
+	return NewSQLiteLicenseStore(db, tokenSecrets)
+}
+
+// checkDB retorna erro quando o store foi criado sem conexão
+func (s *SQLiteLicenseStore) checkDB() error {
+	if s.db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+	return nil
+}
+
+// HasLicense verifica se há informações de licença armazenadas
+func (s *SQLiteLicenseStore) HasLicense() (bool, error) {
+	if err := s.checkDB(); err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM license_info").Scan(&count); err != nil {
+		recordError("has_license")
+		return false, fmt.Errorf("erro ao verificar licença: %v", err)
+	}
+	return count > 0, nil
+}
+
+// Get recupera as informações de licença
+func (s *SQLiteLicenseStore) Get() (*LicenseInfo, error) {
+	if err := s.checkDB(); err != nil {
+		return nil, err
+	}
+
+	var info LicenseInfo
+	var lastVerified sql.NullString
+	query := `
+		SELECT id, token, device_uuid, is_active, created_at, last_check, last_verified_at, fingerprint
+		FROM license_info
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	err := s.db.QueryRow(query).Scan(
+		&info.ID,
+		&info.Token,
+		&info.DeviceUUID,
+		&info.IsActive,
+		&info.CreatedAt,
+		&info.LastCheck,
+		&lastVerified,
+		&info.Fingerprint,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		recordError("get_license")
+		return nil, fmt.Errorf("erro ao recuperar informações de licença: %v", err)
+	}
+	info.LastVerifiedAt = lastVerified.String
+
+	if info.Token, err = decryptToken(s.secrets, info.Token); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Save salva as informações de licença
+func (s *SQLiteLicenseStore) Save(token, deviceUUID string) error {
+	if err := s.checkDB(); err != nil {
+		return err
+	}
+
+	// Valida os parâmetros
+	if token == "" {
+		return fmt.Errorf("token não pode estar vazio")
+	}
+	if deviceUUID == "" {
+		return fmt.Errorf("device UUID não pode estar vazio")
+	}
+
+	encrypted, err := encryptToken(s.secrets, token)
+	if err != nil {
+		return err
+	}
+
+	// Remove informações antigas (a licença offline é mantida)
+	if _, err := s.db.Exec("DELETE FROM license_info"); err != nil {
+		logger.Warn("Erro ao limpar licença antiga", "error", err)
+	}
+
+	// Insere nova informação com timestamp atual. A última validação pelo servidor fica
+	// vazia até MarkVerified: gravar a licença não prova que o servidor a aceitou.
+	query := `
+		INSERT INTO license_info (token, device_uuid, is_active, created_at, last_check, updated_at)
+		VALUES (?, ?, TRUE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`
+
+	result, err := s.db.Exec(query, encrypted, deviceUUID)
+	if err != nil {
+		recordError("save_license")
+		return fmt.Errorf("erro ao salvar informações de licença: %v", err)
+	}
+
+	// Verifica se a inserção foi bem-sucedida
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Warn("Não foi possível verificar linhas afetadas", "error", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("nenhuma linha foi inserida")
+	}
+
+	logger.Info("Informações de licença salvas com sucesso", "device_uuid", deviceUUID)
+	return nil
+}
+
+// UpdateLastCheck atualiza o timestamp da última verificação
+func (s *SQLiteLicenseStore) UpdateLastCheck() error {
+	if err := s.checkDB(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE license_info
+		SET last_check = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT MAX(id) FROM license_info)
+	`
+
+	result, err := s.db.Exec(query)
+	if err != nil {
+		recordError("update_last_check")
+		return fmt.Errorf("erro ao atualizar última verificação: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Warn("Não foi possível verificar linhas afetadas", "error", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+	}
+
+	return nil
+}
+
+// MarkVerified atualiza a última verificação e a última validação pelo servidor
+func (s *SQLiteLicenseStore) MarkVerified() error {
+	if err := s.checkDB(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE license_info
+		SET last_check = CURRENT_TIMESTAMP, last_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT MAX(id) FROM license_info)
+	`
+
+	result, err := s.db.Exec(query)
+	if err != nil {
+		recordError("mark_verified")
+		return fmt.Errorf("erro ao atualizar última validação: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Warn("Não foi possível verificar linhas afetadas", "error", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+	}
+
+	return nil
+}
+
+// UpdateFingerprint grava a impressão digital da máquina na licença atual
+func (s *SQLiteLicenseStore) UpdateFingerprint(fingerprint string) error {
+	if err := s.checkDB(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE license_info
+		SET fingerprint = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT MAX(id) FROM license_info)
+	`
+
+	result, err := s.db.Exec(query, fingerprint)
+	if err != nil {
+		recordError("update_fingerprint")
+		return fmt.Errorf("erro ao atualizar impressão digital: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Warn("Não foi possível verificar linhas afetadas", "error", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+	}
+
+	return nil
+}
+
+// UpdateActiveStatus atualiza o status ativo da licença
+func (s *SQLiteLicenseStore) UpdateActiveStatus(isActive bool) error {
+	if err := s.checkDB(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE license_info
+		SET is_active = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT MAX(id) FROM license_info)
+	`
+
+	result, err := s.db.Exec(query, isActive)
+	if err != nil {
+		recordError("update_active")
+		return fmt.Errorf("erro ao atualizar status ativo: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Warn("Não foi possível verificar linhas afetadas", "error", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+	}
+
+	return nil
+}
+
+// Clear remove todas as informações de licença, inclusive a licença offline
+func (s *SQLiteLicenseStore) Clear() error {
+	if err := s.checkDB(); err != nil {
+		return err
+	}
+
+	for _, table := range []string{"license_info", "offline_license"} {
+		if _, err := s.db.Exec("DELETE FROM " + table); err != nil {
+			recordError("clear_license")
+			return fmt.Errorf("erro ao limpar informações de licença: %v", err)
+		}
+	}
+
+	logger.Info("Informações de licença removidas")
+	return nil
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryLicenseStore implementa LicenseStore em memória, para testes e ferramentas
+// que não devem tocar no banco da máquina
+type MemoryLicenseStore struct {
+	mutex   sync.Mutex
+	info    *LicenseInfo
+	offline *OfflineLicenseRecord
+	nextID  int
+	history []LicenseEvent
+}
+
+var _ LicenseStore = (*MemoryLicenseStore)(nil)
+
+// NewMemoryLicenseStore cria um LicenseStore em memória vazio
+func NewMemoryLicenseStore() *MemoryLicenseStore {
+	return &MemoryLicenseStore{nextID: 1}
+}
+
+// memoryTimestamp formata o horário atual como o driver do SQLite devolve as colunas DATETIME
+func memoryTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// HasLicense verifica se há uma licença armazenada
+func (s *MemoryLicenseStore) HasLicense() (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.info != nil, nil
+}
+
+// Get retorna uma cópia da licença armazenada (nil se não houver)
+func (s *MemoryLicenseStore) Get() (*LicenseInfo, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.info == nil {
+		return nil, nil
+	}
+	info := *s.info
+	return &info, nil
+}
+
+// Save substitui a licença armazenada por uma nova, ativa
+func (s *MemoryLicenseStore) Save(token, deviceUUID string) error {
+	if token == "" {
+		return fmt.Errorf("token não pode estar vazio")
+	}
+	if deviceUUID == "" {
+		return fmt.Errorf("device UUID não pode estar vazio")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := memoryTimestamp()
+	s.info = &LicenseInfo{
+		ID:         s.nextID,
+		Token:      token,
+		DeviceUUID: deviceUUID,
+		IsActive:   true,
+		CreatedAt:  now,
+		LastCheck:  now,
+	}
+	s.nextID++
+	return nil
+}
+
+// UpdateLastCheck registra o horário da última verificação
+func (s *MemoryLicenseStore) UpdateLastCheck() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.info == nil {
+		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+	}
+	s.info.LastCheck = memoryTimestamp()
+	return nil
+}
+
+// MarkVerified registra uma resposta válida do servidor
+func (s *MemoryLicenseStore) MarkVerified() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.info == nil {
+		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+	}
+	now := memoryTimestamp()
+	s.info.LastCheck = now
+	s.info.LastVerifiedAt = now
+	return nil
+}
+
+// UpdateFingerprint grava a impressão digital da máquina na licença armazenada
+func (s *MemoryLicenseStore) UpdateFingerprint(fingerprint string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.info == nil {
+		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+	}
+	s.info.Fingerprint = fingerprint
+	return nil
+}
+
+// UpdateActiveStatus atualiza o status ativo da licença
+func (s *MemoryLicenseStore) UpdateActiveStatus(isActive bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.info == nil {
+		return fmt.Errorf("nenhuma licença encontrada para atualizar")
+	}
+	s.info.IsActive = isActive
+	return nil
+}
+
+// Clear remove a licença armazenada
+func (s *MemoryLicenseStore) Clear() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.info = nil
+	s.offline = nil
+	return nil
+}
+
+// SaveOfflineLicense substitui a licença offline armazenada
+func (s *MemoryLicenseStore) SaveOfflineLicense(record OfflineLicenseRecord) error {
+	if record.DeviceUUID == "" {
+		return fmt.Errorf("device UUID não pode estar vazio")
+	}
+	if record.Content == "" {
+		return fmt.Errorf("conteúdo da licença offline não pode estar vazio")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	record.ImportedAt = memoryTimestamp()
+	s.offline = &record
+	return nil
+}
+
+// GetOfflineLicense retorna uma cópia da licença offline armazenada (nil se não houver)
+func (s *MemoryLicenseStore) GetOfflineLicense() (*OfflineLicenseRecord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.offline == nil {
+		return nil, nil
+	}
+	record := *s.offline
+	return &record, nil
+}
+
+// RecordEvent guarda uma entrada no histórico da licença
+func (s *MemoryLicenseStore) RecordEvent(event LicenseEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	event.ID = int64(len(s.history) + 1)
+	s.history = append(s.history, event)
+	return nil
+}
+
+// History retorna as entradas do histórico que atendem aos filtros, da mais recente para a mais antiga
+func (s *MemoryLicenseStore) History(filter LicenseEventFilter) ([]LicenseEvent, int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	matched := []LicenseEvent{}
+	for i := len(s.history) - 1; i >= 0; i-- {
+		event := s.history[i]
+		if (!filter.From.IsZero() && event.Timestamp.Before(filter.From)) ||
+			(!filter.To.IsZero() && event.Timestamp.After(filter.To)) ||
+			(filter.Type != "" && event.Type != filter.Type) {
+			continue
+		}
+		matched = append(matched, event)
+	}
+
+	total := len(matched)
+	if filter.Offset >= total {
+		return []LicenseEvent{}, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
@@ -0,0 +1,64 @@
+package database
+
+import "errors"
+
+// ErrLicenseStoreNotConfigured indica que o LicenseStore não foi injetado antes do uso
+var ErrLicenseStoreNotConfigured = errors.New("store de licença não configurado")
+
+// UnconfiguredLicenseStore é o store padrão de quem recebe o LicenseStore por injeção.
+// Todas as operações falham com ErrLicenseStoreNotConfigured, para que um SetLicenseStore
+// esquecido apareça no primeiro uso em vez de se passar por banco não inicializado.
+type UnconfiguredLicenseStore struct {
+	// Owner identifica quem deveria ter recebido o store (ex.: "api"), para a mensagem de erro
+	Owner string
+}
+
+var _ LicenseStore = UnconfiguredLicenseStore{}
+
+// err registra e retorna o erro de store não configurado
+func (s UnconfiguredLicenseStore) err() error {
+	logger.Error("LicenseStore usado antes de SetLicenseStore", "owner", s.Owner)
+	return ErrLicenseStoreNotConfigured
+}
+
+// HasLicense falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) HasLicense() (bool, error) { return false, s.err() }
+
+// Get falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) Get() (*LicenseInfo, error) { return nil, s.err() }
+
+// Save falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) Save(token, deviceUUID string) error { return s.err() }
+
+// UpdateLastCheck falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) UpdateLastCheck() error { return s.err() }
+
+// MarkVerified falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) MarkVerified() error { return s.err() }
+
+// UpdateActiveStatus falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) UpdateActiveStatus(isActive bool) error { return s.err() }
+
+// UpdateFingerprint falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) UpdateFingerprint(fingerprint string) error { return s.err() }
+
+// Clear falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) Clear() error { return s.err() }
+
+// SaveOfflineLicense falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) SaveOfflineLicense(record OfflineLicenseRecord) error {
+	return s.err()
+}
+
+// GetOfflineLicense falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) GetOfflineLicense() (*OfflineLicenseRecord, error) {
+	return nil, s.err()
+}
+
+// RecordEvent falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) RecordEvent(event LicenseEvent) error { return s.err() }
+
+// History falha com ErrLicenseStoreNotConfigured
+func (s UnconfiguredLicenseStore) History(filter LicenseEventFilter) ([]LicenseEvent, int, error) {
+	return nil, 0, s.err()
+}
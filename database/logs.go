@@ -0,0 +1,401 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-desktop-app/metrics"
+)
+
+// Parâmetros do gravador de logs em lote
+const (
+	logQueueSize     = 5000
+	logBatchSize     = 200
+	logFlushInterval = 500 * time.Millisecond
+	logPruneInterval = time.Hour
+)
+
+// LogRecord representa uma entrada de log persistida
+type LogRecord struct {
+	ID        int64     `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Content   string    `json:"content"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// LogFilter define os filtros da consulta de logs
+type LogFilter struct {
+	From      time.Time
+	To        time.Time
+	Types     []string
+	Search    string
+	RequestID string
+	Limit     int
+	Offset    int
+}
+
+var (
+	// logQueue, logStop e logWriterDone são do gravador em execução (nil sem gravador)
+	logQueue       chan LogRecord
+	logStop        chan struct{}
+	logWriterDone  chan struct{}
+	logWriterMutex sync.RWMutex
+	flushRequests  = make(chan chan struct{})
+)
+
+// StartLogWriter inicia a gravação assíncrona de logs em lote.
+// Entradas com mais de retention são removidas periodicamente (0 desativa a limpeza).
+// Sem efeito se o gravador já estiver em execução.
+func StartLogWriter(retention time.Duration) {
+	logWriterMutex.Lock()
+	defer logWriterMutex.Unlock()
+	if logQueue != nil {
+		return
+	}
+
+	logQueue = make(chan LogRecord, logQueueSize)
+	logStop = make(chan struct{})
+	logWriterDone = make(chan struct{})
+	go runLogWriter(logQueue, logStop, logWriterDone, retention)
+}
+
+// StopLogWriter grava as entradas pendentes e encerra o gravador de logs; as entradas
+// registradas depois disso não são persistidas. CloseDatabase o chama antes de fechar a
+// conexão, para que o gravador nunca use um banco fechado.
+func StopLogWriter() {
+	logWriterMutex.Lock()
+	stop, done := logStop, logWriterDone
+	logQueue, logStop, logWriterDone = nil, nil, nil
+	logWriterMutex.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+// EnqueueLog agenda a gravação de uma entrada sem bloquear quem registrou o log.
+// Se a fila estiver cheia a entrada é descartada e contabilizada nas métricas.
+func EnqueueLog(record LogRecord) {
+	logWriterMutex.RLock()
+	queue := logQueue
+	logWriterMutex.RUnlock()
+	if queue == nil {
+		return
+	}
+
+	select {
+	case queue <- record:
+	default:
+		metrics.DatabaseErrors.Inc("log_queue_full")
+	}
+}
+
+// FlushLogs força a gravação das entradas pendentes e aguarda a conclusão
+func FlushLogs() {
+	logWriterMutex.RLock()
+	writerDone := logWriterDone
+	logWriterMutex.RUnlock()
+	if writerDone == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	select {
+	case flushRequests <- done:
+		<-done
+	case <-writerDone:
+	}
+}
+
+// runLogWriter agrupa as entradas da fila e grava em transações até stop ser fechado
+func runLogWriter(queue chan LogRecord, stop, writerDone chan struct{}, retention time.Duration) {
+	defer close(writerDone)
+
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+
+	batch := make([]LogRecord, 0, logBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// A falha já é contabilizada nas métricas; registrá-la no logger central
+		// geraria novas entradas para esta mesma fila
+		insertLogBatch(batch)
+		batch = batch[:0]
+	}
+	// drain move para o lote o que já está na fila
+	drain := func() {
+		for {
+			select {
+			case record := <-queue:
+				batch = append(batch, record)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case record := <-queue:
+			batch = append(batch, record)
+			if len(batch) >= logBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+			if retention > 0 && time.Since(lastPrune) >= logPruneInterval {
+				lastPrune = time.Now()
+				pruneLogs(retention)
+			}
+		case done := <-flushRequests:
+			// Esvazia o que já está na fila antes de confirmar
+			drain()
+			flush()
+			close(done)
+		case <-stop:
+			drain()
+			flush()
+			return
+		}
+	}
+}
+
+// insertLogBatch grava um lote de entradas em uma única transação
+func insertLogBatch(batch []LogRecord) error {
+	if db == nil {
+		recordError("insert_logs")
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		recordError("insert_logs")
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO log_entries (timestamp_ms, type, content, request_id) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		recordError("insert_logs")
+		return err
+	}
+	defer stmt.Close()
+
+	for _, record := range batch {
+		if _, err := stmt.Exec(record.Timestamp.UnixMilli(), record.Type, record.Content, record.RequestID); err != nil {
+			tx.Rollback()
+			recordError("insert_logs")
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		recordError("insert_logs")
+		return err
+	}
+	return nil
+}
+
+// pruneLogs remove entradas mais antigas que a retenção
+func pruneLogs(retention time.Duration) {
+	if db == nil {
+		return
+	}
+	cutoff := time.Now().Add(-retention).UnixMilli()
+	result, err := db.Exec("DELETE FROM log_entries WHERE timestamp_ms < ?", cutoff)
+	if err != nil {
+		recordError("prune_logs")
+		logger.Error("Erro ao remover logs antigos", "error", err)
+		return
+	}
+
+	if removed, err := result.RowsAffected(); err == nil && removed > 0 {
+		logger.Info("Entradas de log antigas removidas do banco", "removed", removed)
+	}
+}
+
+// QueryLogs consulta os logs persistidos.
+// Retorna as entradas em ordem cronológica e o total que atende aos filtros.
+// Sem offset, a página contém as entradas mais recentes.
+func QueryLogs(filter LogFilter) ([]LogRecord, int, error) {
+	if db == nil {
+		return nil, 0, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	where, args := logConditions(filter)
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM log_entries "+where, args...).Scan(&total); err != nil {
+		recordError("query_logs")
+		return nil, 0, fmt.Errorf("erro ao contar logs: %v", err)
+	}
+
+	// Busca do mais recente para o mais antigo e inverte para ordem cronológica
+	query := fmt.Sprintf(`
+		SELECT id, timestamp_ms, type, content, request_id
+		FROM log_entries %s
+		ORDER BY timestamp_ms DESC, id DESC
+		LIMIT ? OFFSET ?`, where)
+	rows, err := db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		recordError("query_logs")
+		return nil, 0, fmt.Errorf("erro ao consultar logs: %v", err)
+	}
+	defer rows.Close()
+
+	var records []LogRecord
+	for rows.Next() {
+		var record LogRecord
+		var timestampMs int64
+		if err := rows.Scan(&record.ID, &timestampMs, &record.Type, &record.Content, &record.RequestID); err != nil {
+			recordError("query_logs")
+			return nil, 0, fmt.Errorf("erro ao ler log: %v", err)
+		}
+		record.Timestamp = time.UnixMilli(timestampMs)
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		recordError("query_logs")
+		return nil, 0, fmt.Errorf("erro ao iterar logs: %v", err)
+	}
+
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+
+	return records, total, nil
+}
+
+// exportPageSize é o tamanho das páginas lidas por ExportLogs
+const exportPageSize = 1000
+
+// ExportLogs percorre em ordem cronológica todas as entradas que atendem aos filtros.
+// Limit e Offset são ignorados; a exportação cobre o intervalo inteiro.
+// A leitura é feita em páginas para não manter o banco bloqueado enquanto o cliente recebe os dados.
+func ExportLogs(filter LogFilter, fn func(LogRecord) error) error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+	// Inclui as entradas ainda na fila de gravação
+	FlushLogs()
+
+	where, args := logConditions(filter)
+	if where == "" {
+		where = "WHERE (timestamp_ms, id) > (?, ?)"
+	} else {
+		where += " AND (timestamp_ms, id) > (?, ?)"
+	}
+	query := `
+		SELECT id, timestamp_ms, type, content, request_id
+		FROM log_entries ` + where + `
+		ORDER BY timestamp_ms, id
+		LIMIT ?`
+
+	var lastTimestamp, lastID int64 = -1 << 62, 0
+	for {
+		page, err := queryLogPage(query, append(args, lastTimestamp, lastID, exportPageSize)...)
+		if err != nil {
+			return err
+		}
+		for _, record := range page {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if len(page) < exportPageSize {
+			return nil
+		}
+		last := page[len(page)-1]
+		lastTimestamp, lastID = last.Timestamp.UnixMilli(), last.ID
+	}
+}
+
+// queryLogPage lê uma página da exportação
+func queryLogPage(query string, args ...interface{}) ([]LogRecord, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		recordError("export_logs")
+		return nil, fmt.Errorf("erro ao exportar logs: %v", err)
+	}
+	defer rows.Close()
+
+	var records []LogRecord
+	for rows.Next() {
+		var record LogRecord
+		var timestampMs int64
+		if err := rows.Scan(&record.ID, &timestampMs, &record.Type, &record.Content, &record.RequestID); err != nil {
+			recordError("export_logs")
+			return nil, fmt.Errorf("erro ao ler log: %v", err)
+		}
+		record.Timestamp = time.UnixMilli(timestampMs)
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		recordError("export_logs")
+		return nil, fmt.Errorf("erro ao iterar logs: %v", err)
+	}
+	return records, nil
+}
+
+// logConditions monta a cláusula WHERE e os argumentos dos filtros de logs
+func logConditions(filter LogFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "timestamp_ms >= ?")
+		args = append(args, filter.From.UnixMilli())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "timestamp_ms <= ?")
+		args = append(args, filter.To.UnixMilli())
+	}
+	if len(filter.Types) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(filter.Types)), ",")
+		conditions = append(conditions, "type IN ("+placeholders+")")
+		for _, t := range filter.Types {
+			args = append(args, t)
+		}
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "content LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, filter.RequestID)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ClearLogs remove todas as entradas de log persistidas
+func ClearLogs() error {
+	if db == nil {
+		return nil
+	}
+	FlushLogs()
+
+	if _, err := db.Exec("DELETE FROM log_entries"); err != nil {
+		recordError("clear_logs")
+		return fmt.Errorf("erro ao limpar logs: %v", err)
+	}
+	return nil
+}
+
+// escapeLike escapa os curingas do LIKE para busca textual literal
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	return strings.ReplaceAll(s, "_", `\_`)
+}
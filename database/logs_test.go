@@ -0,0 +1,110 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLogWriterBatching(t *testing.T) {
+	openTestDatabase(t)
+	StartLogWriter(0)
+
+	// Mais entradas que um lote, para exigir várias transações
+	total := logBatchSize*2 + 7
+	start := time.Now().Add(-time.Minute)
+	for i := 0; i < total; i++ {
+		EnqueueLog(LogRecord{
+			Timestamp: start.Add(time.Duration(i) * time.Millisecond),
+			Type:      "INFO",
+			Content:   fmt.Sprintf("entrada %d", i),
+		})
+	}
+	FlushLogs()
+
+	records, count, err := QueryLogs(LogFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("QueryLogs: %v", err)
+	}
+	if count != total {
+		t.Fatalf("total = %d, esperado %d", count, total)
+	}
+	if len(records) != 10 {
+		t.Fatalf("página com %d entradas, esperado 10", len(records))
+	}
+	// A página traz as mais recentes em ordem cronológica
+	if last := records[len(records)-1].Content; last != fmt.Sprintf("entrada %d", total-1) {
+		t.Errorf("última entrada = %q, esperado a mais recente", last)
+	}
+	if records[0].Timestamp.After(records[1].Timestamp) {
+		t.Error("entradas fora de ordem cronológica")
+	}
+}
+
+func TestPruneLogs(t *testing.T) {
+	openTestDatabase(t)
+
+	now := time.Now()
+	batch := []LogRecord{
+		{Timestamp: now.Add(-48 * time.Hour), Type: "INFO", Content: "antiga"},
+		{Timestamp: now.Add(-25 * time.Hour), Type: "INFO", Content: "vencida"},
+		{Timestamp: now.Add(-time.Hour), Type: "INFO", Content: "recente"},
+	}
+	if err := insertLogBatch(batch); err != nil {
+		t.Fatalf("insertLogBatch: %v", err)
+	}
+
+	pruneLogs(24 * time.Hour)
+
+	records, count, err := QueryLogs(LogFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("QueryLogs: %v", err)
+	}
+	if count != 1 || records[0].Content != "recente" {
+		t.Errorf("restaram %d entradas (%v), esperado só a recente", count, records)
+	}
+}
+
+func TestStopLogWriter(t *testing.T) {
+	openTestDatabase(t)
+	StartLogWriter(time.Hour)
+
+	EnqueueLog(LogRecord{Timestamp: time.Now(), Type: "INFO", Content: "pendente"})
+	StopLogWriter()
+
+	// As entradas pendentes são gravadas ao parar
+	if _, count, err := QueryLogs(LogFilter{Limit: 10}); err != nil || count != 1 {
+		t.Fatalf("QueryLogs = %d, %v; esperado a entrada pendente gravada", count, err)
+	}
+
+	// Depois de parar, registrar ou forçar a gravação não tem efeito
+	EnqueueLog(LogRecord{Timestamp: time.Now(), Type: "INFO", Content: "descartada"})
+	FlushLogs()
+	StopLogWriter()
+
+	// O gravador pode ser reiniciado
+	StartLogWriter(time.Hour)
+	EnqueueLog(LogRecord{Timestamp: time.Now(), Type: "INFO", Content: "nova"})
+	FlushLogs()
+	if _, count, err := QueryLogs(LogFilter{Limit: 10}); err != nil || count != 2 {
+		t.Fatalf("QueryLogs = %d, %v; esperado 2 entradas", count, err)
+	}
+}
+
+func TestLogWriterWithoutDatabase(t *testing.T) {
+	openTestDatabase(t)
+	StartLogWriter(time.Hour)
+	EnqueueLog(LogRecord{Timestamp: time.Now(), Type: "INFO", Content: "pendente"})
+
+	// CloseDatabase encerra o gravador antes de fechar a conexão
+	if err := CloseDatabase(); err != nil {
+		t.Fatalf("CloseDatabase: %v", err)
+	}
+	EnqueueLog(LogRecord{Timestamp: time.Now(), Type: "INFO", Content: "sem banco"})
+	FlushLogs()
+
+	if err := insertLogBatch([]LogRecord{{Timestamp: time.Now(), Type: "INFO"}}); err == nil {
+		t.Error("insertLogBatch sem banco deveria falhar")
+	}
+	pruneLogs(time.Hour)
+}
@@ -0,0 +1,289 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationFiles contém os scripts SQL de migração (NNNN_nome.up.sql e NNNN_nome.down.sql)
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationFilePattern interpreta o nome dos arquivos de migração
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// migrationHooks são etapas em Go executadas depois do script SQL da versão, na mesma
+// transação, para conversões de dados que o SQL não consegue fazer
+var migrationHooks = map[int]struct {
+	Up   func(tx *sql.Tx) error
+	Down func(tx *sql.Tx) error
+}{
+	5: {Up: encryptLicenseTokens, Down: decryptLicenseTokens},
+}
+
+// Migration é uma versão do esquema com os scripts para aplicar e reverter
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState descreve uma migração e se ela já foi aplicada ao banco
+type MigrationState struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// loadMigrations lê os scripts embarcados em ordem de versão.
+// Toda versão precisa dos dois scripts e as versões não podem se repetir.
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler migrações: %v", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nome de migração inválido: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler migração %s: %v", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("versão %d usada por %s e %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migração %04d_%s sem o script up ou down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ensureMigrationsTable cria a tabela que registra as versões aplicadas
+func ensureMigrationsTable() error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at_ms INTEGER NOT NULL
+	);
+	`)
+	if err != nil {
+		recordError("migrate")
+		return fmt.Errorf("erro ao criar tabela schema_migrations: %v", err)
+	}
+	return nil
+}
+
+// appliedMigrations retorna o horário de aplicação de cada versão registrada
+func appliedMigrations() (map[int]time.Time, error) {
+	rows, err := db.Query(`SELECT version, applied_at_ms FROM schema_migrations`)
+	if err != nil {
+		recordError("migrate")
+		return nil, fmt.Errorf("erro ao consultar schema_migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedMs int64
+		if err := rows.Scan(&version, &appliedMs); err != nil {
+			recordError("migrate")
+			return nil, fmt.Errorf("erro ao ler schema_migrations: %v", err)
+		}
+		applied[version] = time.UnixMilli(appliedMs)
+	}
+	if err := rows.Err(); err != nil {
+		recordError("migrate")
+		return nil, fmt.Errorf("erro ao iterar schema_migrations: %v", err)
+	}
+	return applied, nil
+}
+
+// MigrationStatus lista todas as migrações conhecidas e quais já foram aplicadas
+func MigrationStatus() ([]MigrationState, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, len(migrations))
+	for i, migration := range migrations {
+		appliedAt, ok := applied[migration.Version]
+		states[i] = MigrationState{Version: migration.Version, Name: migration.Name, Applied: ok, AppliedAt: appliedAt}
+	}
+	return states, nil
+}
+
+// MigrateUp aplica as migrações pendentes até a versão target (0 aplica todas).
+// Cada migração roda em uma transação própria junto com o registro em schema_migrations,
+// então uma falha desfaz apenas a migração atual. Retorna as migrações aplicadas.
+func MigrateUp(target int) ([]Migration, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// Um banco migrado por uma versão mais nova da aplicação não pode ser usado por esta
+	latest := migrations[len(migrations)-1].Version
+	for version := range applied {
+		if version > latest {
+			return nil, fmt.Errorf("banco de dados na versão %d, mais nova que a suportada (%d)", version, latest)
+		}
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if target > 0 && migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := applyMigration(migration, migration.Up, true); err != nil {
+			return done, err
+		}
+		logger.Info("Migração aplicada", "version", migration.Version, "name", migration.Name)
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// MigrateDown reverte as últimas steps migrações aplicadas, da mais nova para a mais antiga.
+// Retorna as migrações revertidas.
+func MigrateDown(steps int) ([]Migration, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+	if steps < 1 {
+		return nil, fmt.Errorf("quantidade de migrações a reverter deve ser maior que zero")
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := applyMigration(migration, migration.Down, false); err != nil {
+			return done, err
+		}
+		logger.Info("Migração revertida", "version", migration.Version, "name", migration.Name)
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// applyMigration executa o script e atualiza schema_migrations na mesma transação
+func applyMigration(migration Migration, script string, up bool) error {
+	direction := "reverter"
+	if up {
+		direction = "aplicar"
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		recordError("migrate")
+		return fmt.Errorf("erro ao %s migração %04d_%s: %v", direction, migration.Version, migration.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		recordError("migrate")
+		return fmt.Errorf("erro ao %s migração %04d_%s: %v", direction, migration.Version, migration.Name, err)
+	}
+
+	if hook, ok := migrationHooks[migration.Version]; ok {
+		run := hook.Down
+		if up {
+			run = hook.Up
+		}
+		if err := run(tx); err != nil {
+			recordError("migrate")
+			return fmt.Errorf("erro ao %s migração %04d_%s: %v", direction, migration.Version, migration.Name, err)
+		}
+	}
+
+	if up {
+		_, err = tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at_ms) VALUES (?, ?, ?)`,
+			migration.Version, migration.Name, time.Now().UnixMilli())
+	} else {
+		_, err = tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, migration.Version)
+	}
+	if err != nil {
+		recordError("migrate")
+		return fmt.Errorf("erro ao registrar migração %04d_%s: %v", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		recordError("migrate")
+		return fmt.Errorf("erro ao %s migração %04d_%s: %v", direction, migration.Version, migration.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"testing"
+
+	"go-desktop-app/secrets"
+)
+
+// hasColumn informa se a tabela tem a coluna (false se a tabela não existir)
+func hasColumn(t *testing.T, table, column string) bool {
+	t.Helper()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		t.Fatalf("pragma_table_info(%s): %v", table, err)
+	}
+	return count > 0
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	openTestDatabase(t)
+
+	// Cada migração, da mais nova para a mais antiga, e uma coluna que ela cria
+	tests := []struct {
+		version int
+		table   string
+		column  string
+	}{
+		{9, "license_info", "fingerprint"},
+		{8, "offline_license", "content"},
+		{7, "license_info", "last_verified_at"},
+		{6, "license_events", "type"},
+		{4, "audit_log", "hash"},
+		{3, "webhooks", "url"},
+		{2, "log_entries", "content"},
+		{1, "license_info", "token"},
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	latest := migrations[len(migrations)-1].Version
+	if latest != tests[0].version {
+		t.Fatalf("migração mais nova é %d; inclua-a no teste", latest)
+	}
+
+	for _, tt := range tests {
+		if !hasColumn(t, tt.table, tt.column) {
+			t.Errorf("depois de MigrateUp falta %s.%s", tt.table, tt.column)
+		}
+	}
+
+	// Reverte tudo, uma migração por vez
+	for version := latest; version >= 1; version-- {
+		done, err := MigrateDown(1)
+		if err != nil {
+			t.Fatalf("MigrateDown na versão %d: %v", version, err)
+		}
+		if len(done) != 1 || done[0].Version != version {
+			t.Fatalf("MigrateDown reverteu %v, esperado a versão %d", done, version)
+		}
+		for _, tt := range tests {
+			if tt.version == version && hasColumn(t, tt.table, tt.column) {
+				t.Errorf("depois de reverter %d ainda existe %s.%s", version, tt.table, tt.column)
+			}
+		}
+	}
+	if done, err := MigrateDown(1); err != nil || len(done) != 0 {
+		t.Errorf("MigrateDown sem migrações aplicadas = %v, %v", done, err)
+	}
+
+	// Reaplica até uma versão intermediária e depois o restante
+	done, err := MigrateUp(4)
+	if err != nil || len(done) != 4 {
+		t.Fatalf("MigrateUp(4) = %d migrações, %v", len(done), err)
+	}
+	if hasColumn(t, "license_events", "type") {
+		t.Error("MigrateUp(4) aplicou a migração 6")
+	}
+	done, err = MigrateUp(0)
+	if err != nil || len(done) != latest-4 {
+		t.Fatalf("MigrateUp(0) = %d migrações, %v", len(done), err)
+	}
+	for _, tt := range tests {
+		if !hasColumn(t, tt.table, tt.column) {
+			t.Errorf("depois de reaplicar falta %s.%s", tt.table, tt.column)
+		}
+	}
+}
+
+func TestMigrationKeepsLicenseToken(t *testing.T) {
+	openTestDatabase(t)
+	store := Licenses()
+	if err := store.Save("token-secreto", "device-1"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		migrate   func() error
+		encrypted bool
+	}{
+		{"antes da migração 5 o token volta a texto puro", func() error { _, err := MigrateDown(5); return err }, false},
+		{"a migração 5 cifra o token de novo", func() error { _, err := MigrateUp(0); return err }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.migrate(); err != nil {
+				t.Fatalf("migração: %v", err)
+			}
+			var stored string
+			if err := db.QueryRow(`SELECT token FROM license_info`).Scan(&stored); err != nil {
+				t.Fatalf("ler token: %v", err)
+			}
+			if secrets.IsEncrypted(stored) != tt.encrypted {
+				t.Errorf("token gravado %q, cifrado esperado = %v", stored, tt.encrypted)
+			}
+			if !tt.encrypted && stored != "token-secreto" {
+				t.Errorf("token em texto puro = %q", stored)
+			}
+		})
+	}
+
+	info, err := store.Get()
+	if err != nil || info == nil || info.Token != "token-secreto" {
+		t.Fatalf("Get depois das migrações = %+v, %v", info, err)
+	}
+}
@@ -0,0 +1,14 @@
+-- Tabela principal para informações de licença.
+-- IF NOT EXISTS permite adotar bancos criados antes do controle de versões.
+CREATE TABLE IF NOT EXISTS license_info (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	token TEXT NOT NULL UNIQUE,
+	device_uuid TEXT NOT NULL UNIQUE,
+	is_active BOOLEAN DEFAULT FALSE,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	last_check DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_license_token ON license_info(token);
+CREATE INDEX IF NOT EXISTS idx_license_uuid ON license_info(device_uuid);
+CREATE INDEX IF NOT EXISTS idx_license_active ON license_info(is_active);
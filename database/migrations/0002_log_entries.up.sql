@@ -0,0 +1,11 @@
+-- Logs da interface web gravados em lote
+CREATE TABLE IF NOT EXISTS log_entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp_ms INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	content TEXT NOT NULL,
+	request_id TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_log_entries_timestamp ON log_entries(timestamp_ms);
+CREATE INDEX IF NOT EXISTS idx_log_entries_type ON log_entries(type);
+CREATE INDEX IF NOT EXISTS idx_log_entries_request ON log_entries(request_id);
@@ -0,0 +1,27 @@
+-- Trilha de auditoria somente inclusão, com cadeia de hashes (prev_hash -> hash)
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp_ms INTEGER NOT NULL,
+	caller TEXT NOT NULL,
+	remote_addr TEXT NOT NULL,
+	operation TEXT NOT NULL,
+	target TEXT NOT NULL DEFAULT '',
+	arguments TEXT NOT NULL DEFAULT '',
+	result TEXT NOT NULL,
+	status_code INTEGER NOT NULL,
+	error TEXT NOT NULL DEFAULT '',
+	request_id TEXT NOT NULL DEFAULT '',
+	prev_hash TEXT NOT NULL,
+	hash TEXT NOT NULL UNIQUE
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp ON audit_log(timestamp_ms);
+CREATE INDEX IF NOT EXISTS idx_audit_log_operation ON audit_log(operation);
+CREATE INDEX IF NOT EXISTS idx_audit_log_request ON audit_log(request_id);
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log aceita apenas inclusões');
+END;
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log aceita apenas inclusões');
+END;
@@ -0,0 +1 @@
+-- Volta license_info.token para texto puro (decryptLicenseTokens, em migrationHooks).
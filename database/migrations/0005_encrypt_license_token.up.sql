@@ -0,0 +1,3 @@
+-- Cifra license_info.token com o cofre de segredos da máquina.
+-- A conversão dos registros é feita em Go (encryptLicenseTokens, em migrationHooks),
+-- na mesma transação deste script.
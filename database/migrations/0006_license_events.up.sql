@@ -0,0 +1,14 @@
+-- Histórico da licença: configuração, verificações, ativação/desativação e remoção
+CREATE TABLE IF NOT EXISTS license_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp_ms INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	device_uuid TEXT NOT NULL DEFAULT '',
+	result TEXT NOT NULL DEFAULT '',
+	message TEXT NOT NULL DEFAULT '',
+	http_status INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	request_id TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_license_events_timestamp ON license_events(timestamp_ms);
+CREATE INDEX IF NOT EXISTS idx_license_events_type ON license_events(type);
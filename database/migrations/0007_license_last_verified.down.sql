@@ -0,0 +1 @@
+ALTER TABLE license_info DROP COLUMN last_verified_at;
@@ -0,0 +1,4 @@
+-- Horário da última resposta válida do servidor de licenças, base do período de tolerância offline
+ALTER TABLE license_info ADD COLUMN last_verified_at DATETIME;
+-- Licenças já ativas contam a tolerância a partir da última verificação registrada
+UPDATE license_info SET last_verified_at = last_check WHERE is_active = TRUE;
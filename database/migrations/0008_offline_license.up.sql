@@ -0,0 +1,8 @@
+-- Licença offline importada de um arquivo assinado (Ed25519). O conteúdo é guardado como
+-- recebido e a assinatura é conferida de novo a cada leitura.
+CREATE TABLE IF NOT EXISTS offline_license (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	device_uuid TEXT NOT NULL,
+	content TEXT NOT NULL,
+	imported_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
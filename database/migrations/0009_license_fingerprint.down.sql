@@ -0,0 +1,2 @@
+ALTER TABLE offline_license DROP COLUMN fingerprint;
+ALTER TABLE license_info DROP COLUMN fingerprint;
@@ -0,0 +1,3 @@
+-- Impressão digital da máquina em que a licença foi registrada (JSON com os hashes dos componentes)
+ALTER TABLE license_info ADD COLUMN fingerprint TEXT NOT NULL DEFAULT '';
+ALTER TABLE offline_license ADD COLUMN fingerprint TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// OfflineLicenseRecord é o arquivo de licença offline guardado pelo LicenseStore.
+// Content é o arquivo assinado como foi importado; quem lê confere a assinatura.
+// Fingerprint é a impressão digital da máquina em que o arquivo foi importado.
+type OfflineLicenseRecord struct {
+	DeviceUUID  string `json:"device_uuid"`
+	Content     string `json:"-"`
+	Fingerprint string `json:"-"`
+	ImportedAt  string `json:"imported_at"`
+}
+
+// SaveOfflineLicense substitui a licença offline armazenada (ImportedAt é o horário atual)
+func (s *SQLiteLicenseStore) SaveOfflineLicense(record OfflineLicenseRecord) error {
+	if err := s.checkDB(); err != nil {
+		return err
+	}
+	if record.DeviceUUID == "" {
+		return fmt.Errorf("device UUID não pode estar vazio")
+	}
+	if record.Content == "" {
+		return fmt.Errorf("conteúdo da licença offline não pode estar vazio")
+	}
+
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO offline_license (id, device_uuid, content, fingerprint, imported_at)
+		VALUES (1, ?, ?, ?, CURRENT_TIMESTAMP)`, record.DeviceUUID, record.Content, record.Fingerprint)
+	if err != nil {
+		recordError("save_offline_license")
+		return fmt.Errorf("erro ao salvar licença offline: %v", err)
+	}
+
+	logger.Info("Licença offline salva com sucesso", "device_uuid", record.DeviceUUID)
+	return nil
+}
+
+// GetOfflineLicense retorna a licença offline armazenada (nil se não houver)
+func (s *SQLiteLicenseStore) GetOfflineLicense() (*OfflineLicenseRecord, error) {
+	if err := s.checkDB(); err != nil {
+		return nil, err
+	}
+
+	var record OfflineLicenseRecord
+	err := s.db.QueryRow("SELECT device_uuid, content, fingerprint, imported_at FROM offline_license WHERE id = 1").
+		Scan(&record.DeviceUUID, &record.Content, &record.Fingerprint, &record.ImportedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		recordError("get_offline_license")
+		return nil, fmt.Errorf("erro ao recuperar licença offline: %v", err)
+	}
+	return &record, nil
+}
@@ -0,0 +1,472 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status das entregas de webhook
+const (
+	DeliveryPending   = "pending"   // aguardando a primeira tentativa
+	DeliveryRetrying  = "retrying"  // falhou e aguarda nova tentativa
+	DeliveryDelivered = "delivered" // recebida com status 2xx
+	DeliveryFailed    = "failed"    // esgotou as tentativas e foi para a dead letter
+)
+
+// Erros de consulta de webhooks e entregas
+var (
+	ErrWebhookNotFound  = errors.New("webhook não encontrado")
+	ErrDeliveryNotFound = errors.New("entrega de webhook não encontrada")
+)
+
+// Webhook é uma inscrição de webhook. Events lista os tipos de evento aceitos ("*" aceita todos).
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"-"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery é a entrega de um evento a um webhook
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	RequestID      string     `json:"request_id,omitempty"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// WebhookDeadLetter registra uma entrega que esgotou as tentativas
+type WebhookDeadLetter struct {
+	ID         int64     `json:"id"`
+	DeliveryID int64     `json:"delivery_id"`
+	WebhookID  int64     `json:"webhook_id"`
+	EventType  string    `json:"event_type"`
+	Payload    string    `json:"payload"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error"`
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+// DeliveryFilter define os filtros da consulta de entregas
+type DeliveryFilter struct {
+	WebhookID int64
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+// CreateWebhook grava uma nova inscrição de webhook
+func CreateWebhook(url string, events []string, secret string) (*Webhook, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	hook := &Webhook{URL: url, Events: events, Secret: secret, Active: true, CreatedAt: time.Now()}
+	result, err := db.Exec(`INSERT INTO webhooks (url, events, secret, active, created_at_ms) VALUES (?, ?, ?, TRUE, ?)`,
+		url, strings.Join(events, ","), secret, hook.CreatedAt.UnixMilli())
+	if err != nil {
+		recordError("create_webhook")
+		return nil, fmt.Errorf("erro ao criar webhook: %v", err)
+	}
+
+	if hook.ID, err = result.LastInsertId(); err != nil {
+		recordError("create_webhook")
+		return nil, fmt.Errorf("erro ao criar webhook: %v", err)
+	}
+	return hook, nil
+}
+
+// ListWebhooks retorna todas as inscrições de webhook
+func ListWebhooks() ([]Webhook, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	rows, err := db.Query(`SELECT id, url, events, secret, active, created_at_ms FROM webhooks ORDER BY id`)
+	if err != nil {
+		recordError("list_webhooks")
+		return nil, fmt.Errorf("erro ao listar webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	hooks := []Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			recordError("list_webhooks")
+			return nil, fmt.Errorf("erro ao ler webhook: %v", err)
+		}
+		hooks = append(hooks, *hook)
+	}
+	if err := rows.Err(); err != nil {
+		recordError("list_webhooks")
+		return nil, fmt.Errorf("erro ao iterar webhooks: %v", err)
+	}
+	return hooks, nil
+}
+
+// GetWebhook retorna uma inscrição pelo ID (ErrWebhookNotFound se não existir)
+func GetWebhook(id int64) (*Webhook, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	row := db.QueryRow(`SELECT id, url, events, secret, active, created_at_ms FROM webhooks WHERE id = ?`, id)
+	hook, err := scanWebhook(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		recordError("get_webhook")
+		return nil, fmt.Errorf("erro ao ler webhook: %v", err)
+	}
+	return hook, nil
+}
+
+// DeleteWebhook remove a inscrição com suas entregas e registros de dead letter
+func DeleteWebhook(id int64) error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		recordError("delete_webhook")
+		return fmt.Errorf("erro ao remover webhook: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		recordError("delete_webhook")
+		return fmt.Errorf("erro ao remover webhook: %v", err)
+	}
+	if removed, _ := result.RowsAffected(); removed == 0 {
+		return ErrWebhookNotFound
+	}
+
+	for _, query := range []string{
+		`DELETE FROM webhook_deliveries WHERE webhook_id = ?`,
+		`DELETE FROM webhook_dead_letters WHERE webhook_id = ?`,
+	} {
+		if _, err := tx.Exec(query, id); err != nil {
+			recordError("delete_webhook")
+			return fmt.Errorf("erro ao remover entregas do webhook: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		recordError("delete_webhook")
+		return fmt.Errorf("erro ao remover webhook: %v", err)
+	}
+	return nil
+}
+
+// WebhooksForEvent retorna as inscrições ativas que aceitam o tipo de evento
+func WebhooksForEvent(eventType string) ([]Webhook, error) {
+	hooks, err := ListWebhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Webhook
+	for _, hook := range hooks {
+		if hook.Active && hook.Accepts(eventType) {
+			matched = append(matched, hook)
+		}
+	}
+	return matched, nil
+}
+
+// Accepts verifica se a inscrição aceita o tipo de evento
+func (h *Webhook) Accepts(eventType string) bool {
+	for _, e := range h.Events {
+		if e == "*" || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// EnqueueWebhookDeliveries cria, em uma transação, uma entrega pendente do evento para cada webhook
+func EnqueueWebhookDeliveries(hooks []Webhook, eventID int64, eventType, requestID, payload string) error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		recordError("enqueue_webhook")
+		return fmt.Errorf("erro ao agendar entregas: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UnixMilli()
+	for _, hook := range hooks {
+		_, err := tx.Exec(`
+			INSERT INTO webhook_deliveries
+				(webhook_id, event_id, event_type, request_id, payload, status, next_attempt_ms, created_at_ms)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			hook.ID, eventID, eventType, requestID, payload, DeliveryPending, now, now)
+		if err != nil {
+			recordError("enqueue_webhook")
+			return fmt.Errorf("erro ao agendar entrega: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		recordError("enqueue_webhook")
+		return fmt.Errorf("erro ao agendar entregas: %v", err)
+	}
+	return nil
+}
+
+// deliveryColumns são as colunas lidas por scanDelivery
+const deliveryColumns = `id, webhook_id, event_id, event_type, request_id, payload, status, attempts,
+	next_attempt_ms, last_status_code, last_error, created_at_ms, delivered_at_ms`
+
+// DueWebhookDeliveries retorna as entregas pendentes cuja próxima tentativa já venceu
+func DueWebhookDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	rows, err := db.Query(`SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE status IN (?, ?) AND next_attempt_ms <= ?
+		ORDER BY next_attempt_ms, id
+		LIMIT ?`, DeliveryPending, DeliveryRetrying, now.UnixMilli(), limit)
+	if err != nil {
+		recordError("due_webhooks")
+		return nil, fmt.Errorf("erro ao consultar entregas pendentes: %v", err)
+	}
+	return scanDeliveries(rows, "due_webhooks")
+}
+
+// MarkWebhookDelivered registra a entrega bem-sucedida
+func MarkWebhookDelivered(id int64, attempts, statusCode int) error {
+	_, err := db.Exec(`UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_status_code = ?, last_error = '', next_attempt_ms = NULL, delivered_at_ms = ?
+		WHERE id = ?`, DeliveryDelivered, attempts, statusCode, time.Now().UnixMilli(), id)
+	if err != nil {
+		recordError("update_webhook")
+		return fmt.Errorf("erro ao atualizar entrega: %v", err)
+	}
+	return nil
+}
+
+// MarkWebhookRetry registra a falha e agenda a próxima tentativa
+func MarkWebhookRetry(id int64, attempts, statusCode int, lastError string, next time.Time) error {
+	_, err := db.Exec(`UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, next_attempt_ms = ?
+		WHERE id = ?`, DeliveryRetrying, attempts, statusCode, lastError, next.UnixMilli(), id)
+	if err != nil {
+		recordError("update_webhook")
+		return fmt.Errorf("erro ao atualizar entrega: %v", err)
+	}
+	return nil
+}
+
+// MarkWebhookDeadLetter marca a entrega como falha e a copia para a dead letter
+func MarkWebhookDeadLetter(delivery WebhookDelivery, attempts, statusCode int, lastError string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		recordError("dead_letter_webhook")
+		return fmt.Errorf("erro ao mover entrega para dead letter: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UnixMilli()
+	if _, err := tx.Exec(`UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, next_attempt_ms = NULL
+		WHERE id = ?`, DeliveryFailed, attempts, statusCode, lastError, delivery.ID); err != nil {
+		recordError("dead_letter_webhook")
+		return fmt.Errorf("erro ao atualizar entrega: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO webhook_dead_letters
+		(delivery_id, webhook_id, event_type, payload, attempts, last_error, failed_at_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		delivery.ID, delivery.WebhookID, delivery.EventType, delivery.Payload, attempts, lastError, now); err != nil {
+		recordError("dead_letter_webhook")
+		return fmt.Errorf("erro ao gravar dead letter: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		recordError("dead_letter_webhook")
+		return fmt.Errorf("erro ao mover entrega para dead letter: %v", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries consulta as entregas, das mais recentes para as mais antigas.
+// Retorna também o total que atende aos filtros.
+func ListWebhookDeliveries(filter DeliveryFilter) ([]WebhookDelivery, int, error) {
+	if db == nil {
+		return nil, 0, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	var conditions []string
+	var args []interface{}
+	if filter.WebhookID > 0 {
+		conditions = append(conditions, "webhook_id = ?")
+		args = append(args, filter.WebhookID)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM webhook_deliveries "+where, args...).Scan(&total); err != nil {
+		recordError("list_deliveries")
+		return nil, 0, fmt.Errorf("erro ao contar entregas: %v", err)
+	}
+
+	rows, err := db.Query(`SELECT `+deliveryColumns+` FROM webhook_deliveries `+where+`
+		ORDER BY id DESC LIMIT ? OFFSET ?`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		recordError("list_deliveries")
+		return nil, 0, fmt.Errorf("erro ao consultar entregas: %v", err)
+	}
+	deliveries, err := scanDeliveries(rows, "list_deliveries")
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// ListWebhookDeadLetters retorna as entregas que esgotaram as tentativas, das mais recentes para as mais antigas
+func ListWebhookDeadLetters(limit, offset int) ([]WebhookDeadLetter, error) {
+	if db == nil {
+		return nil, fmt.Errorf("banco de dados não inicializado")
+	}
+
+	rows, err := db.Query(`SELECT id, delivery_id, webhook_id, event_type, payload, attempts, last_error, failed_at_ms
+		FROM webhook_dead_letters ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		recordError("list_dead_letters")
+		return nil, fmt.Errorf("erro ao consultar dead letters: %v", err)
+	}
+	defer rows.Close()
+
+	letters := []WebhookDeadLetter{}
+	for rows.Next() {
+		var letter WebhookDeadLetter
+		var failedAtMs int64
+		if err := rows.Scan(&letter.ID, &letter.DeliveryID, &letter.WebhookID, &letter.EventType,
+			&letter.Payload, &letter.Attempts, &letter.LastError, &failedAtMs); err != nil {
+			recordError("list_dead_letters")
+			return nil, fmt.Errorf("erro ao ler dead letter: %v", err)
+		}
+		letter.FailedAt = time.UnixMilli(failedAtMs)
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		recordError("list_dead_letters")
+		return nil, fmt.Errorf("erro ao iterar dead letters: %v", err)
+	}
+	return letters, nil
+}
+
+// ReplayWebhookDelivery reagenda a entrega para agora, zerando as tentativas,
+// e a retira da dead letter. Entregas já concluídas também podem ser reenviadas.
+func ReplayWebhookDelivery(id int64) error {
+	if db == nil {
+		return fmt.Errorf("banco de dados não inicializado")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		recordError("replay_webhook")
+		return fmt.Errorf("erro ao reenviar entrega: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, last_status_code = 0, last_error = '', next_attempt_ms = ?, delivered_at_ms = NULL
+		WHERE id = ?`, DeliveryPending, time.Now().UnixMilli(), id)
+	if err != nil {
+		recordError("replay_webhook")
+		return fmt.Errorf("erro ao reenviar entrega: %v", err)
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return ErrDeliveryNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM webhook_dead_letters WHERE delivery_id = ?`, id); err != nil {
+		recordError("replay_webhook")
+		return fmt.Errorf("erro ao remover dead letter: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		recordError("replay_webhook")
+		return fmt.Errorf("erro ao reenviar entrega: %v", err)
+	}
+	return nil
+}
+
+// rowScanner é implementado por *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWebhook lê uma inscrição de webhook
+func scanWebhook(row rowScanner) (*Webhook, error) {
+	var hook Webhook
+	var events string
+	var createdAtMs int64
+	if err := row.Scan(&hook.ID, &hook.URL, &events, &hook.Secret, &hook.Active, &createdAtMs); err != nil {
+		return nil, err
+	}
+	hook.Events = strings.Split(events, ",")
+	hook.CreatedAt = time.UnixMilli(createdAtMs)
+	return &hook, nil
+}
+
+// scanDeliveries lê as entregas retornadas por uma consulta com deliveryColumns
+func scanDeliveries(rows *sql.Rows, operation string) ([]WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		var nextAttemptMs, deliveredAtMs sql.NullInt64
+		var createdAtMs int64
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.RequestID, &d.Payload, &d.Status,
+			&d.Attempts, &nextAttemptMs, &d.LastStatusCode, &d.LastError, &createdAtMs, &deliveredAtMs); err != nil {
+			recordError(operation)
+			return nil, fmt.Errorf("erro ao ler entrega: %v", err)
+		}
+		d.CreatedAt = time.UnixMilli(createdAtMs)
+		if nextAttemptMs.Valid {
+			t := time.UnixMilli(nextAttemptMs.Int64)
+			d.NextAttemptAt = &t
+		}
+		if deliveredAtMs.Valid {
+			t := time.UnixMilli(deliveredAtMs.Int64)
+			d.DeliveredAt = &t
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		recordError(operation)
+		return nil, fmt.Errorf("erro ao iterar entregas: %v", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-desktop-app/logging"
+	"go-desktop-app/metrics"
+)
+
+// subscriberBuffer é a quantidade de eventos pendentes por inscrição
+const subscriberBuffer = 256
+
+// logger registra falhas dos consumidores do barramento
+var logger = logging.Component("events")
+
+// Handler recebe os eventos de uma inscrição
+type Handler func(Event)
+
+// subscription entrega os eventos a um consumidor em uma goroutine própria,
+// na ordem de publicação, sem bloquear quem publicou
+type subscription struct {
+	name    string
+	types   map[Type]bool
+	queue   chan Event
+	done    chan struct{}
+	handler Handler
+}
+
+var (
+	subscriptions      []*subscription
+	subscriptionsMutex sync.RWMutex
+	eventSequence      atomic.Uint64
+)
+
+// Subscribe registra um consumidor para os tipos informados (todos se nenhum for informado).
+// name identifica o consumidor nas métricas e nos logs. Retorna a função que cancela a inscrição.
+func Subscribe(name string, handler Handler, types ...Type) (unsubscribe func()) {
+	sub := &subscription{
+		name:    name,
+		types:   make(map[Type]bool),
+		queue:   make(chan Event, subscriberBuffer),
+		done:    make(chan struct{}),
+		handler: handler,
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	subscriptionsMutex.Lock()
+	subscriptions = append(subscriptions, sub)
+	subscriptionsMutex.Unlock()
+
+	go sub.run()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			subscriptionsMutex.Lock()
+			for i, s := range subscriptions {
+				if s == sub {
+					subscriptions = append(subscriptions[:i], subscriptions[i+1:]...)
+					break
+				}
+			}
+			subscriptionsMutex.Unlock()
+			close(sub.done)
+		})
+	}
+}
+
+// Publish envia o evento a todas as inscrições interessadas.
+// Preenche ID e Time; se a fila de um consumidor estiver cheia, o evento é descartado para ele.
+func Publish(event Event) {
+	event.ID = eventSequence.Add(1)
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	metrics.EventsPublished.Inc(string(event.Type))
+
+	subscriptionsMutex.RLock()
+	defer subscriptionsMutex.RUnlock()
+
+	for _, sub := range subscriptions {
+		if len(sub.types) > 0 && !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.queue <- event:
+		default:
+			metrics.EventsDropped.Inc(sub.name)
+		}
+	}
+}
+
+// run entrega os eventos da fila até a inscrição ser cancelada
+func (s *subscription) run() {
+	for {
+		select {
+		case event := <-s.queue:
+			s.deliver(event)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// deliver chama o consumidor, isolando panics para não derrubar a inscrição
+func (s *subscription) deliver(event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Consumidor de eventos falhou", "subscriber", s.name, "event", event.Type, "panic", r)
+		}
+	}()
+	s.handler(event)
+}
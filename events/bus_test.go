@@ -0,0 +1,157 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go-desktop-app/metrics"
+)
+
+// collector acumula os eventos recebidos por uma inscrição
+type collector struct {
+	mutex  sync.Mutex
+	events []Event
+}
+
+func (c *collector) handle(event Event) {
+	c.mutex.Lock()
+	c.events = append(c.events, event)
+	c.mutex.Unlock()
+}
+
+// wait aguarda n eventos e retorna os tipos recebidos
+func (c *collector) wait(t *testing.T, n int) []Type {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		c.mutex.Lock()
+		received := len(c.events)
+		var types []Type
+		for _, event := range c.events {
+			types = append(types, event.Type)
+		}
+		c.mutex.Unlock()
+
+		if received >= n {
+			return types
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("recebidos %d eventos, esperado %d", received, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPublishFanOut(t *testing.T) {
+	all, files, config := &collector{}, &collector{}, &collector{}
+	defer Subscribe("teste-todos", all.handle)()
+	defer Subscribe("teste-arquivos", files.handle, FileRead, FileMoved)()
+	defer Subscribe("teste-config", config.handle, ConfigReloaded)()
+
+	Publish(Event{Type: FileRead})
+	Publish(Event{Type: ProcessStarted})
+	Publish(Event{Type: FileMoved})
+	Publish(Event{Type: ConfigReloaded})
+
+	tests := []struct {
+		name      string
+		collector *collector
+		expected  []Type
+	}{
+		{"sem filtro recebe todos em ordem", all, []Type{FileRead, ProcessStarted, FileMoved, ConfigReloaded}},
+		{"filtro de arquivos", files, []Type{FileRead, FileMoved}},
+		{"filtro de configuração", config, []Type{ConfigReloaded}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.collector.wait(t, len(tt.expected))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("eventos = %v, esperado %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("eventos = %v, esperado %v", got, tt.expected)
+				}
+			}
+		})
+	}
+
+	// Publish preenche ID crescente e horário
+	all.mutex.Lock()
+	defer all.mutex.Unlock()
+	for i, event := range all.events {
+		if event.Time.IsZero() {
+			t.Errorf("evento %d sem horário", i)
+		}
+		if i > 0 && event.ID <= all.events[i-1].ID {
+			t.Errorf("IDs fora de ordem: %d após %d", event.ID, all.events[i-1].ID)
+		}
+	}
+}
+
+func TestPublishDropsForSlowSubscriber(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	slow := func(Event) {
+		once.Do(func() { close(started) })
+		<-release
+	}
+	fast := &collector{}
+
+	unsubscribeSlow := Subscribe("teste-lento", slow)
+	defer unsubscribeSlow()
+	defer Subscribe("teste-rapido", fast.handle)()
+	defer close(release)
+
+	// O primeiro evento ocupa o consumidor lento; depois a fila enche e o excedente é descartado
+	Publish(Event{Type: FileRead})
+	<-started
+
+	const extra = 3
+	before := metrics.EventsDropped.Value("teste-lento")
+	for i := 0; i < subscriberBuffer+extra; i++ {
+		Publish(Event{Type: FileRead})
+	}
+
+	if dropped := metrics.EventsDropped.Value("teste-lento") - before; dropped != extra {
+		t.Errorf("descartados = %v, esperado %d", dropped, extra)
+	}
+	// O consumidor lento não impede a entrega aos demais
+	fast.wait(t, 1)
+}
+
+func TestSubscriberPanicIsIsolated(t *testing.T) {
+	received := &collector{}
+	defer Subscribe("teste-panic", func(event Event) {
+		if event.Type == FileRead {
+			panic("falha no consumidor")
+		}
+		received.handle(event)
+	})()
+
+	Publish(Event{Type: FileRead})
+	Publish(Event{Type: FileMoved})
+
+	if got := received.wait(t, 1); got[0] != FileMoved {
+		t.Errorf("eventos = %v, esperado o evento após o panic", got)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	received := &collector{}
+	unsubscribe := Subscribe("teste-cancelado", received.handle)
+	unsubscribe()
+	unsubscribe() // idempotente
+
+	Publish(Event{Type: FileRead})
+	time.Sleep(20 * time.Millisecond)
+
+	received.mutex.Lock()
+	defer received.mutex.Unlock()
+	if len(received.events) != 0 {
+		t.Errorf("inscrição cancelada recebeu %d eventos", len(received.events))
+	}
+}
@@ -0,0 +1,73 @@
+package events
+
+import "time"
+
+// Type identifica o tipo de um evento do barramento
+type Type string
+
+// Tipos de evento publicados pela aplicação
+const (
+	FileRead       Type = "file.read"
+	FileMoved      Type = "file.moved"
+	ProcessStarted Type = "process.started"
+	ProcessExited  Type = "process.exited"
+	LicenseChanged Type = "license.changed"
+	ConfigReloaded Type = "config.reloaded"
+)
+
+// Types lista todos os tipos de evento
+var Types = []Type{FileRead, FileMoved, ProcessStarted, ProcessExited, LicenseChanged, ConfigReloaded}
+
+// Event é um evento publicado no barramento.
+// Data traz o payload do tipo: File, Process, License ou Config.
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      Type        `json:"type"`
+	Time      time.Time   `json:"time"`
+	RequestID string      `json:"request_id,omitempty"`
+	Data      interface{} `json:"data"`
+}
+
+// File é o payload de FileRead e FileMoved
+type File struct {
+	File        string `json:"file"`
+	Destination string `json:"destination,omitempty"`
+	Success     bool   `json:"success"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Process é o payload de ProcessStarted e ProcessExited
+type Process struct {
+	JobID      string     `json:"job_id"`
+	Executable string     `json:"executable"`
+	PID        int        `json:"pid"`
+	Status     string     `json:"status"`
+	ExitCode   int        `json:"exit_code"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+}
+
+// License é o payload de LicenseChanged: o estado da licença armazenada, sem o token.
+// Source indica a origem do estado: "server" (token verificado no servidor de licenças)
+// ou "offline" (arquivo de licença assinado, que expira em ExpiresAt).
+type License struct {
+	HasLicense     bool   `json:"has_license"`
+	IsValid        bool   `json:"is_valid"`
+	Message        string `json:"message"`
+	Source         string `json:"source,omitempty"`
+	DeviceUUID     string `json:"device_uuid,omitempty"`
+	LastCheck      string `json:"last_check,omitempty"`
+	LastVerifiedAt string `json:"last_verified_at,omitempty"`
+	// OfflineGraceUntil é até quando a licença ativa continua válida sem o servidor de licenças
+	OfflineGraceUntil string `json:"offline_grace_until,omitempty"`
+	ExpiresAt         string `json:"expires_at,omitempty"`
+	// NextCheck é quando a licença será verificada de novo em segundo plano
+	NextCheck string `json:"next_check,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Config é o payload de ConfigReloaded
+type Config struct {
+	Path string `json:"path"`
+}
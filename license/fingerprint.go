@@ -0,0 +1,241 @@
+package license
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"go-desktop-app/database"
+
+	"github.com/google/uuid"
+)
+
+// Pesos dos componentes na comparação das impressões digitais
+const (
+	weightMachineID  = 2
+	weightDiskSerial = 1
+	weightMACs       = 1
+)
+
+// fingerprintVersion é a versão do formato de Fingerprint gravado junto da licença
+const fingerprintVersion = 1
+
+// deviceNamespace é o namespace dos UUIDs (versão 5) derivados da impressão digital
+var deviceNamespace = uuid.MustParse("6f1c3a52-8d0e-4b7a-9f21-3c5e7d9a0b14")
+
+// ErrHostMismatch indica que a licença armazenada foi registrada em outra máquina
+var ErrHostMismatch = errors.New("a licença armazenada pertence a outra máquina")
+
+// ErrFingerprintMissing indica que a licença armazenada não tem a impressão digital da máquina
+var ErrFingerprintMissing = errors.New("a licença armazenada não tem a impressão digital da máquina")
+
+// ErrNoMachineAttributes indica que nenhum atributo da máquina pôde ser lido para derivar o UUID
+var ErrNoMachineAttributes = errors.New("nenhum atributo da máquina disponível para identificar o dispositivo")
+
+// Fingerprint identifica a máquina por atributos estáveis do hardware e do sistema.
+// Os valores são guardados como hashes: o arquivo do banco não revela os originais.
+type Fingerprint struct {
+	Version    int      `json:"version"`
+	MachineID  string   `json:"machine_id,omitempty"`
+	DiskSerial string   `json:"disk_serial,omitempty"`
+	MACs       []string `json:"macs,omitempty"`
+}
+
+// FingerprintMatch é o resultado da comparação entre duas impressões digitais
+type FingerprintMatch struct {
+	// Matched e Compared somam os pesos dos componentes que conferem e dos que existem nas duas
+	Matched  int
+	Compared int
+	// Changed lista os componentes que mudaram
+	Changed []string
+}
+
+// OK informa se a máquina é a mesma: os componentes que conferem pesam mais da metade
+// do total comparado, o que tolera a troca de um componente secundário (disco ou placa de rede)
+func (m FingerprintMatch) OK() bool {
+	return m.Compared == 0 || m.Matched*2 > m.Compared
+}
+
+var (
+	hostFingerprint     Fingerprint
+	hostFingerprintOnce sync.Once
+)
+
+// CurrentFingerprint retorna a impressão digital desta máquina, coletada uma vez por execução
+func CurrentFingerprint() Fingerprint {
+	hostFingerprintOnce.Do(func() {
+		hostFingerprint = collectFingerprint()
+		if hostFingerprint.MachineID == "" && hostFingerprint.DiskSerial == "" && len(hostFingerprint.MACs) == 0 {
+			logger.Warn("Nenhum atributo da máquina disponível para a impressão digital")
+		}
+	})
+	return hostFingerprint
+}
+
+// collectFingerprint lê os atributos da máquina (machineID e diskSerial dependem do sistema)
+func collectFingerprint() Fingerprint {
+	fp := Fingerprint{Version: fingerprintVersion}
+	if id, err := machineID(); err != nil {
+		logger.Debug("Identificador da máquina indisponível", "error", err)
+	} else {
+		fp.MachineID = hashComponent("machine_id", id)
+	}
+	if serial, err := diskSerial(); err != nil {
+		logger.Debug("Número de série do disco indisponível", "error", err)
+	} else {
+		fp.DiskSerial = hashComponent("disk_serial", serial)
+	}
+	for _, mac := range hardwareMACs() {
+		fp.MACs = append(fp.MACs, hashComponent("mac", mac))
+	}
+	sort.Strings(fp.MACs)
+	return fp
+}
+
+// hashComponent resume um atributo da máquina (SHA-256 truncado, separado por tipo)
+func hashComponent(kind, value string) string {
+	sum := sha256.Sum256([]byte("go-desktop-app fingerprint v1:" + kind + ":" + strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(sum[:16])
+}
+
+// hardwareMACs retorna os endereços MAC das interfaces físicas. Ficam de fora loopback,
+// interfaces sem endereço e endereços administrados localmente (virtuais ou aleatórios).
+func hardwareMACs() []string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		logger.Debug("Interfaces de rede indisponíveis", "error", err)
+		return nil
+	}
+	var macs []string
+	for _, iface := range interfaces {
+		mac := iface.HardwareAddr
+		if iface.Flags&net.FlagLoopback != 0 || len(mac) != 6 || mac[0]&0x02 != 0 {
+			continue
+		}
+		if mac.String() == "00:00:00:00:00:00" {
+			continue
+		}
+		macs = append(macs, mac.String())
+	}
+	return macs
+}
+
+// DeviceUUID deriva o UUID do dispositivo do componente mais estável disponível
+// (identificador da máquina, disco ou menor MAC). Sem nenhum, retorna ErrNoMachineAttributes:
+// um UUID aleatório mudaria a cada execução e não identificaria a máquina.
+func (fp Fingerprint) DeviceUUID() (string, error) {
+	var source string
+	switch {
+	case fp.MachineID != "":
+		source = "machine_id:" + fp.MachineID
+	case fp.DiskSerial != "":
+		source = "disk_serial:" + fp.DiskSerial
+	case len(fp.MACs) > 0:
+		source = "mac:" + fp.MACs[0]
+	default:
+		return "", ErrNoMachineAttributes
+	}
+	return uuid.NewSHA1(deviceNamespace, []byte(source)).String(), nil
+}
+
+// Compare confere a impressão armazenada (fp) com a atual. Só contam os componentes
+// presentes nas duas; os MACs conferem se houver ao menos um endereço em comum.
+func (fp Fingerprint) Compare(current Fingerprint) FingerprintMatch {
+	var m FingerprintMatch
+	compare := func(name string, weight int, stored, actual string) {
+		if stored == "" || actual == "" {
+			return
+		}
+		m.Compared += weight
+		if stored == actual {
+			m.Matched += weight
+		} else {
+			m.Changed = append(m.Changed, name)
+		}
+	}
+	compare("machine_id", weightMachineID, fp.MachineID, current.MachineID)
+	compare("disk_serial", weightDiskSerial, fp.DiskSerial, current.DiskSerial)
+
+	if len(fp.MACs) > 0 && len(current.MACs) > 0 {
+		m.Compared += weightMACs
+		if sharesAny(fp.MACs, current.MACs) {
+			m.Matched += weightMACs
+		} else {
+			m.Changed = append(m.Changed, "macs")
+		}
+	}
+	return m
+}
+
+// sharesAny informa se as listas têm ao menos um elemento em comum
+func sharesAny(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String serializa a impressão digital para o banco de dados
+func (fp Fingerprint) String() string {
+	data, _ := json.Marshal(fp)
+	return string(data)
+}
+
+// ParseFingerprint lê uma impressão digital gravada por Fingerprint.String
+func ParseFingerprint(value string) (Fingerprint, error) {
+	var fp Fingerprint
+	if err := json.Unmarshal([]byte(value), &fp); err != nil {
+		return fp, fmt.Errorf("impressão digital inválida: %v", err)
+	}
+	if fp.Version != fingerprintVersion {
+		return fp, fmt.Errorf("versão %d da impressão digital não suportada", fp.Version)
+	}
+	return fp, nil
+}
+
+// CheckHost confere se a impressão digital gravada com a licença corresponde a esta
+// máquina. Uma licença sem impressão gravada retorna ErrFingerprintMissing: a impressão
+// só é gravada de novo depois que o servidor de licenças aceita o token (checkServerLicense).
+func CheckHost(storedFingerprint string) error {
+	if storedFingerprint == "" {
+		return ErrFingerprintMissing
+	}
+	stored, err := ParseFingerprint(storedFingerprint)
+	if err != nil {
+		return err
+	}
+	if m := stored.Compare(CurrentFingerprint()); !m.OK() {
+		return fmt.Errorf("%w (componentes alterados: %s)", ErrHostMismatch, strings.Join(m.Changed, ", "))
+	}
+	return nil
+}
+
+// MachineDeviceUUID retorna o UUID do dispositivo desta máquina: o da licença do servidor
+// ou da licença offline armazenada, ou, sem nenhuma, o derivado da impressão digital
+func MachineDeviceUUID(store database.LicenseStore) (string, error) {
+	info, err := store.Get()
+	if err != nil {
+		return "", err
+	}
+	if info != nil && info.DeviceUUID != "" {
+		return info.DeviceUUID, nil
+	}
+	record, err := store.GetOfflineLicense()
+	if err != nil {
+		return "", err
+	}
+	if record != nil {
+		return record.DeviceUUID, nil
+	}
+	return CurrentFingerprint().DeviceUUID()
+}
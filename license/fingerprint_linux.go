@@ -0,0 +1,61 @@
+package license
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// machineID lê o identificador da instalação gerado pelo systemd (ou pelo D-Bus)
+func machineID() (string, error) {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			if id := strings.TrimSpace(string(data)); id != "" {
+				return id, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("machine-id não encontrado")
+}
+
+// virtualBlockPrefixes são os dispositivos de bloco sem disco físico por trás
+var virtualBlockPrefixes = []string{"loop", "ram", "zram", "dm-", "md", "sr", "nbd"}
+
+// diskSerial retorna o número de série do primeiro disco fixo em /sys/block (em ordem alfabética)
+func diskSerial() (string, error) {
+	devices, err := filepath.Glob("/sys/block/*")
+	if err != nil {
+		return "", err
+	}
+	for _, device := range devices {
+		if isVirtualBlockDevice(filepath.Base(device)) || readSysValue(filepath.Join(device, "removable")) == "1" {
+			continue
+		}
+		for _, name := range []string{"device/serial", "serial", "device/wwid", "wwid"} {
+			if serial := readSysValue(filepath.Join(device, name)); serial != "" {
+				return serial, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("nenhum disco com número de série em /sys/block")
+}
+
+// isVirtualBlockDevice informa se o dispositivo de bloco é virtual
+func isVirtualBlockDevice(name string) bool {
+	for _, prefix := range virtualBlockPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readSysValue lê um atributo do sysfs (vazio se não existir)
+func readSysValue(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
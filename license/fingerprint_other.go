@@ -0,0 +1,15 @@
+//go:build !windows && !linux
+
+package license
+
+import "fmt"
+
+// machineID não tem fonte nos demais sistemas: a impressão usa apenas os MACs
+func machineID() (string, error) {
+	return "", fmt.Errorf("identificador da máquina não suportado neste sistema")
+}
+
+// diskSerial não tem fonte nos demais sistemas: a impressão usa os MACs
+func diskSerial() (string, error) {
+	return "", fmt.Errorf("número de série do disco não suportado neste sistema")
+}
@@ -0,0 +1,121 @@
+package license
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFingerprintCompare(t *testing.T) {
+	stored := Fingerprint{Version: fingerprintVersion, MachineID: "m1", DiskSerial: "d1", MACs: []string{"a", "b"}}
+
+	tests := []struct {
+		name    string
+		stored  Fingerprint
+		current Fingerprint
+		ok      bool
+		changed []string
+	}{
+		{"mesma máquina", stored, stored, true, nil},
+		{"disco trocado", stored, Fingerprint{MachineID: "m1", DiskSerial: "d2", MACs: []string{"a", "b"}}, true, []string{"disk_serial"}},
+		{"placa de rede trocada", stored, Fingerprint{MachineID: "m1", DiskSerial: "d1", MACs: []string{"c"}}, true, []string{"macs"}},
+		{"um MAC em comum basta", stored, Fingerprint{MachineID: "m1", DiskSerial: "d1", MACs: []string{"b", "c"}}, true, nil},
+		{"disco e rede trocados", stored, Fingerprint{MachineID: "m1", DiskSerial: "d2", MACs: []string{"c"}}, false, []string{"disk_serial", "macs"}},
+		{"outra máquina", stored, Fingerprint{MachineID: "m2", DiskSerial: "d1", MACs: []string{"a"}}, false, []string{"machine_id"}},
+		{"só o MAC disponível e diferente", Fingerprint{MACs: []string{"a"}}, Fingerprint{MACs: []string{"b"}}, false, []string{"macs"}},
+		{"componentes ausentes não contam", Fingerprint{MachineID: "m1"}, Fingerprint{MachineID: "m1", DiskSerial: "d9"}, true, nil},
+		{"nada em comum para comparar", Fingerprint{MachineID: "m1"}, Fingerprint{DiskSerial: "d1"}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.stored.Compare(tt.current)
+			if m.OK() != tt.ok {
+				t.Errorf("Compare = %+v, OK %v; esperado %v", m, m.OK(), tt.ok)
+			}
+			if len(m.Changed) != len(tt.changed) {
+				t.Fatalf("Changed = %v, esperado %v", m.Changed, tt.changed)
+			}
+			for i := range tt.changed {
+				if m.Changed[i] != tt.changed[i] {
+					t.Errorf("Changed = %v, esperado %v", m.Changed, tt.changed)
+				}
+			}
+		})
+	}
+}
+
+func TestFingerprintDeviceUUID(t *testing.T) {
+	withMachine := Fingerprint{MachineID: "m1", DiskSerial: "d1", MACs: []string{"a"}}
+
+	tests := []struct {
+		name    string
+		fp      Fingerprint
+		same    *Fingerprint // impressão que deve gerar o mesmo UUID
+		wantErr error
+	}{
+		{"identificador da máquina tem prioridade", withMachine, &Fingerprint{MachineID: "m1", DiskSerial: "d2"}, nil},
+		{"sem identificador usa o disco", Fingerprint{DiskSerial: "d1", MACs: []string{"a"}}, &Fingerprint{DiskSerial: "d1", MACs: []string{"b"}}, nil},
+		{"sem disco usa o menor MAC", Fingerprint{MACs: []string{"a", "b"}}, &Fingerprint{MACs: []string{"a"}}, nil},
+		{"sem atributos falha", Fingerprint{Version: fingerprintVersion}, nil, ErrNoMachineAttributes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fp.DeviceUUID()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeviceUUID erro = %v, esperado %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if got != "" {
+					t.Errorf("DeviceUUID = %q com erro, esperado vazio", got)
+				}
+				return
+			}
+			again, _ := tt.fp.DeviceUUID()
+			if again != got {
+				t.Errorf("DeviceUUID não é determinístico: %q != %q", got, again)
+			}
+			if same, _ := tt.same.DeviceUUID(); same != got {
+				t.Errorf("DeviceUUID de %+v = %q, esperado %q", *tt.same, same, got)
+			}
+		})
+	}
+
+	a, _ := Fingerprint{MachineID: "m1"}.DeviceUUID()
+	b, _ := Fingerprint{DiskSerial: "m1"}.DeviceUUID()
+	if a == b {
+		t.Error("componentes diferentes com o mesmo valor geraram o mesmo UUID")
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	other := Fingerprint{Version: fingerprintVersion, MachineID: "outra", DiskSerial: "outro", MACs: []string{"outro"}}
+
+	type checkHostCase struct {
+		name    string
+		stored  string
+		wantErr error
+		anyErr  bool
+	}
+	tests := []checkHostCase{
+		{"impressão ausente", "", ErrFingerprintMissing, true},
+		{"impressão inválida", "{", nil, true},
+		{"versão não suportada", `{"version":99}`, nil, true},
+	}
+	// Só há o que comparar se a máquina do teste tiver algum atributo
+	if _, err := CurrentFingerprint().DeviceUUID(); err == nil {
+		tests = append(tests,
+			checkHostCase{"esta máquina", CurrentFingerprint().String(), nil, false},
+			checkHostCase{"outra máquina", other.String(), ErrHostMismatch, true},
+		)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckHost(tt.stored)
+			if (err != nil) != tt.anyErr {
+				t.Fatalf("CheckHost erro = %v, esperado erro: %v", err, tt.anyErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckHost erro = %v, esperado %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package license
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// machineID lê o MachineGuid gerado na instalação do Windows
+func machineID() (string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Cryptography`,
+		registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return "", err
+	}
+	defer key.Close()
+
+	guid, _, err := key.GetStringValue("MachineGuid")
+	if err != nil {
+		return "", err
+	}
+	return guid, nil
+}
+
+// diskSerial retorna o número de série do volume do sistema (definido na formatação)
+func diskSerial() (string, error) {
+	drive := os.Getenv("SystemDrive")
+	if drive == "" {
+		drive = "C:"
+	}
+	root, err := windows.UTF16PtrFromString(drive + `\`)
+	if err != nil {
+		return "", err
+	}
+
+	var serial uint32
+	if err := windows.GetVolumeInformation(root, nil, 0, &serial, nil, nil, nil, 0); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08X", serial), nil
+}
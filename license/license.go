@@ -3,21 +3,52 @@ package license
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"time"
 
 	"go-desktop-app/database"
+	"go-desktop-app/events"
+	"go-desktop-app/logging"
+	"go-desktop-app/metrics"
+)
+
+// logger registra as verificações de licença
+var logger = logging.Component("license")
 
-	"github.com/google/uuid"
+// Origens do estado da licença (events.License.Source)
+const (
+	SourceServer  = "server"  // token verificado no servidor de licenças
+	SourceOffline = "offline" // arquivo de licença offline assinado
 )
 
+// maxVerifyResponseBytes limita o corpo lido da resposta do servidor de licenças
+const maxVerifyResponseBytes = 1 << 20
+
+// ServerError indica que o servidor de licenças respondeu sem decidir sobre o token
+// (5xx, 429 ou outro status fora de 2xx e 4xx). A verificação é tratada como servidor
+// inacessível: vale a tolerância offline e a licença não é desativada.
+type ServerError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("servidor de licenças respondeu %s", e.Status)
+}
+
 // LicenseClient representa o cliente da API de licenciamento
 type LicenseClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	// RequestID é repassado ao servidor de licenças no header X-Request-ID
+	RequestID string
+	// Store guarda o token e o UUID do dispositivo
+	Store database.LicenseStore
+	// Policy define o tratamento do servidor inacessível (simulação ou tolerância offline)
+	Policy Policy
 }
 
 // VerifyTokenRequest representa a requisição de verificação de token
@@ -43,24 +74,30 @@ type VerifyTokenResponse struct {
 		Email string `json:"email"`
 	} `json:"employer"`
 	Error string `json:"error,omitempty"`
+	// StatusCode é o status HTTP devolvido pelo servidor (0 na resposta simulada)
+	StatusCode int `json:"-"`
 }
 
-// NewLicenseClient cria uma nova instância do cliente de licenciamento
-func NewLicenseClient(baseURL string) *LicenseClient {
+// NewLicenseClient cria uma nova instância do cliente de licenciamento sobre o store informado
+func NewLicenseClient(baseURL string, store database.LicenseStore) *LicenseClient {
 	return &LicenseClient{
 		BaseURL: baseURL,
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		Store:  store,
+		Policy: CurrentPolicy(),
 	}
 }
 
-// GenerateDeviceUUID gera um UUID único para a máquina
-func GenerateDeviceUUID() string {
-	return uuid.New().String()
+// GenerateDeviceUUID retorna o UUID desta máquina, derivado da impressão digital do
+// hardware: apagar o banco não muda a identidade e o UUID não vale em outra máquina
+func GenerateDeviceUUID() (string, error) {
+	return CurrentFingerprint().DeviceUUID()
 }
 
-// VerifyToken verifica se o token é válido na API de licenciamento
+// VerifyToken verifica se o token é válido na API de licenciamento.
+// Só respostas 2xx e 4xx (exceto 429) são a decisão do servidor; as demais retornam *ServerError.
 func (c *LicenseClient) VerifyToken(token, deviceUUID string) (*VerifyTokenResponse, error) {
 	// Prepara a requisição
 	reqBody := VerifyTokenRequest{
@@ -82,6 +119,9 @@ func (c *LicenseClient) VerifyToken(token, deviceUUID string) (*VerifyTokenRespo
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	if c.RequestID != "" {
+		req.Header.Set("X-Request-ID", c.RequestID)
+	}
 
 	// Executa a requisição
 	resp, err := c.HTTPClient.Do(req)
@@ -90,8 +130,14 @@ func (c *LicenseClient) VerifyToken(token, deviceUUID string) (*VerifyTokenRespo
 	}
 	defer resp.Body.Close()
 
+	if !isServerDecision(resp.StatusCode) {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxVerifyResponseBytes))
+		logger.Warn("Servidor de licenças indisponível", "status", resp.StatusCode, logging.RequestIDKey, c.RequestID)
+		return nil, &ServerError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
 	// Lê a resposta
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxVerifyResponseBytes))
 	if err != nil {
 		return nil, fmt.Errorf("erro ao ler resposta: %v", err)
 	}
@@ -102,92 +148,439 @@ func (c *LicenseClient) VerifyToken(token, deviceUUID string) (*VerifyTokenRespo
 		return nil, fmt.Errorf("erro ao decodificar resposta: %v", err)
 	}
 
+	response.StatusCode = resp.StatusCode
+
 	// Se a API retornou erro HTTP, mas conseguimos decodificar, retorna a resposta
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("API retornou status %d: %s", resp.StatusCode, response.Message)
+		logger.Warn("API de licenças retornou erro",
+			"status", resp.StatusCode, "message", response.Message, logging.RequestIDKey, c.RequestID)
 	}
 
 	return &response, nil
 }
 
-// CheckLicense verifica a licença usando as informações armazenadas
+// isServerDecision informa se o status HTTP é uma resposta do servidor sobre o token:
+// 2xx aceita ou recusa, 4xx recusa (429 é limite de requisições, não decisão)
+func isServerDecision(statusCode int) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return false
+	}
+	return statusCode >= 200 && statusCode < 300 || statusCode >= 400 && statusCode < 500
+}
+
+// CheckLicense verifica a licença usando as informações armazenadas: o token no servidor de
+// licenças e, se não houver licença válida do servidor, a licença offline instalada
 func (c *LicenseClient) CheckLicense() (bool, error) {
 	// Recupera as informações de licença do banco
-	info, err := database.GetLicenseInfo()
+	info, err := c.Store.Get()
 	if err != nil {
 		return false, fmt.Errorf("erro ao recuperar informações de licença: %v", err)
 	}
 
+	var serverErr error
 	if info == nil || info.Token == "" || info.DeviceUUID == "" {
-		return false, fmt.Errorf("informações de licença não encontradas")
+		info = nil
+		serverErr = fmt.Errorf("informações de licença não encontradas")
+	} else if valid, err := c.checkServerLicense(info); valid {
+		return true, nil
+	} else {
+		serverErr = err
 	}
 
-	// Verifica o token na API (com fallback)
+	// Sem licença válida do servidor, vale a licença offline instalada
+	lic, err := InstalledOfflineLicense(c.Store)
+	if lic == nil && err == nil {
+		return false, serverErr
+	}
+	if valid, err := c.checkOfflineLicense(lic, err); valid || info == nil {
+		return valid, err
+	}
+	return false, serverErr
+}
+
+// checkServerLicense verifica o token armazenado no servidor de licenças
+func (c *LicenseClient) checkServerLicense(info *database.LicenseInfo) (bool, error) {
+	// Uma licença sem impressão digital (anterior a ela ou com a coluna apagada) só volta a
+	// valer nesta máquina quando o servidor aceita o token; até lá não há tolerância offline
+	adopt := info.Fingerprint == ""
+	if !adopt {
+		if err := c.checkHost(info); err != nil {
+			return false, err
+		}
+	}
+
+	// Verifica o token na API (simulada apenas no modo de desenvolvimento)
 	response, err := c.VerifyTokenWithFallback(info.Token, info.DeviceUUID)
 	if err != nil {
-		log.Printf("Erro ao verificar token: %v", err)
-		return false, err
+		return c.checkOffline(info, err)
 	}
+	resetBackoff()
+	recordEvent(c.Store, c.responseEvent(database.LicenseEventVerify, info.DeviceUUID, response))
 
-	// Atualiza o timestamp da última verificação
-	if err := database.UpdateLastCheck(); err != nil {
-		log.Printf("Erro ao atualizar última verificação: %v", err)
+	// Atualiza o timestamp da última verificação (e da última validação, se o token foi aceito)
+	if response.Valid {
+		if adopt {
+			c.adoptFingerprint(info)
+		}
+		err = c.Store.MarkVerified()
+	} else {
+		err = c.Store.UpdateLastCheck()
+	}
+	if err != nil {
+		logger.Warn("Erro ao atualizar última verificação", "error", err)
 	}
 
 	// Atualiza o status ativo baseado na resposta
-	if err := database.UpdateActiveStatus(response.Valid); err != nil {
-		log.Printf("Erro ao atualizar status ativo: %v", err)
+	if err := c.Store.UpdateActiveStatus(response.Valid); err != nil {
+		logger.Warn("Erro ao atualizar status ativo", "error", err)
+	} else if info.IsActive != response.Valid {
+		c.recordTransition(info.DeviceUUID, response)
 	}
 
+	publishChange(c.Store, c.RequestID)
+
 	if !response.Valid {
-		log.Printf("Token inválido: %s", response.Message)
-		if response.Error != "" {
-			log.Printf("Erro da API: %s", response.Error)
-		}
+		metrics.LicenseChecks.Inc("invalid")
+		logger.Warn("Token inválido", "message", response.Message, "api_error", response.Error,
+			logging.RequestIDKey, c.RequestID)
 		return false, fmt.Errorf("licença inválida: %s", response.Message)
 	}
 
-	log.Printf("Licença válida para máquina %s", response.Machine.DeviceUUID)
+	metrics.LicenseChecks.Inc("valid")
+	metrics.LicenseLastSuccess.Set(float64(time.Now().Unix()))
+	logger.Info("Licença válida", "device_uuid", response.Machine.DeviceUUID, logging.RequestIDKey, c.RequestID)
 	return true, nil
 }
 
+// adoptFingerprint grava a impressão atual numa licença sem impressão digital, depois que o
+// servidor de licenças aceitou o token
+func (c *LicenseClient) adoptFingerprint(info *database.LicenseInfo) {
+	if err := c.Store.UpdateFingerprint(CurrentFingerprint().String()); err != nil {
+		logger.Warn("Erro ao gravar impressão digital da máquina", "error", err)
+		return
+	}
+	logger.Info("Impressão digital da máquina registrada na licença", "device_uuid", info.DeviceUUID,
+		logging.RequestIDKey, c.RequestID)
+}
+
+// checkHost confere se a licença foi registrada nesta máquina. Uma licença de outra máquina
+// (banco copiado) é desativada sem consultar o servidor.
+func (c *LicenseClient) checkHost(info *database.LicenseInfo) error {
+	hostErr := CheckHost(info.Fingerprint)
+	if hostErr == nil {
+		return nil
+	}
+
+	metrics.LicenseChecks.Inc("invalid")
+	logger.Error("Licença armazenada não corresponde a esta máquina", "device_uuid", info.DeviceUUID,
+		"error", hostErr, logging.RequestIDKey, c.RequestID)
+	recordEvent(c.Store, database.LicenseEvent{Type: database.LicenseEventVerify, DeviceUUID: info.DeviceUUID,
+		Result: database.LicenseResultInvalid, Error: hostErr.Error(), RequestID: c.RequestID})
+	if info.IsActive {
+		if err := c.Store.UpdateActiveStatus(false); err != nil {
+			logger.Warn("Erro ao atualizar status ativo", "error", err)
+		} else {
+			recordEvent(c.Store, database.LicenseEvent{Type: database.LicenseEventDeactivated, DeviceUUID: info.DeviceUUID,
+				Message: "licença registrada em outra máquina", Error: hostErr.Error(), RequestID: c.RequestID})
+		}
+	}
+	publishChange(c.Store, c.RequestID)
+	return fmt.Errorf("licença inválida: %v", hostErr)
+}
+
+// checkOfflineLicense registra a verificação da licença offline instalada (lic e err
+// de InstalledOfflineLicense)
+func (c *LicenseClient) checkOfflineLicense(lic *OfflineLicense, licErr error) (bool, error) {
+	event := database.LicenseEvent{Type: database.LicenseEventVerify, RequestID: c.RequestID}
+	if lic != nil {
+		event.DeviceUUID = lic.DeviceUUID
+	}
+
+	if licErr != nil {
+		metrics.LicenseChecks.Inc("invalid")
+		logger.Warn("Licença offline inválida", "error", licErr, logging.RequestIDKey, c.RequestID)
+		event.Result = database.LicenseResultInvalid
+		event.Error = licErr.Error()
+		recordEvent(c.Store, event)
+		publishChange(c.Store, c.RequestID)
+		return false, fmt.Errorf("licença inválida: %v", licErr)
+	}
+
+	metrics.LicenseChecks.Inc("valid")
+	metrics.LicenseLastSuccess.Set(float64(time.Now().Unix()))
+	logger.Info("Licença offline válida", "license_id", lic.LicenseID, "device_uuid", lic.DeviceUUID,
+		"expires_at", lic.ExpiresAt.Format(time.RFC3339), logging.RequestIDKey, c.RequestID)
+	event.Result = database.LicenseResultValid
+	event.Message = offlineMessage(lic)
+	recordEvent(c.Store, event)
+	publishChange(c.Store, c.RequestID)
+	return true, nil
+}
+
+// checkOffline aplica a tolerância offline quando o servidor de licenças não pôde ser consultado:
+// a licença ativa continua válida até Policy.OfflineGrace após a última resposta válida
+// do servidor e passa a inativa depois disso
+func (c *LicenseClient) checkOffline(info *database.LicenseInfo, verifyErr error) (bool, error) {
+	if err := c.Store.UpdateLastCheck(); err != nil {
+		logger.Warn("Erro ao atualizar última verificação", "error", err)
+	}
+	noteUnreachable()
+	event := database.LicenseEvent{Type: database.LicenseEventVerify, DeviceUUID: info.DeviceUUID,
+		Result: database.LicenseResultError, Error: verifyErr.Error(), RequestID: c.RequestID}
+	var serverErr *ServerError
+	if errors.As(verifyErr, &serverErr) {
+		event.HTTPStatus = serverErr.StatusCode
+	}
+
+	graceUntil, verified := c.Policy.OfflineGraceUntil(info)
+	// Sem impressão digital, a licença depende da resposta do servidor
+	missingFingerprint := info.Fingerprint == ""
+	if missingFingerprint {
+		verified = false
+	}
+	if info.IsActive && verified && time.Now().Before(graceUntil) {
+		metrics.LicenseChecks.Inc("offline")
+		logger.Warn("Servidor de licenças indisponível, licença mantida no período de tolerância offline",
+			"error", verifyErr, "grace_until", graceUntil.Format(time.RFC3339), logging.RequestIDKey, c.RequestID)
+		event.Message = fmt.Sprintf("servidor indisponível; licença válida offline até %s", graceUntil.Format(time.RFC3339))
+		recordEvent(c.Store, event)
+		publishChange(c.Store, c.RequestID)
+		return true, nil
+	}
+
+	metrics.LicenseChecks.Inc("error")
+	logger.Error("Erro ao verificar token", "error", verifyErr, logging.RequestIDKey, c.RequestID)
+	if !info.IsActive {
+		recordEvent(c.Store, event)
+		publishChange(c.Store, c.RequestID)
+		return false, verifyErr
+	}
+
+	message := "servidor indisponível e período de tolerância offline expirado"
+	if missingFingerprint {
+		message = "servidor indisponível e licença sem impressão digital da máquina"
+	} else if verified {
+		message = fmt.Sprintf("%s em %s", message, graceUntil.Format(time.RFC3339))
+	}
+	event.Message = message
+	recordEvent(c.Store, event)
+
+	if err := c.Store.UpdateActiveStatus(false); err != nil {
+		logger.Warn("Erro ao atualizar status ativo", "error", err)
+	} else {
+		recordEvent(c.Store, database.LicenseEvent{Type: database.LicenseEventDeactivated, DeviceUUID: info.DeviceUUID,
+			Message: message, Error: verifyErr.Error(), RequestID: c.RequestID})
+	}
+	publishChange(c.Store, c.RequestID)
+	return false, fmt.Errorf("licença inválida: %s: %v", message, verifyErr)
+}
+
 // SetupLicense configura uma nova licença com o token fornecido
 func (c *LicenseClient) SetupLicense(token string) error {
 	// Gera um novo UUID para a máquina se não existir
-	info, err := database.GetLicenseInfo()
+	info, err := c.Store.Get()
 	if err != nil {
 		return fmt.Errorf("erro ao verificar informações existentes: %v", err)
 	}
 
+	// Um UUID gravado só é reaproveitado se a impressão digital confere com esta máquina
 	var deviceUUID string
-	if info != nil && info.DeviceUUID != "" {
+	if info != nil && info.DeviceUUID != "" && CheckHost(info.Fingerprint) == nil {
 		// Usa o UUID existente
 		deviceUUID = info.DeviceUUID
+	} else if record, err := c.Store.GetOfflineLicense(); err == nil && record != nil && CheckHost(record.Fingerprint) == nil {
+		// Usa o UUID da licença offline instalada
+		deviceUUID = record.DeviceUUID
 	} else {
-		// Gera um novo UUID
-		deviceUUID = GenerateDeviceUUID()
+		// Deriva o UUID do hardware (uma licença copiada de outra máquina é substituída)
+		if deviceUUID, err = GenerateDeviceUUID(); err != nil {
+			return fmt.Errorf("erro ao identificar o dispositivo: %v", err)
+		}
 	}
 
-	// Verifica se o token é válido (com fallback)
+	// Verifica se o token é válido (a configuração exige o servidor, exceto no modo de desenvolvimento)
 	response, err := c.VerifyTokenWithFallback(token, deviceUUID)
 	if err != nil {
+		recordEvent(c.Store, database.LicenseEvent{Type: database.LicenseEventSetup, DeviceUUID: deviceUUID,
+			Result: database.LicenseResultError, Error: err.Error(), RequestID: c.RequestID})
 		return fmt.Errorf("erro ao verificar token: %v", err)
 	}
+	resetBackoff()
+	recordEvent(c.Store, c.responseEvent(database.LicenseEventSetup, deviceUUID, response))
 
 	if !response.Valid {
 		return fmt.Errorf("token inválido: %s", response.Message)
 	}
 
 	// Salva as informações no banco
-	if err := database.SaveLicenseInfo(token, deviceUUID); err != nil {
+	if err := c.Store.Save(token, deviceUUID); err != nil {
 		return fmt.Errorf("erro ao salvar informações de licença: %v", err)
 	}
+	// O servidor aceitou o token: inicia o período de tolerância sem o servidor
+	if err := c.Store.MarkVerified(); err != nil {
+		logger.Warn("Erro ao atualizar última validação", "error", err)
+	}
+	if err := c.Store.UpdateFingerprint(CurrentFingerprint().String()); err != nil {
+		logger.Warn("Erro ao gravar impressão digital da máquina", "error", err)
+	}
+	if info == nil || !info.IsActive {
+		c.recordTransition(deviceUUID, response)
+	}
 
-	log.Printf("Licença configurada com sucesso para máquina %s", deviceUUID)
+	logger.Info("Licença configurada com sucesso", "device_uuid", deviceUUID, logging.RequestIDKey, c.RequestID)
+	publishChange(c.Store, c.RequestID)
 	return nil
 }
 
-// SimulateAPIResponse simula uma resposta da API quando ela não estiver disponível
+// ClearLicense remove as informações de licença armazenadas e publica LicenseChanged
+func ClearLicense(store database.LicenseStore, requestID string) error {
+	// O UUID removido fica registrado no histórico
+	deviceUUID := ""
+	if info, err := store.Get(); err == nil && info != nil {
+		deviceUUID = info.DeviceUUID
+	}
+
+	if err := store.Clear(); err != nil {
+		return err
+	}
+	recordEvent(store, database.LicenseEvent{Type: database.LicenseEventCleared, DeviceUUID: deviceUUID, RequestID: requestID})
+	resetBackoff()
+
+	logger.Info("Licença removida", logging.RequestIDKey, requestID)
+	publishChange(store, requestID)
+	return nil
+}
+
+// CurrentStatus retorna o estado da licença armazenada, sem o token. Sem licença válida
+// do servidor, o estado é o da licença offline instalada (se houver).
+func CurrentStatus(store database.LicenseStore) events.License {
+	status := serverStatus(store)
+	if status.IsValid {
+		return status
+	}
+
+	lic, err := InstalledOfflineLicense(store)
+	if lic == nil && err == nil {
+		return status
+	}
+	if err != nil && status.HasLicense {
+		// Mantém o estado da licença do servidor
+		return status
+	}
+
+	status = events.License{HasLicense: true, Source: SourceOffline}
+	if lic != nil {
+		status.DeviceUUID = lic.DeviceUUID
+		status.ExpiresAt = lic.ExpiresAt.Format(time.RFC3339)
+	}
+	if next, ok := NextCheck(nil); ok {
+		status.NextCheck = next.Format(time.RFC3339)
+	}
+	switch {
+	case errors.Is(err, ErrLicenseExpired):
+		status.Message = "Licença offline expirada"
+		status.Error = err.Error()
+	case err != nil:
+		status.Message = "Licença offline inválida"
+		status.Error = err.Error()
+	default:
+		status.IsValid = true
+		status.Message = "Licença offline ativa"
+	}
+	return status
+}
+
+// serverStatus retorna o estado da licença do servidor de licenças armazenada
+func serverStatus(store database.LicenseStore) events.License {
+	status := events.License{Message: "Licença não configurada"}
+	hasLicense, err := store.HasLicense()
+	if err != nil {
+		logger.Error("Erro ao verificar licença", "error", err)
+		return status
+	}
+	if !hasLicense {
+		return status
+	}
+
+	status.HasLicense = true
+	info, err := store.Get()
+	if err != nil || info == nil {
+		status.Message = "Erro ao recuperar informações de licença"
+		if err != nil {
+			status.Error = err.Error()
+		}
+		return status
+	}
+
+	status.Source = SourceServer
+	status.IsValid = info.IsActive
+	status.DeviceUUID = info.DeviceUUID
+	status.LastCheck = info.LastCheck
+	status.LastVerifiedAt = info.LastVerifiedAt
+	status.Message = "Licença inativa"
+	if err := CheckHost(info.Fingerprint); err != nil {
+		status.IsValid = false
+		status.Message = "Licença registrada em outra máquina"
+		if errors.Is(err, ErrFingerprintMissing) {
+			status.Message = "Licença aguardando confirmação do servidor de licenças"
+		}
+		status.Error = err.Error()
+	} else if info.IsActive {
+		status.Message = "Licença ativa"
+		if graceUntil, ok := CurrentPolicy().OfflineGraceUntil(info); ok {
+			status.OfflineGraceUntil = graceUntil.Format(time.RFC3339)
+		}
+	}
+	if next, ok := NextCheck(info); ok {
+		status.NextCheck = next.Format(time.RFC3339)
+	}
+	return status
+}
+
+// responseEvent monta a entrada do histórico com o resultado devolvido pelo servidor de licenças
+func (c *LicenseClient) responseEvent(eventType, deviceUUID string, response *VerifyTokenResponse) database.LicenseEvent {
+	result := database.LicenseResultInvalid
+	if response.Valid {
+		result = database.LicenseResultValid
+	}
+	return database.LicenseEvent{
+		Type:       eventType,
+		DeviceUUID: deviceUUID,
+		Result:     result,
+		Message:    response.Message,
+		HTTPStatus: response.StatusCode,
+		Error:      response.Error,
+		RequestID:  c.RequestID,
+	}
+}
+
+// recordTransition registra a ativação ou desativação da licença com a resposta que a causou
+func (c *LicenseClient) recordTransition(deviceUUID string, response *VerifyTokenResponse) {
+	eventType := database.LicenseEventDeactivated
+	if response.Valid {
+		eventType = database.LicenseEventActivated
+	}
+	event := c.responseEvent(eventType, deviceUUID, response)
+	event.Result = ""
+	recordEvent(c.Store, event)
+}
+
+// recordEvent grava uma entrada no histórico da licença; uma falha é apenas registrada no log
+func recordEvent(store database.LicenseStore, event database.LicenseEvent) {
+	if err := store.RecordEvent(event); err != nil {
+		logger.Warn("Erro ao gravar histórico da licença", "type", event.Type, "error", err,
+			logging.RequestIDKey, event.RequestID)
+	}
+}
+
+// publishChange publica LicenseChanged com o estado atual da licença e faz o verificador
+// periódico recalcular a próxima verificação
+func publishChange(store database.LicenseStore, requestID string) {
+	wakeVerifier()
+	events.Publish(events.Event{Type: events.LicenseChanged, RequestID: requestID, Data: CurrentStatus(store)})
+}
+
+// SimulateAPIResponse simula uma resposta válida da API (usada apenas no modo de desenvolvimento)
 func (c *LicenseClient) SimulateAPIResponse(token, deviceUUID string) *VerifyTokenResponse {
 	// Simula uma resposta válida para desenvolvimento
 	return &VerifyTokenResponse{
@@ -218,13 +611,17 @@ func (c *LicenseClient) SimulateAPIResponse(token, deviceUUID string) *VerifyTok
 	}
 }
 
-// VerifyTokenWithFallback verifica o token com fallback para simulação
+// VerifyTokenWithFallback verifica o token na API. Se a API não responder, devolve o erro,
+// exceto no modo de desenvolvimento (Policy.DevelopmentMode), em que simula uma resposta válida.
 func (c *LicenseClient) VerifyTokenWithFallback(token, deviceUUID string) (*VerifyTokenResponse, error) {
 	// Tenta verificar com a API real primeiro
 	response, err := c.VerifyToken(token, deviceUUID)
 	if err != nil {
-		log.Printf("API não disponível, usando simulação: %v", err)
-		// Se a API não estiver disponível, simula uma resposta
+		if !c.Policy.DevelopmentMode {
+			return nil, err
+		}
+		logger.Warn("API não disponível, usando simulação (modo de desenvolvimento)", "error", err,
+			logging.RequestIDKey, c.RequestID)
 		return c.SimulateAPIResponse(token, deviceUUID), nil
 	}
 
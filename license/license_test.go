@@ -0,0 +1,240 @@
+package license
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-desktop-app/database"
+)
+
+// Respostas do servidor de licenças de teste
+const (
+	serverAccepts     = "accepts"
+	serverRejects     = "rejects"
+	serverDown        = "down"
+	serverUnavailable = "unavailable" // 503 com corpo de recusa, que não é decisão do servidor
+	serverThrottled   = "throttled"   // 429
+)
+
+// testClient cria um cliente sobre store apontando para um servidor de licenças que
+// aceita, recusa, não responde ou responde sem decidir (503 e 429)
+func testClient(t *testing.T, store database.LicenseStore, behavior string) *LicenseClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req VerifyTokenRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		response := VerifyTokenResponse{Valid: behavior == serverAccepts, Message: "Token is valid"}
+		response.Machine.DeviceUUID = req.DeviceUUID
+		switch behavior {
+		case serverRejects:
+			response.Message = "Machine revoked"
+			w.WriteHeader(http.StatusForbidden)
+		case serverUnavailable:
+			response.Message = "Service unavailable"
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case serverThrottled:
+			response.Message = "Too many requests"
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	if behavior == serverDown {
+		server.Close()
+	}
+
+	client := NewLicenseClient(server.URL, store)
+	client.HTTPClient.Timeout = 5 * time.Second
+	client.Policy = Policy{ServerURL: server.URL, OfflineGrace: 72 * time.Hour}
+	return client
+}
+
+func TestSetupLicense(t *testing.T) {
+	if _, err := CurrentFingerprint().DeviceUUID(); err != nil {
+		t.Skipf("máquina do teste sem atributos para a impressão digital: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		behavior string
+		ok       bool
+	}{
+		{"servidor aceita", serverAccepts, true},
+		{"servidor recusa", serverRejects, false},
+		{"servidor inacessível", serverDown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := database.NewMemoryLicenseStore()
+			err := testClient(t, store, tt.behavior).SetupLicense("token-teste")
+			if (err == nil) != tt.ok {
+				t.Fatalf("SetupLicense erro = %v, esperado sucesso: %v", err, tt.ok)
+			}
+
+			info, _ := store.Get()
+			if !tt.ok {
+				if info != nil {
+					t.Errorf("licença gravada sem aceite do servidor: %+v", info)
+				}
+				return
+			}
+			if info == nil || info.LastVerifiedAt == "" || CheckHost(info.Fingerprint) != nil {
+				t.Errorf("licença depois da configuração = %+v", info)
+			}
+		})
+	}
+}
+
+func TestSaveDoesNotMarkVerified(t *testing.T) {
+	store := database.NewMemoryLicenseStore()
+	if err := store.Save("token-teste", "device-1"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	info, _ := store.Get()
+	if info.LastVerifiedAt != "" {
+		t.Errorf("Save gravou last_verified_at = %q", info.LastVerifiedAt)
+	}
+	if _, ok := CurrentPolicy().OfflineGraceUntil(info); ok {
+		t.Error("licença nunca validada pelo servidor recebeu tolerância offline")
+	}
+}
+
+func TestCheckLicenseMissingFingerprint(t *testing.T) {
+	if _, err := CurrentFingerprint().DeviceUUID(); err != nil {
+		t.Skipf("máquina do teste sem atributos para a impressão digital: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		behavior string
+		valid    bool
+		adopted  bool
+	}{
+		{"servidor aceita e a impressão é gravada", serverAccepts, true, true},
+		{"servidor recusa", serverRejects, false, false},
+		// A tolerância offline não vale sem impressão, mesmo com validação recente
+		{"servidor inacessível", serverDown, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := database.NewMemoryLicenseStore()
+			if err := store.Save("token-teste", "device-1"); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if err := store.MarkVerified(); err != nil {
+				t.Fatalf("MarkVerified: %v", err)
+			}
+			if status := CurrentStatus(store); status.IsValid {
+				t.Fatalf("licença sem impressão digital aparece como válida: %+v", status)
+			}
+
+			valid, err := testClient(t, store, tt.behavior).CheckLicense()
+			if valid != tt.valid {
+				t.Fatalf("CheckLicense = %v, %v; esperado %v", valid, err, tt.valid)
+			}
+			info, _ := store.Get()
+			if adopted := info.Fingerprint != ""; adopted != tt.adopted {
+				t.Errorf("impressão gravada = %v, esperado %v", adopted, tt.adopted)
+			}
+			if status := CurrentStatus(store); status.IsValid != tt.valid {
+				t.Errorf("CurrentStatus = %+v, esperado válida: %v", status, tt.valid)
+			}
+		})
+	}
+}
+
+func TestCheckLicenseOtherMachine(t *testing.T) {
+	store := database.NewMemoryLicenseStore()
+	if err := store.Save("token-teste", "device-1"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	other := Fingerprint{Version: fingerprintVersion, MachineID: "outra", DiskSerial: "outro", MACs: []string{"outro"}}
+	if err := store.UpdateFingerprint(other.String()); err != nil {
+		t.Fatalf("UpdateFingerprint: %v", err)
+	}
+	if _, err := CurrentFingerprint().DeviceUUID(); err != nil {
+		t.Skipf("máquina do teste sem atributos para a impressão digital: %v", err)
+	}
+
+	// O servidor aceitaria o token, mas a licença copiada é recusada antes de consultá-lo
+	valid, err := testClient(t, store, serverAccepts).CheckLicense()
+	if valid || err == nil {
+		t.Fatalf("CheckLicense = %v, %v; esperado inválida", valid, err)
+	}
+	info, _ := store.Get()
+	if info.IsActive || info.Fingerprint != other.String() {
+		t.Errorf("licença de outra máquina depois da verificação = %+v", info)
+	}
+}
+
+func TestCheckLicenseServerWithoutDecision(t *testing.T) {
+	fingerprint := CurrentFingerprint()
+	if _, err := fingerprint.DeviceUUID(); err != nil {
+		t.Skipf("máquina do teste sem atributos para a impressão digital: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		behavior string
+		status   int
+	}{
+		{"503", serverUnavailable, http.StatusServiceUnavailable},
+		{"429", serverThrottled, http.StatusTooManyRequests},
+		{"inacessível", serverDown, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := database.NewMemoryLicenseStore()
+			store.Save("token-teste", "device-1")
+			store.UpdateFingerprint(fingerprint.String())
+			store.MarkVerified()
+			store.UpdateActiveStatus(true)
+
+			// Dentro da tolerância offline a licença continua válida e ativa
+			valid, err := testClient(t, store, tt.behavior).CheckLicense()
+			if !valid || err != nil {
+				t.Fatalf("CheckLicense = %v, %v; esperado válida pela tolerância offline", valid, err)
+			}
+			if info, _ := store.Get(); !info.IsActive {
+				t.Error("licença desativada sem decisão do servidor")
+			}
+
+			history, _, _ := store.History(database.LicenseEventFilter{Limit: 1})
+			if len(history) != 1 || history[0].Result != database.LicenseResultError || history[0].HTTPStatus != tt.status {
+				t.Errorf("histórico = %+v, esperado erro com status %d", history, tt.status)
+			}
+		})
+	}
+}
+
+func TestVerifyTokenServerError(t *testing.T) {
+	store := database.NewMemoryLicenseStore()
+	tests := []struct {
+		behavior string
+		decision bool
+	}{
+		{serverAccepts, true},
+		{serverRejects, true},
+		{serverUnavailable, false},
+		{serverThrottled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.behavior, func(t *testing.T) {
+			response, err := testClient(t, store, tt.behavior).VerifyToken("token-teste", "device-1")
+			if tt.decision {
+				if err != nil || response == nil {
+					t.Fatalf("VerifyToken = %v, %v; esperado a resposta do servidor", response, err)
+				}
+				return
+			}
+			var serverErr *ServerError
+			if !errors.As(err, &serverErr) || response != nil {
+				t.Errorf("VerifyToken = %v, %v; esperado *ServerError", response, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-desktop-app/database"
+	"go-desktop-app/logging"
+)
+
+// offlinePublicKey é a chave pública Ed25519 (base64) do emissor das licenças offline.
+// A chave privada correspondente fica apenas com o emissor. Pode ser trocada na compilação:
+// -ldflags "-X go-desktop-app/license.offlinePublicKey=<base64>"
+var offlinePublicKey = "VFm2bn3ubzvtKw7+EPqpHXVeTM2fH5zK3hvIrde/WrI="
+
+// offlineLicenseVersion é a versão do formato de OfflineLicense aceita por esta aplicação
+const offlineLicenseVersion = 1
+
+var (
+	// ErrInvalidSignature indica um arquivo de licença alterado ou assinado por outra chave
+	ErrInvalidSignature = errors.New("assinatura da licença offline inválida")
+	// ErrLicenseExpired indica uma licença offline com a data de expiração vencida
+	ErrLicenseExpired = errors.New("licença offline expirada")
+)
+
+var (
+	publicKey      ed25519.PublicKey
+	publicKeyMutex sync.RWMutex
+)
+
+// setOfflinePublicKey troca a chave pública usada para conferir as licenças offline. Só os
+// testes a usam: em produção vale apenas a chave embarcada no executável.
+func setOfflinePublicKey(key ed25519.PublicKey) {
+	publicKeyMutex.Lock()
+	defer publicKeyMutex.Unlock()
+	publicKey = key
+}
+
+// offlineKey retorna a chave definida por setOfflinePublicKey ou, na falta dela, a embarcada
+func offlineKey() (ed25519.PublicKey, error) {
+	publicKeyMutex.RLock()
+	defer publicKeyMutex.RUnlock()
+	if publicKey != nil {
+		return publicKey, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(offlinePublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("chave pública de licenças offline inválida")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// SignedLicense é o arquivo de licença offline: o JSON de OfflineLicense em base64 e a
+// assinatura Ed25519 desses bytes, feita com a chave privada do emissor
+type SignedLicense struct {
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// Employer identifica a empresa dona da licença offline
+type Employer struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// OfflineLicense é o conteúdo assinado de uma licença offline
+type OfflineLicense struct {
+	Version    int       `json:"version"`
+	LicenseID  string    `json:"license_id"`
+	DeviceUUID string    `json:"device_uuid"`
+	Employer   Employer  `json:"employer"`
+	Features   []string  `json:"features"`
+	IssuedAt   time.Time `json:"issued_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// HasFeature informa se a licença inclui o recurso informado
+func (l *OfflineLicense) HasFeature(feature string) bool {
+	for _, f := range l.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLicenseFile lê o conteúdo de um arquivo de licença offline
+func ParseLicenseFile(data []byte) (SignedLicense, error) {
+	var file SignedLicense
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("arquivo de licença inválido: %v", err)
+	}
+	if file.Payload == "" || file.Signature == "" {
+		return file, fmt.Errorf("arquivo de licença inválido: payload e signature são obrigatórios")
+	}
+	return file, nil
+}
+
+// VerifyOfflineLicense confere a assinatura e o conteúdo da licença. Uma licença com
+// assinatura válida mas vencida em now é devolvida junto com ErrLicenseExpired.
+func VerifyOfflineLicense(file SignedLicense, now time.Time) (*OfflineLicense, error) {
+	key, err := offlineKey()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := base64.StdEncoding.DecodeString(file.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("payload da licença não está em base64: %v", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(file.Signature)
+	if err != nil || !ed25519.Verify(key, payload, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var lic OfflineLicense
+	if err := json.Unmarshal(payload, &lic); err != nil {
+		return nil, fmt.Errorf("conteúdo da licença inválido: %v", err)
+	}
+	switch {
+	case lic.Version != offlineLicenseVersion:
+		return nil, fmt.Errorf("versão %d da licença offline não suportada", lic.Version)
+	case lic.DeviceUUID == "":
+		return nil, fmt.Errorf("licença offline sem device_uuid")
+	case lic.ExpiresAt.IsZero():
+		return nil, fmt.Errorf("licença offline sem expires_at")
+	}
+	if !now.Before(lic.ExpiresAt) {
+		return &lic, fmt.Errorf("%w em %s", ErrLicenseExpired, lic.ExpiresAt.Format(time.RFC3339))
+	}
+	return &lic, nil
+}
+
+// ImportLicense confere o arquivo de licença offline e o instala no store. A licença deve
+// ser do dispositivo desta máquina (MachineDeviceUUID).
+func ImportLicense(store database.LicenseStore, file SignedLicense, requestID string) (*OfflineLicense, error) {
+	event := database.LicenseEvent{Type: database.LicenseEventImported, RequestID: requestID}
+
+	lic, err := VerifyOfflineLicense(file, time.Now())
+	if lic != nil {
+		event.DeviceUUID = lic.DeviceUUID
+	}
+	if err == nil {
+		err = checkDeviceUUID(store, lic.DeviceUUID)
+	}
+	if err != nil {
+		event.Result = database.LicenseResultInvalid
+		event.Error = err.Error()
+		recordEvent(store, event)
+		logger.Warn("Licença offline recusada", "error", err, logging.RequestIDKey, requestID)
+		return nil, err
+	}
+
+	wasValid := CurrentStatus(store).IsValid
+	content, err := json.Marshal(file)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar licença offline: %v", err)
+	}
+	record := database.OfflineLicenseRecord{DeviceUUID: lic.DeviceUUID, Content: string(content),
+		Fingerprint: CurrentFingerprint().String()}
+	if err := store.SaveOfflineLicense(record); err != nil {
+		return nil, err
+	}
+
+	event.Result = database.LicenseResultValid
+	event.Message = offlineMessage(lic)
+	recordEvent(store, event)
+	if !wasValid {
+		recordEvent(store, database.LicenseEvent{Type: database.LicenseEventActivated, DeviceUUID: lic.DeviceUUID,
+			Message: event.Message, RequestID: requestID})
+	}
+
+	logger.Info("Licença offline importada", "license_id", lic.LicenseID, "device_uuid", lic.DeviceUUID,
+		"expires_at", lic.ExpiresAt.Format(time.RFC3339), logging.RequestIDKey, requestID)
+	publishChange(store, requestID)
+	return lic, nil
+}
+
+// InstalledOfflineLicense retorna a licença offline instalada, conferida de novo (assinatura,
+// expiração e impressão digital da máquina). Retorna (nil, nil) se não houver; uma licença
+// vencida vem com ErrLicenseExpired e uma importada em outra máquina, com ErrHostMismatch.
+func InstalledOfflineLicense(store database.LicenseStore) (*OfflineLicense, error) {
+	record, err := store.GetOfflineLicense()
+	if err != nil || record == nil {
+		return nil, err
+	}
+	file, err := ParseLicenseFile([]byte(record.Content))
+	if err != nil {
+		return nil, err
+	}
+	lic, err := VerifyOfflineLicense(file, time.Now())
+	if err == nil && lic.DeviceUUID != record.DeviceUUID {
+		return nil, fmt.Errorf("licença offline armazenada não corresponde ao dispositivo %s", record.DeviceUUID)
+	}
+	if err == nil {
+		err = CheckHost(record.Fingerprint)
+	}
+	return lic, err
+}
+
+// checkDeviceUUID recusa uma licença emitida para outro dispositivo
+func checkDeviceUUID(store database.LicenseStore, deviceUUID string) error {
+	current, err := MachineDeviceUUID(store)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(current, deviceUUID) {
+		return fmt.Errorf("licença emitida para o dispositivo %s, mas este dispositivo é %s", deviceUUID, current)
+	}
+	return nil
+}
+
+// offlineMessage descreve a licença offline válida para o histórico e o status
+func offlineMessage(lic *OfflineLicense) string {
+	return fmt.Sprintf("licença offline %s de %s válida até %s", lic.LicenseID, lic.Employer.Name,
+		lic.ExpiresAt.Format(time.RFC3339))
+}
@@ -0,0 +1,185 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go-desktop-app/database"
+)
+
+// useTestKey troca a chave embarcada por uma gerada para o teste e devolve a chave privada
+func useTestKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+	setOfflinePublicKey(public)
+	t.Cleanup(func() { setOfflinePublicKey(nil) })
+	return private
+}
+
+// signLicense monta o arquivo de licença offline assinado com key
+func signLicense(t *testing.T, key ed25519.PrivateKey, lic OfflineLicense) SignedLicense {
+	t.Helper()
+	payload, err := json.Marshal(lic)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	return SignedLicense{
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}
+}
+
+// testOfflineLicense retorna uma licença do dispositivo informado válida entre issued e expires
+func testOfflineLicense(deviceUUID string, issued, expires time.Time) OfflineLicense {
+	return OfflineLicense{
+		Version:    offlineLicenseVersion,
+		LicenseID:  "lic-1",
+		DeviceUUID: deviceUUID,
+		Employer:   Employer{ID: "emp-1", Name: "Empresa Teste"},
+		Features:   []string{"execute"},
+		IssuedAt:   issued,
+		ExpiresAt:  expires,
+	}
+}
+
+func TestVerifyOfflineLicense(t *testing.T) {
+	key := useTestKey(t)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	valid := testOfflineLicense("device-1", now.AddDate(0, -1, 0), now.AddDate(0, 1, 0))
+
+	tampered := signLicense(t, key, valid)
+	changed := valid
+	changed.ExpiresAt = now.AddDate(10, 0, 0)
+	changedPayload, _ := json.Marshal(changed)
+	tampered.Payload = base64.StdEncoding.EncodeToString(changedPayload)
+
+	wrongVersion := valid
+	wrongVersion.Version = 2
+	noDevice := valid
+	noDevice.DeviceUUID = ""
+	noExpiry := valid
+	noExpiry.ExpiresAt = time.Time{}
+
+	tests := []struct {
+		name    string
+		file    SignedLicense
+		now     time.Time
+		wantLic bool
+		wantErr error
+		anyErr  bool
+	}{
+		{"válida", signLicense(t, key, valid), now, true, nil, false},
+		{"vence no instante exato", signLicense(t, key, valid), valid.ExpiresAt, true, ErrLicenseExpired, true},
+		{"vencida", signLicense(t, key, valid), now.AddDate(0, 2, 0), true, ErrLicenseExpired, true},
+		{"assinada por outra chave", signLicense(t, otherKey, valid), now, false, ErrInvalidSignature, true},
+		{"conteúdo alterado", tampered, now, false, ErrInvalidSignature, true},
+		{"assinatura fora de base64", SignedLicense{Payload: tampered.Payload, Signature: "%%%"}, now, false, ErrInvalidSignature, true},
+		{"payload fora de base64", SignedLicense{Payload: "%%%", Signature: tampered.Signature}, now, false, nil, true},
+		{"versão não suportada", signLicense(t, key, wrongVersion), now, false, nil, true},
+		{"sem device_uuid", signLicense(t, key, noDevice), now, false, nil, true},
+		{"sem expires_at", signLicense(t, key, noExpiry), now, false, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lic, err := VerifyOfflineLicense(tt.file, tt.now)
+			if (err != nil) != tt.anyErr {
+				t.Fatalf("VerifyOfflineLicense erro = %v, esperado erro: %v", err, tt.anyErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyOfflineLicense erro = %v, esperado %v", err, tt.wantErr)
+			}
+			if (lic != nil) != tt.wantLic {
+				t.Errorf("VerifyOfflineLicense licença = %+v, esperada: %v", lic, tt.wantLic)
+			}
+			if lic != nil && (lic.LicenseID != valid.LicenseID || !lic.HasFeature("execute")) {
+				t.Errorf("VerifyOfflineLicense = %+v", lic)
+			}
+		})
+	}
+}
+
+func TestInstalledOfflineLicense(t *testing.T) {
+	deviceUUID, err := CurrentFingerprint().DeviceUUID()
+	if err != nil {
+		t.Skipf("máquina do teste sem atributos para a impressão digital: %v", err)
+	}
+	key := useTestKey(t)
+	now := time.Now()
+	other := Fingerprint{Version: fingerprintVersion, MachineID: "outra", DiskSerial: "outro", MACs: []string{"outro"}}
+
+	tests := []struct {
+		name        string
+		lic         OfflineLicense
+		fingerprint string
+		wantErr     error
+	}{
+		{"desta máquina", testOfflineLicense(deviceUUID, now.Add(-time.Hour), now.Add(time.Hour)), CurrentFingerprint().String(), nil},
+		{"vencida", testOfflineLicense(deviceUUID, now.Add(-2*time.Hour), now.Add(-time.Hour)), CurrentFingerprint().String(), ErrLicenseExpired},
+		{"importada em outra máquina", testOfflineLicense(deviceUUID, now.Add(-time.Hour), now.Add(time.Hour)), other.String(), ErrHostMismatch},
+		{"sem impressão digital", testOfflineLicense(deviceUUID, now.Add(-time.Hour), now.Add(time.Hour)), "", ErrFingerprintMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := database.NewMemoryLicenseStore()
+			content, _ := json.Marshal(signLicense(t, key, tt.lic))
+			record := database.OfflineLicenseRecord{DeviceUUID: deviceUUID, Content: string(content), Fingerprint: tt.fingerprint}
+			if err := store.SaveOfflineLicense(record); err != nil {
+				t.Fatalf("SaveOfflineLicense: %v", err)
+			}
+
+			lic, err := InstalledOfflineLicense(store)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InstalledOfflineLicense erro = %v, esperado %v", err, tt.wantErr)
+			}
+			if lic == nil {
+				t.Fatal("InstalledOfflineLicense não devolveu a licença")
+			}
+			if status := CurrentStatus(store); status.IsValid != (tt.wantErr == nil) || status.Source != SourceOffline {
+				t.Errorf("CurrentStatus = %+v", status)
+			}
+		})
+	}
+}
+
+func TestImportLicense(t *testing.T) {
+	deviceUUID, err := CurrentFingerprint().DeviceUUID()
+	if err != nil {
+		t.Skipf("máquina do teste sem atributos para a impressão digital: %v", err)
+	}
+	key := useTestKey(t)
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		deviceUUID string
+		ok         bool
+	}{
+		{"emitida para esta máquina", deviceUUID, true},
+		{"emitida para outro dispositivo", "00000000-0000-0000-0000-000000000001", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := database.NewMemoryLicenseStore()
+			file := signLicense(t, key, testOfflineLicense(tt.deviceUUID, now.Add(-time.Hour), now.Add(time.Hour)))
+			_, err := ImportLicense(store, file, "req-teste")
+			if (err == nil) != tt.ok {
+				t.Fatalf("ImportLicense erro = %v, esperado sucesso: %v", err, tt.ok)
+			}
+			record, _ := store.GetOfflineLicense()
+			if (record != nil) != tt.ok {
+				t.Fatalf("licença gravada = %+v, esperado gravar: %v", record, tt.ok)
+			}
+			if tt.ok && CheckHost(record.Fingerprint) != nil {
+				t.Errorf("impressão digital gravada não confere com esta máquina")
+			}
+		})
+	}
+}
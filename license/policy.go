@@ -0,0 +1,76 @@
+package license
+
+import (
+	"sync"
+	"time"
+
+	"go-desktop-app/database"
+)
+
+// DefaultServerURL é o servidor de licenças usado quando a política não define outro
+const DefaultServerURL = "http://localhost:8000"
+
+// maxClockSkew é a diferença de relógio tolerada ao comparar a última validação com o horário atual
+const maxClockSkew = 5 * time.Minute
+
+// Policy define o servidor de licenças e o comportamento da verificação quando ele não responde
+type Policy struct {
+	// ServerURL é o endereço do servidor de licenças
+	ServerURL string
+	// DevelopmentMode troca a falha de comunicação por uma resposta simulada válida
+	DevelopmentMode bool
+	// OfflineGrace é por quanto tempo, desde a última resposta válida do servidor,
+	// a licença continua válida sem conseguir verificá-la
+	OfflineGrace time.Duration
+}
+
+var (
+	policy      = Policy{ServerURL: DefaultServerURL, OfflineGrace: 72 * time.Hour}
+	policyMutex sync.RWMutex
+)
+
+// SetPolicy define a política usada pelos clientes criados a partir de agora
+func SetPolicy(p Policy) {
+	if p.ServerURL == "" {
+		p.ServerURL = DefaultServerURL
+	}
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+	policy = p
+}
+
+// CurrentPolicy retorna a política de verificação em vigor
+func CurrentPolicy() Policy {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+	return policy
+}
+
+// OfflineGraceUntil retorna até quando a licença continua válida sem o servidor de licenças.
+// Retorna false se a licença nunca foi validada pelo servidor ou se o horário da última
+// validação está no futuro (alterado no banco ou relógio adiantado na validação).
+func (p Policy) OfflineGraceUntil(info *database.LicenseInfo) (time.Time, bool) {
+	if info == nil || info.LastVerifiedAt == "" {
+		return time.Time{}, false
+	}
+	verifiedAt, err := parseTimestamp(info.LastVerifiedAt)
+	if err != nil {
+		logger.Warn("Horário da última validação inválido", "last_verified_at", info.LastVerifiedAt, "error", err)
+		return time.Time{}, false
+	}
+	if verifiedAt.After(time.Now().Add(maxClockSkew)) {
+		logger.Warn("Horário da última validação no futuro, tolerância sem o servidor ignorada",
+			"last_verified_at", info.LastVerifiedAt)
+		return time.Time{}, false
+	}
+	return verifiedAt.Add(p.OfflineGrace), true
+}
+
+// parseTimestamp interpreta os horários gravados em license_info (RFC 3339 ou o
+// formato de CURRENT_TIMESTAMP do SQLite, ambos em UTC)
+func parseTimestamp(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", value, time.UTC)
+}
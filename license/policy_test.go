@@ -0,0 +1,39 @@
+package license
+
+import (
+	"testing"
+	"time"
+
+	"go-desktop-app/database"
+)
+
+func TestOfflineGraceUntil(t *testing.T) {
+	policy := Policy{OfflineGrace: 72 * time.Hour}
+	past := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name string
+		info *database.LicenseInfo
+		want time.Time
+		ok   bool
+	}{
+		{"sem licença", nil, time.Time{}, false},
+		{"nunca validada", &database.LicenseInfo{}, time.Time{}, false},
+		{"RFC 3339", &database.LicenseInfo{LastVerifiedAt: past.Format(time.RFC3339)}, past.Add(72 * time.Hour), true},
+		{"formato do SQLite", &database.LicenseInfo{LastVerifiedAt: past.Format("2006-01-02 15:04:05")}, past.Add(72 * time.Hour), true},
+		{"relógio pouco adiantado", &database.LicenseInfo{LastVerifiedAt: time.Now().Add(time.Minute).UTC().Format(time.RFC3339)}, time.Time{}, true},
+		{"no futuro", &database.LicenseInfo{LastVerifiedAt: time.Now().AddDate(1, 0, 0).UTC().Format(time.RFC3339)}, time.Time{}, false},
+		{"inválida", &database.LicenseInfo{LastVerifiedAt: "ontem"}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := policy.OfflineGraceUntil(tt.info)
+			if ok != tt.ok {
+				t.Fatalf("OfflineGraceUntil ok = %v, esperado %v", ok, tt.ok)
+			}
+			if !tt.want.IsZero() && !got.Equal(tt.want) {
+				t.Errorf("OfflineGraceUntil = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package license
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go-desktop-app/database"
+	"go-desktop-app/logging"
+	"go-desktop-app/metrics"
+
+	"github.com/google/uuid"
+)
+
+// VerifierSchedule configura a verificação periódica feita por StartVerifier
+type VerifierSchedule struct {
+	// Interval é o intervalo entre verificações da licença (0 desativa a verificação periódica)
+	Interval time.Duration
+	// InitialBackoff é a espera antes de repetir uma verificação em que o servidor de licenças
+	// não respondeu; dobra a cada falha seguida até MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+var (
+	schedule = VerifierSchedule{Interval: 6 * time.Hour, InitialBackoff: 30 * time.Second, MaxBackoff: 30 * time.Minute}
+	// verifierStarted indica se StartVerifier foi chamado (sem ele não há próxima verificação)
+	verifierStarted bool
+	// lastRun é o horário da última verificação feita pelo verificador
+	lastRun time.Time
+	// failures conta as verificações seguidas sem resposta do servidor e retryAt é o
+	// horário da próxima tentativa
+	failures      int
+	retryAt       time.Time
+	verifierMutex sync.Mutex
+	verifierOnce  sync.Once
+	// verifierWake faz o verificador recalcular a próxima verificação
+	verifierWake = make(chan struct{}, 1)
+)
+
+// SetVerifierSchedule define o intervalo e a espera entre tentativas da verificação periódica
+func SetVerifierSchedule(s VerifierSchedule) {
+	verifierMutex.Lock()
+	schedule = s
+	verifierMutex.Unlock()
+	wakeVerifier()
+}
+
+// StartVerifier inicia a verificação periódica da licença armazenada em store. A licença é
+// verificada de novo um intervalo após a última verificação (imediatamente, se já venceu);
+// sem resposta do servidor, a nova tentativa segue a espera exponencial com variação aleatória.
+func StartVerifier(store database.LicenseStore) {
+	verifierOnce.Do(func() {
+		verifierMutex.Lock()
+		verifierStarted = true
+		verifierMutex.Unlock()
+		go runVerifier(store)
+	})
+}
+
+// NextCheck retorna quando o verificador fará a próxima verificação da licença do servidor
+// info (nil para a licença offline). Retorna false se a verificação periódica estiver desativada.
+func NextCheck(info *database.LicenseInfo) (time.Time, bool) {
+	verifierMutex.Lock()
+	defer verifierMutex.Unlock()
+	if !verifierStarted || schedule.Interval <= 0 {
+		return time.Time{}, false
+	}
+	if !retryAt.IsZero() {
+		return retryAt, true
+	}
+
+	last := lastRun
+	if info != nil && info.LastCheck != "" {
+		if t, err := parseTimestamp(info.LastCheck); err == nil && t.After(last) {
+			last = t
+		}
+	}
+	if last.IsZero() {
+		return time.Now(), true
+	}
+	return last.Add(schedule.Interval), true
+}
+
+// runVerifier aguarda a próxima verificação e a executa; as alterações da licença
+// (configuração, verificação manual, remoção) e da agenda antecipam o recálculo
+func runVerifier(store database.LicenseStore) {
+	for {
+		next, ok := scheduledCheck(store)
+		if !ok {
+			metrics.LicenseNextCheck.Set(0)
+			<-verifierWake
+			continue
+		}
+		metrics.LicenseNextCheck.Set(float64(next.Unix()))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			runScheduledCheck(store)
+			// A alteração publicada pela própria verificação já está considerada
+			select {
+			case <-verifierWake:
+			default:
+			}
+		case <-verifierWake:
+			timer.Stop()
+		}
+	}
+}
+
+// scheduledCheck retorna a próxima verificação da licença instalada (false se não houver
+// licença ou a verificação periódica estiver desativada)
+func scheduledCheck(store database.LicenseStore) (time.Time, bool) {
+	if !CurrentStatus(store).HasLicense {
+		return time.Time{}, false
+	}
+	info, err := store.Get()
+	if err != nil {
+		logger.Warn("Erro ao recuperar informações de licença", "error", err)
+		return time.Time{}, false
+	}
+	if info != nil && info.Token == "" {
+		info = nil
+	}
+	return NextCheck(info)
+}
+
+// runScheduledCheck verifica a licença como POST /api/license/verify; o resultado é
+// publicado em LicenseChanged por CheckLicense
+func runScheduledCheck(store database.LicenseStore) {
+	verifierMutex.Lock()
+	lastRun = time.Now()
+	verifierMutex.Unlock()
+
+	client := NewLicenseClient(CurrentPolicy().ServerURL, store)
+	client.RequestID = uuid.New().String()
+	logger.Debug("Verificação periódica da licença", logging.RequestIDKey, client.RequestID)
+	if valid, err := client.CheckLicense(); err != nil {
+		logger.Warn("Verificação periódica da licença falhou", "valid", valid, "error", err,
+			logging.RequestIDKey, client.RequestID)
+	}
+}
+
+// noteUnreachable registra uma verificação sem resposta do servidor de licenças e agenda
+// a nova tentativa
+func noteUnreachable() {
+	verifierMutex.Lock()
+	defer verifierMutex.Unlock()
+	failures++
+	wait := backoff(failures, schedule)
+	retryAt = time.Now().Add(wait)
+	logger.Info("Nova tentativa de verificação da licença agendada", "failures", failures,
+		"retry_at", retryAt.Format(time.RFC3339))
+}
+
+// resetBackoff encerra a espera entre tentativas (servidor respondeu ou licença removida)
+func resetBackoff() {
+	verifierMutex.Lock()
+	defer verifierMutex.Unlock()
+	failures = 0
+	retryAt = time.Time{}
+}
+
+// wakeVerifier faz o verificador recalcular a próxima verificação
+func wakeVerifier() {
+	select {
+	case verifierWake <- struct{}{}:
+	default:
+	}
+}
+
+// backoff retorna a espera antes da tentativa seguinte a attempts falhas: InitialBackoff
+// dobrando a cada falha até MaxBackoff, reduzida ao acaso em até metade para que várias
+// instâncias não consultem o servidor ao mesmo tempo
+func backoff(attempts int, s VerifierSchedule) time.Duration {
+	wait := s.InitialBackoff
+	for i := 1; i < attempts && wait < s.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if s.MaxBackoff > 0 && wait > s.MaxBackoff {
+		wait = s.MaxBackoff
+	}
+	if wait <= 0 {
+		wait = time.Second
+	}
+	half := wait / 2
+	return half + rand.N(wait-half+1)
+}
@@ -0,0 +1,37 @@
+package license
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	schedule := VerifierSchedule{Interval: time.Hour, InitialBackoff: 30 * time.Second, MaxBackoff: 30 * time.Minute}
+
+	tests := []struct {
+		name     string
+		attempts int
+		schedule VerifierSchedule
+		wait     time.Duration // espera antes da variação aleatória
+	}{
+		{"primeira falha", 1, schedule, 30 * time.Second},
+		{"segunda falha dobra", 2, schedule, time.Minute},
+		{"quinta falha", 5, schedule, 8 * time.Minute},
+		{"limitada pelo máximo", 7, schedule, 30 * time.Minute},
+		{"muitas falhas não estouram", 1000, schedule, 30 * time.Minute},
+		{"inicial acima do máximo", 1, VerifierSchedule{InitialBackoff: time.Hour, MaxBackoff: time.Minute}, time.Minute},
+		{"sem máximo a espera não cresce", 4, VerifierSchedule{InitialBackoff: 4 * time.Second}, 4 * time.Second},
+		{"sem espera configurada usa 1s", 3, VerifierSchedule{}, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A variação aleatória reduz a espera em até metade
+			for range 200 {
+				got := backoff(tt.attempts, tt.schedule)
+				if got < tt.wait/2 || got > tt.wait {
+					t.Fatalf("backoff(%d) = %v, esperado entre %v e %v", tt.attempts, got, tt.wait/2, tt.wait)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record é uma entrada de log já resolvida, entregue a cada sink
+type Record struct {
+	Time      time.Time
+	Level     slog.Level
+	Component string
+	RequestID string
+	Message   string
+	Attrs     []slog.Attr
+}
+
+// Sink recebe os registros do logger central
+type Sink interface {
+	Write(rec Record)
+}
+
+// SinkFunc adapta uma função a um Sink
+type SinkFunc func(rec Record)
+
+// Write chama a função
+func (f SinkFunc) Write(rec Record) {
+	f(rec)
+}
+
+// registeredSink associa um sink ao nível mínimo que ele recebe
+type registeredSink struct {
+	name     string
+	minLevel slog.Leveler
+	sink     Sink
+}
+
+var (
+	level      = new(slog.LevelVar)
+	sinks      []registeredSink
+	sinksMutex sync.RWMutex
+	root       = slog.New(&handler{})
+)
+
+func init() {
+	// Até a configuração da aplicação, os logs vão apenas para o console
+	AddSink("console", slog.LevelDebug, NewConsoleSink(os.Stderr))
+}
+
+// Logger retorna o logger central
+func Logger() *slog.Logger {
+	return root
+}
+
+// Component retorna um logger identificado pelo componente (api, core, license...)
+func Component(name string) *slog.Logger {
+	return root.With(slog.String(ComponentKey, name))
+}
+
+// Chaves de atributos tratadas de forma especial pelos sinks
+const (
+	ComponentKey = "component"
+	RequestIDKey = "request_id"
+)
+
+// SetLevel define o nível mínimo global
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// ParseLevel converte "debug", "info", "warn" ou "error" em slog.Level
+func ParseLevel(name string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("nível de log inválido: %s", name)
+	}
+	return l, nil
+}
+
+// LevelName retorna o nome do nível em minúsculas (debug, info, warn, error),
+// usado como tipo das entradas de log na interface web
+func LevelName(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "error"
+	case l >= slog.LevelWarn:
+		return "warn"
+	case l >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// AddSink registra (ou substitui, pelo nome) um sink com seu nível mínimo
+func AddSink(name string, minLevel slog.Leveler, sink Sink) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+
+	for i, s := range sinks {
+		if s.name == name {
+			sinks[i] = registeredSink{name: name, minLevel: minLevel, sink: sink}
+			return
+		}
+	}
+	sinks = append(sinks, registeredSink{name: name, minLevel: minLevel, sink: sink})
+}
+
+// RemoveSink remove um sink pelo nome
+func RemoveSink(name string) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+
+	for i, s := range sinks {
+		if s.name == name {
+			sinks = append(sinks[:i], sinks[i+1:]...)
+			return
+		}
+	}
+}
+
+// RedirectStandardLog envia o pacote log da biblioteca padrão para o logger central,
+// para que bibliotecas de terceiros também passem pelos sinks
+func RedirectStandardLog() {
+	slog.SetDefault(root)
+	log.SetFlags(0)
+}
+
+// Text formata a mensagem seguida dos atributos no formato chave=valor
+func (rec Record) Text() string {
+	var b strings.Builder
+	b.WriteString(rec.Message)
+	for _, attr := range rec.Attrs {
+		b.WriteByte(' ')
+		b.WriteString(attr.Key)
+		b.WriteByte('=')
+		value := attr.Value.String()
+		if strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		b.WriteString(value)
+	}
+	return b.String()
+}
+
+// handler é o slog.Handler que resolve os atributos e distribui para os sinks
+type handler struct {
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	rec := Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+	}
+
+	addAttr := func(attr slog.Attr) {
+		attr.Value = attr.Value.Resolve()
+		switch {
+		case len(h.groups) == 0 && attr.Key == ComponentKey:
+			rec.Component = attr.Value.String()
+		case len(h.groups) == 0 && attr.Key == RequestIDKey:
+			rec.RequestID = attr.Value.String()
+		default:
+			rec.Attrs = append(rec.Attrs, flattenAttr(h.groups, attr)...)
+		}
+	}
+
+	for _, attr := range h.attrs {
+		addAttr(attr)
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		addAttr(attr)
+		return true
+	})
+
+	sinksMutex.RLock()
+	defer sinksMutex.RUnlock()
+	for _, s := range sinks {
+		if rec.Level >= s.minLevel.Level() {
+			s.sink.Write(rec)
+		}
+	}
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := &handler{groups: h.groups}
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), qualifyAttrs(h.groups, attrs)...)
+	return clone
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &handler{attrs: h.attrs, groups: append(append([]string{}, h.groups...), name)}
+}
+
+// qualifyAttrs prefixa as chaves com os grupos ativos
+func qualifyAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	if len(groups) == 0 {
+		return attrs
+	}
+	result := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		result = append(result, flattenAttr(groups, attr)...)
+	}
+	return result
+}
+
+// flattenAttr achata grupos em chaves com ponto (grupo.chave)
+func flattenAttr(groups []string, attr slog.Attr) []slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		var result []slog.Attr
+		nested := append(append([]string{}, groups...), attr.Key)
+		for _, a := range attr.Value.Group() {
+			result = append(result, flattenAttr(nested, a)...)
+		}
+		return result
+	}
+	if len(groups) > 0 {
+		attr.Key = strings.Join(groups, ".") + "." + attr.Key
+	}
+	return []slog.Attr{attr}
+}
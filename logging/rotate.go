@@ -0,0 +1,415 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeLayout é o carimbo de data/hora no nome dos arquivos rotacionados
+const backupTimeLayout = "2006-01-02T15-04-05.000"
+
+// ErrLogFileNotFound indica que o arquivo pedido não pertence ao diretório de logs
+var ErrLogFileNotFound = errors.New("arquivo de log não encontrado")
+
+// FileOptions configura o arquivo de log com rotação
+type FileOptions struct {
+	Directory  string
+	Name       string // nome do arquivo atual, ex.: go-desktop-app.log
+	MaxSizeMB  int    // rotaciona ao atingir o tamanho (0 desativa)
+	MaxAgeDays int    // remove arquivos rotacionados mais antigos (0 mantém)
+	MaxBackups int    // quantidade máxima de arquivos rotacionados (0 mantém todos)
+	Compress   bool   // compacta os arquivos rotacionados com gzip
+}
+
+// LogFile descreve um arquivo do diretório de logs
+type LogFile struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Current    bool      `json:"current"`
+	Compressed bool      `json:"compressed"`
+}
+
+// RotatingFile é um io.Writer sobre um arquivo que é rotacionado por tamanho.
+// Os arquivos rotacionados recebem a data/hora no nome, podem ser compactados
+// e são removidos conforme MaxBackups e MaxAgeDays.
+type RotatingFile struct {
+	opts  FileOptions
+	mutex sync.Mutex
+	file  *os.File
+	size  int64
+
+	// maintenanceMutex serializa compactação e limpeza
+	maintenanceMutex sync.Mutex
+	maintenance      sync.WaitGroup
+	// maintenanceDay é o dia (AAAA-MM-DD) da última limpeza iniciada
+	maintenanceDay string
+}
+
+// OpenRotatingFile abre (ou cria) o arquivo de log em modo append
+func OpenRotatingFile(opts FileOptions) (*RotatingFile, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("nome do arquivo de log não informado")
+	}
+	if err := os.MkdirAll(opts.Directory, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de logs %s: %v", opts.Directory, err)
+	}
+
+	f := &RotatingFile{opts: opts}
+	if err := f.openCurrent(); err != nil {
+		return nil, err
+	}
+
+	// Conclui a compactação e a limpeza de execuções anteriores
+	f.startMaintenance()
+
+	return f, nil
+}
+
+// Write grava os dados, rotacionando antes se o limite de tamanho for excedido
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	// A retenção por idade também é aplicada na primeira escrita de cada dia: um processo
+	// com pouco log não rotaciona e manteria os arquivos vencidos indefinidamente
+	if f.opts.MaxAgeDays > 0 && time.Now().Format("2006-01-02") != f.maintenanceDay {
+		f.startMaintenance()
+	}
+
+	maxSize := int64(f.opts.MaxSizeMB) * 1024 * 1024
+	if maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate força a rotação do arquivo atual
+func (f *RotatingFile) Rotate() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Close fecha o arquivo e aguarda a compactação em andamento
+func (f *RotatingFile) Close() error {
+	f.mutex.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mutex.Unlock()
+
+	f.maintenance.Wait()
+	return err
+}
+
+// Directory retorna o diretório dos arquivos de log
+func (f *RotatingFile) Directory() string {
+	return f.opts.Directory
+}
+
+// Files lista o arquivo atual e os rotacionados, do mais recente para o mais antigo
+func (f *RotatingFile) Files() ([]LogFile, error) {
+	entries, err := os.ReadDir(f.opts.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar diretório de logs: %v", err)
+	}
+
+	var files []LogFile
+	for _, entry := range entries {
+		name := entry.Name()
+		current := name == f.opts.Name
+		if entry.IsDir() || (!current && !f.isBackup(name)) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, LogFile{
+			Name:       name,
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+			Current:    current,
+			Compressed: strings.HasSuffix(name, ".gz"),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Current != files[j].Current {
+			return files[i].Current
+		}
+		return files[i].Name > files[j].Name
+	})
+	return files, nil
+}
+
+// Path retorna o caminho completo de um arquivo listado por Files.
+// Nomes fora do padrão de logs (inclusive com componentes de caminho) são recusados.
+func (f *RotatingFile) Path(name string) (string, error) {
+	if name != filepath.Base(name) || (name != f.opts.Name && !f.isBackup(name)) {
+		return "", ErrLogFileNotFound
+	}
+
+	path := filepath.Join(f.opts.Directory, name)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", ErrLogFileNotFound
+	}
+	return path, nil
+}
+
+// openCurrent abre o arquivo atual em modo append
+func (f *RotatingFile) openCurrent() error {
+	path := filepath.Join(f.opts.Directory, f.opts.Name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de log %s: %v", path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao ler arquivo de log %s: %v", path, err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate renomeia o arquivo atual e abre um novo; deve ser chamado com f.mutex
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	current := filepath.Join(f.opts.Directory, f.opts.Name)
+	backup := filepath.Join(f.opts.Directory, f.backupName(time.Now()))
+	if err := os.Rename(current, backup); err != nil {
+		// Sem renomear, continua no mesmo arquivo para não perder logs
+		if openErr := f.openCurrent(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("erro ao rotacionar arquivo de log: %v", err)
+	}
+
+	if err := f.openCurrent(); err != nil {
+		return err
+	}
+
+	f.startMaintenance()
+	return nil
+}
+
+// startMaintenance inicia a compactação e a limpeza em segundo plano; deve ser chamado
+// com f.mutex (ou antes de o arquivo ser compartilhado)
+func (f *RotatingFile) startMaintenance() {
+	f.maintenanceDay = time.Now().Format("2006-01-02")
+	f.maintenance.Add(1)
+	go f.runMaintenance()
+}
+
+// runMaintenance compacta os arquivos rotacionados e aplica a retenção
+func (f *RotatingFile) runMaintenance() {
+	defer f.maintenance.Done()
+
+	f.maintenanceMutex.Lock()
+	defer f.maintenanceMutex.Unlock()
+
+	if f.opts.Compress {
+		f.compressBackups()
+	}
+	f.removeOldBackups()
+}
+
+// compressBackups compacta com gzip os arquivos rotacionados ainda sem compactação.
+// Falhas vão para o stderr: registrá-las no logger escreveria neste mesmo arquivo.
+func (f *RotatingFile) compressBackups() {
+	entries, err := os.ReadDir(f.opts.Directory)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !f.isBackup(name) || strings.HasSuffix(name, ".gz") {
+			continue
+		}
+		if err := compressFile(filepath.Join(f.opts.Directory, name)); err != nil {
+			fmt.Fprintf(os.Stderr, "erro ao compactar log %s: %v\n", name, err)
+		}
+	}
+}
+
+// removeOldBackups remove os arquivos rotacionados além de MaxBackups ou mais antigos que MaxAgeDays
+func (f *RotatingFile) removeOldBackups() {
+	files, err := f.Files()
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Time{}
+	if f.opts.MaxAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -f.opts.MaxAgeDays)
+	}
+
+	kept := 0
+	for _, file := range files {
+		if file.Current {
+			continue
+		}
+
+		expired := !cutoff.IsZero() && file.ModifiedAt.Before(cutoff)
+		tooMany := f.opts.MaxBackups > 0 && kept >= f.opts.MaxBackups
+		if expired || tooMany {
+			os.Remove(filepath.Join(f.opts.Directory, file.Name))
+			continue
+		}
+		kept++
+	}
+}
+
+// backupName gera o nome do arquivo rotacionado: go-desktop-app-<data>.log
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.opts.Name)
+	base := strings.TrimSuffix(f.opts.Name, ext)
+	return base + "-" + t.Format(backupTimeLayout) + ext
+}
+
+// isBackup verifica se o nome segue o padrão dos arquivos rotacionados (com ou sem .gz)
+func (f *RotatingFile) isBackup(name string) bool {
+	ext := filepath.Ext(f.opts.Name)
+	base := strings.TrimSuffix(f.opts.Name, ext)
+
+	name = strings.TrimSuffix(name, ".gz")
+	stamp, ok := strings.CutPrefix(name, base+"-")
+	if !ok {
+		return false
+	}
+	stamp, ok = strings.CutSuffix(stamp, ext)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(backupTimeLayout, stamp)
+	return err == nil
+}
+
+// compressFile grava path.gz preservando a data de modificação e remove o original
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	gz.Name = filepath.Base(path)
+	gz.ModTime = info.ModTime()
+	if _, err = io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmpPath, path+".gz"); err != nil {
+		return err
+	}
+	os.Chtimes(path+".gz", info.ModTime(), info.ModTime())
+	src.Close()
+	return os.Remove(path)
+}
+
+var (
+	activeFile      *RotatingFile
+	activeFileMutex sync.Mutex
+)
+
+// EnableFileSink abre o arquivo de log com rotação e registra o sink "file" (JSON por linha).
+// Um arquivo aberto anteriormente é fechado.
+func EnableFileSink(opts FileOptions) (*RotatingFile, error) {
+	file, err := OpenRotatingFile(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	activeFileMutex.Lock()
+	previous := activeFile
+	activeFile = file
+	activeFileMutex.Unlock()
+
+	AddSink("file", slog.LevelDebug, NewJSONSink(file))
+	if previous != nil {
+		previous.Close()
+	}
+	return file, nil
+}
+
+// CloseFileSink remove o sink "file" e fecha o arquivo de log
+func CloseFileSink() error {
+	RemoveSink("file")
+
+	activeFileMutex.Lock()
+	file := activeFile
+	activeFile = nil
+	activeFileMutex.Unlock()
+
+	if file == nil {
+		return nil
+	}
+	return file.Close()
+}
+
+// ActiveLogFile retorna o arquivo de log em uso, ou nil se o sink de arquivo estiver desativado
+func ActiveLogFile() *RotatingFile {
+	activeFileMutex.Lock()
+	defer activeFileMutex.Unlock()
+	return activeFile
+}
@@ -0,0 +1,180 @@
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeBackup cria um arquivo rotacionado com a data no nome e a data de modificação informadas
+func writeBackup(t *testing.T, f *RotatingFile, stamp, modified time.Time) string {
+	t.Helper()
+	name := f.backupName(stamp)
+	path := filepath.Join(f.opts.Directory, name)
+	if err := os.WriteFile(path, []byte("antigo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func backupNames(t *testing.T, f *RotatingFile) []string {
+	t.Helper()
+	files, err := f.Files()
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		if !file.Current {
+			names = append(names, file.Name)
+		}
+	}
+	return names
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenRotatingFile(FileOptions{Directory: dir, Name: "app.log", MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer f.Close()
+
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+	for i := 0; i < 2; i++ {
+		if _, err := f.Write(chunk); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	f.maintenance.Wait()
+
+	backups := backupNames(t, f)
+	if len(backups) != 1 {
+		t.Fatalf("arquivos rotacionados = %v, esperado 1", backups)
+	}
+	info, err := os.Stat(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != int64(len(chunk)) {
+		t.Errorf("arquivo atual com %d bytes, esperado só a última escrita (%d)", info.Size(), len(chunk))
+	}
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		opts FileOptions
+		// idades em dias dos arquivos rotacionados, do mais recente para o mais antigo
+		ages []int
+		kept int
+	}{
+		{"sem limites", FileOptions{}, []int{1, 2, 3}, 3},
+		{"máximo de arquivos", FileOptions{MaxBackups: 2}, []int{1, 2, 3, 4}, 2},
+		{"idade máxima", FileOptions{MaxAgeDays: 7}, []int{1, 5, 10, 30}, 2},
+		{"ambos", FileOptions{MaxBackups: 1, MaxAgeDays: 7}, []int{1, 5, 10}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Directory = t.TempDir()
+			opts.Name = "app.log"
+			f := &RotatingFile{opts: opts}
+
+			var expected []string
+			for i, days := range tt.ages {
+				at := now.AddDate(0, 0, -days)
+				name := writeBackup(t, f, at, at)
+				if i < tt.kept {
+					expected = append(expected, name)
+				}
+			}
+
+			f.removeOldBackups()
+
+			if got := backupNames(t, f); strings.Join(got, ",") != strings.Join(expected, ",") {
+				t.Errorf("restaram %v, esperado %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenRotatingFile(FileOptions{Directory: dir, Name: "app.log", Compress: true})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+
+	if _, err := f.Write([]byte("linha rotacionada\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := f.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	// Close aguarda a compactação em andamento
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	backups := backupNames(t, f)
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".gz") {
+		t.Fatalf("arquivos rotacionados = %v, esperado um .gz", backups)
+	}
+
+	file, err := os.Open(filepath.Join(dir, backups[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if string(content) != "linha rotacionada\n" {
+		t.Errorf("conteúdo = %q", content)
+	}
+}
+
+func TestRotatingFilePath(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenRotatingFile(FileOptions{Directory: dir, Name: "app.log"})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer f.Close()
+	backup := writeBackup(t, f, time.Now(), time.Now())
+	os.WriteFile(filepath.Join(dir, "outro.txt"), nil, 0644)
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"app.log", true},
+		{backup, true},
+		{"outro.txt", false},
+		{"../app.log", false},
+		{"app-2024-01-01T00-00-00.000.log", false}, // no padrão, mas inexistente
+	}
+
+	for _, tt := range tests {
+		_, err := f.Path(tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("Path(%q) erro = %v, esperado aceito = %v", tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// consoleTimeLayout é o formato de data/hora das linhas de texto
+const consoleTimeLayout = "2006/01/02 15:04:05"
+
+// ConsoleSink escreve linhas de texto legíveis (usado para stderr e a janela nativa)
+type ConsoleSink struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// NewConsoleSink cria um sink de texto sobre o writer informado
+func NewConsoleSink(w io.Writer) *ConsoleSink {
+	return &ConsoleSink{w: w}
+}
+
+// Write escreve o registro como uma linha de texto
+func (s *ConsoleSink) Write(rec Record) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	fmt.Fprintln(s.w, FormatLine(rec))
+}
+
+// FormatLine formata o registro como "data NÍVEL [componente] mensagem chave=valor"
+func FormatLine(rec Record) string {
+	line := rec.Time.Format(consoleTimeLayout) + " " + fmt.Sprintf("%-5s", rec.Level.String())
+	if rec.Component != "" {
+		line += " [" + rec.Component + "]"
+	}
+	line += " " + rec.Text()
+	if rec.RequestID != "" {
+		line += " request_id=" + rec.RequestID
+	}
+	return line
+}
+
+// JSONSink escreve um objeto JSON por linha (usado para arquivos de log)
+type JSONSink struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// NewJSONSink cria um sink JSON sobre o writer informado
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+// Write escreve o registro como uma linha JSON
+func (s *JSONSink) Write(rec Record) {
+	data, err := json.Marshal(rec.Map())
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.w.Write(append(data, '\n'))
+}
+
+// Map converte o registro em um mapa com os atributos no nível raiz
+func (rec Record) Map() map[string]interface{} {
+	fields := make(map[string]interface{}, len(rec.Attrs)+5)
+	for _, attr := range rec.Attrs {
+		fields[attr.Key] = attr.Value.Any()
+		if err, ok := fields[attr.Key].(error); ok {
+			fields[attr.Key] = err.Error()
+		}
+	}
+	fields["time"] = rec.Time.Format(time.RFC3339Nano)
+	fields["level"] = LevelName(rec.Level)
+	fields["msg"] = rec.Message
+	if rec.Component != "" {
+		fields[ComponentKey] = rec.Component
+	}
+	if rec.RequestID != "" {
+		fields[RequestIDKey] = rec.RequestID
+	}
+	return fields
+}
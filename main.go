@@ -2,40 +2,35 @@ package main
 
 import (
 	"embed"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"go-desktop-app/api"
+	"go-desktop-app/bootstrap"
+	"go-desktop-app/config"
 	"go-desktop-app/database"
+	"go-desktop-app/license"
+	"go-desktop-app/logging"
 	"go-desktop-app/service"
 	"go-desktop-app/ui"
+	"go-desktop-app/webhooks"
 )
 
 //go:embed web/*
 var webFiles embed.FS
 
-// CustomLogWriter é um writer customizado que envia logs para a janela
-type CustomLogWriter struct{}
-
-func (w CustomLogWriter) Write(p []byte) (n int, err error) {
-	message := string(p)
-
-	// Envia para o log padrão
-	os.Stderr.Write(p)
-
-	// Envia para a janela de logs nativa
-	ui.AddGlobalLog(message)
-
-	// NÃO envia para a interface web aqui - apenas o middleware deve fazer isso
-	// para evitar duplicação de logs
-
-	return len(p), nil
-}
+// logger registra a inicialização da aplicação
+var logger = logging.Component("main")
 
 func main() {
 	// Verifica se foi passado algum argumento para gerenciar o serviço
@@ -81,6 +76,55 @@ func main() {
 			}
 			fmt.Printf("Status do serviço: %s\n", status)
 			return
+		case "migrate":
+			// Gerencia as versões do esquema do banco de dados
+			if err := runMigrate(os.Args[2:]); err != nil {
+				fmt.Printf("Erro na migração: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "backup":
+			// Cria ou lista os backups do banco de dados
+			if err := runBackup(os.Args[2:]); err != nil {
+				fmt.Printf("Erro no backup: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "restore":
+			// Restaura o banco de dados a partir de um backup
+			if err := runRestore(os.Args[2:]); err != nil {
+				fmt.Printf("Erro na restauração: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "check":
+			// Verifica a integridade do banco de dados e dos backups
+			if err := runCheck(os.Args[2:]); err != nil {
+				fmt.Printf("Erro na verificação: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "license-import":
+			// Instala um arquivo de licença offline assinado
+			if err := runLicenseImport(os.Args[2:]); err != nil {
+				fmt.Printf("Erro ao importar licença: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "device-id":
+			// Mostra o UUID e a impressão digital desta máquina
+			if err := runDeviceID(); err != nil {
+				fmt.Printf("Erro ao identificar a máquina: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "webhook-receiver":
+			// Receptor local de webhooks para testes
+			if err := runWebhookReceiver(os.Args[2:]); err != nil {
+				fmt.Printf("Erro no receptor de webhooks: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		case "service":
 			// Configura os arquivos web embarcados para o serviço
 			service.SetWebFiles(webFiles)
@@ -101,28 +145,33 @@ func runInteractiveMode() {
 	// Oculta o console no Windows
 	hideConsole()
 
-	// Configura o logger customizado
-	log.SetOutput(CustomLogWriter{})
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	// Configura o logger central: console, janela nativa e interface web
+	logging.RedirectStandardLog()
+	logging.AddSink("window", slog.LevelDebug, ui.LogSink())
+	logging.AddSink("web", slog.LevelDebug, api.LogSink())
 
-	log.Println("Iniciando Go Desktop App...")
+	logger.Info("Iniciando Go Desktop App")
 
-	// Inicializa o banco de dados
-	if err := database.InitDatabase(); err != nil {
-		log.Printf("Erro ao inicializar banco de dados: %v", err)
-		// Continua a execução mesmo com erro no banco
-	}
+	// Carrega as configurações (config.json), o arquivo de log e o banco de dados
+	bootstrap.LoadSettings()
+	bootstrap.EnableLogFile()
+	bootstrap.StartDatabase()
+
+	// Injeta o store de licença (sem banco, as operações de licença retornam erro)
+	store := database.Licenses()
+	api.SetLicenseStore(store)
+	ui.SetLicenseStore(store)
 
 	// Configura os arquivos web embarcados
 	api.SetWebFiles(webFiles)
 
 	// Inicia o servidor da API
-	log.Println("Iniciando servidor API...")
+	logger.Info("Iniciando servidor API")
 	go func() {
 		api.StartServer()
 	}()
 
-	log.Println("Configurando system tray...")
+	logger.Info("Configurando system tray")
 
 	// Abre automaticamente o navegador após um tempo
 	go func() {
@@ -135,6 +184,335 @@ func runInteractiveMode() {
 	ui.SetupTray()
 }
 
+// runMigrate executa os subcomandos de migração do banco de dados.
+// Uso: migrate status | migrate up [versão] | migrate down [quantidade]
+func runMigrate(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("uso: migrate status | up [versão] | down [quantidade]")
+	}
+
+	// Argumento numérico opcional: versão alvo (up) ou quantidade a reverter (down)
+	number := 0
+	if len(args) == 2 {
+		var err error
+		if number, err = strconv.Atoi(args[1]); err != nil || number < 1 {
+			return fmt.Errorf("argumento inválido: %s", args[1])
+		}
+	}
+
+	if err := config.LoadSettings(config.SettingsPath()); err != nil {
+		return err
+	}
+	if err := database.OpenDatabase(bootstrap.DatabaseOptions()); err != nil {
+		return err
+	}
+	fmt.Printf("Banco de dados: %s\n", database.Path())
+	defer database.CloseDatabase()
+
+	switch args[0] {
+	case "status":
+		states, err := database.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			status := "pendente"
+			if state.Applied {
+				status = "aplicada em " + state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-20s %s\n", state.Version, state.Name, status)
+		}
+		return nil
+	case "up":
+		applied, err := database.MigrateUp(number)
+		for _, migration := range applied {
+			fmt.Printf("Aplicada: %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Nenhuma migração pendente")
+		}
+		return err
+	case "down":
+		if number == 0 {
+			number = 1
+		}
+		reverted, err := database.MigrateDown(number)
+		for _, migration := range reverted {
+			fmt.Printf("Revertida: %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("Nenhuma migração aplicada")
+		}
+		return err
+	default:
+		return fmt.Errorf("subcomando desconhecido: %s (use status, up ou down)", args[0])
+	}
+}
+
+// runBackup cria um backup do banco de dados ou lista os existentes.
+// Uso: backup (no diretório de backups) | backup <arquivo> | backup list
+func runBackup(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("uso: backup [arquivo | list]")
+	}
+	if err := config.LoadSettings(config.SettingsPath()); err != nil {
+		return err
+	}
+	dir := config.BackupDirectory()
+
+	if len(args) == 1 && args[0] == "list" {
+		backups, err := database.ListBackups(dir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backups em %s:\n", dir)
+		for _, backup := range backups {
+			fmt.Printf("  %s  %10d bytes  %s\n", backup.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+				backup.Size, filepath.Base(backup.Path))
+		}
+		if len(backups) == 0 {
+			fmt.Println("  nenhum backup")
+		}
+		return nil
+	}
+
+	if err := database.OpenDatabase(bootstrap.DatabaseOptions()); err != nil {
+		return err
+	}
+	defer database.CloseDatabase()
+	fmt.Printf("Banco de dados: %s\n", database.Path())
+
+	if len(args) == 1 {
+		if err := database.BackupTo(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Backup criado: %s\n", args[0])
+		return nil
+	}
+
+	backup, err := database.CreateBackup(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backup criado: %s (%d bytes)\n", backup.Path, backup.Size)
+	if removed, err := database.PruneBackups(dir, config.GetSettings().Database.Backup.Keep); err != nil {
+		return err
+	} else if removed > 0 {
+		fmt.Printf("Backups antigos removidos: %d\n", removed)
+	}
+	return nil
+}
+
+// runRestore substitui o banco de dados por um backup conferido. A aplicação e o serviço
+// precisam estar parados. Uso: restore (backup válido mais recente) | restore <arquivo>
+func runRestore(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("uso: restore [arquivo]")
+	}
+	if err := config.LoadSettings(config.SettingsPath()); err != nil {
+		return err
+	}
+	options := bootstrap.DatabaseOptions()
+	fmt.Printf("Banco de dados: %s\n", options.Path)
+
+	if len(args) == 1 {
+		if err := database.RestoreBackup(options.Path, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Restaurado de: %s\n", args[0])
+	} else {
+		backup, err := database.RestoreLatestBackup(options.Path, config.BackupDirectory())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restaurado de: %s\n", backup.Path)
+	}
+
+	// Abre o banco restaurado para conferir e atualizar o esquema
+	if err := database.InitDatabase(options); err != nil {
+		return err
+	}
+	defer database.CloseDatabase()
+	fmt.Println("Banco de dados restaurado com sucesso")
+	return nil
+}
+
+// runLicenseImport confere e instala um arquivo de licença offline assinado.
+// Uso: license-import <arquivo>
+func runLicenseImport(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("uso: license-import <arquivo>")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	file, err := license.ParseLicenseFile(data)
+	if err != nil {
+		return err
+	}
+
+	if err := config.LoadSettings(config.SettingsPath()); err != nil {
+		return err
+	}
+	if err := database.InitDatabase(bootstrap.DatabaseOptions()); err != nil {
+		return err
+	}
+	defer database.CloseDatabase()
+
+	lic, err := license.ImportLicense(database.Licenses(), file, "")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Licença %s importada com sucesso\n", lic.LicenseID)
+	fmt.Printf("  Empresa:     %s\n", lic.Employer.Name)
+	fmt.Printf("  Dispositivo: %s\n", lic.DeviceUUID)
+	fmt.Printf("  Recursos:    %s\n", strings.Join(lic.Features, ", "))
+	fmt.Printf("  Expira em:   %s\n", lic.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// runDeviceID mostra o UUID do dispositivo (informado ao emissor de licenças offline),
+// os componentes da impressão digital e se a licença armazenada pertence a esta máquina
+func runDeviceID() error {
+	if err := config.LoadSettings(config.SettingsPath()); err != nil {
+		return err
+	}
+	if err := database.InitDatabase(bootstrap.DatabaseOptions()); err != nil {
+		return err
+	}
+	defer database.CloseDatabase()
+
+	store := database.Licenses()
+	deviceUUID, err := license.MachineDeviceUUID(store)
+	if err != nil {
+		return err
+	}
+	fingerprint := license.CurrentFingerprint()
+	fmt.Printf("UUID do dispositivo:       %s\n", deviceUUID)
+	if hardwareUUID, err := fingerprint.DeviceUUID(); err != nil {
+		fmt.Printf("UUID derivado do hardware: indisponível (%v)\n", err)
+	} else {
+		fmt.Printf("UUID derivado do hardware: %s\n", hardwareUUID)
+	}
+
+	available := func(value string) string {
+		if value == "" {
+			return "indisponível"
+		}
+		return "disponível"
+	}
+	fmt.Printf("  Identificador da máquina: %s\n", available(fingerprint.MachineID))
+	fmt.Printf("  Número de série do disco: %s\n", available(fingerprint.DiskSerial))
+	fmt.Printf("  Endereços MAC:            %d\n", len(fingerprint.MACs))
+
+	info, err := store.Get()
+	if err != nil {
+		return err
+	}
+	record, err := store.GetOfflineLicense()
+	if err != nil {
+		return err
+	}
+	if info != nil {
+		fmt.Printf("Licença do servidor:       %s\n", hostStatus(info.Fingerprint))
+	}
+	if record != nil {
+		fmt.Printf("Licença offline:           %s\n", hostStatus(record.Fingerprint))
+	}
+	return nil
+}
+
+// hostStatus descreve se a impressão digital gravada com a licença confere com esta máquina
+func hostStatus(storedFingerprint string) string {
+	if storedFingerprint == "" {
+		return "sem impressão digital registrada"
+	}
+	if err := license.CheckHost(storedFingerprint); err != nil {
+		return err.Error()
+	}
+	return "registrada nesta máquina"
+}
+
+// runCheck executa PRAGMA integrity_check no banco de dados e confere os backups.
+// Uso: check [-quick]
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	quick := flags.Bool("quick", false, "usa PRAGMA quick_check em vez de integrity_check")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := config.LoadSettings(config.SettingsPath()); err != nil {
+		return err
+	}
+
+	// A verificação é feita abaixo, para que o resultado seja impresso mesmo com o banco corrompido
+	options := bootstrap.DatabaseOptions()
+	options.IntegrityCheck = database.IntegrityOff
+	options.LegacyPaths = nil
+	fmt.Printf("Banco de dados: %s\n", options.Path)
+
+	var err error
+	if _, statErr := os.Stat(options.Path); statErr != nil {
+		err = fmt.Errorf("arquivo não encontrado")
+	} else if err = database.OpenDatabase(options); err == nil {
+		err = database.CheckIntegrity(!*quick)
+		database.CloseDatabase()
+	}
+	if err == nil {
+		fmt.Println("  ok")
+	} else {
+		fmt.Printf("  %v\n", err)
+	}
+
+	dir := config.BackupDirectory()
+	backups, listErr := database.ListBackups(dir)
+	if listErr != nil {
+		return listErr
+	}
+	fmt.Printf("Backups em %s:\n", dir)
+	for _, backup := range backups {
+		status := "ok"
+		if verifyErr := database.VerifyBackup(backup.Path); verifyErr != nil {
+			status = verifyErr.Error()
+		}
+		fmt.Printf("  %s  %s\n", filepath.Base(backup.Path), status)
+	}
+	if len(backups) == 0 {
+		fmt.Println("  nenhum backup")
+	}
+
+	if err != nil {
+		return fmt.Errorf("banco de dados com problemas (use restore para recuperar de um backup)")
+	}
+	return nil
+}
+
+// runWebhookReceiver inicia um servidor HTTP local que recebe webhooks e imprime cada entrega.
+// Uso: webhook-receiver [-addr 127.0.0.1:9090] [-secret segredo] [-fail N]
+func runWebhookReceiver(args []string) error {
+	flags := flag.NewFlagSet("webhook-receiver", flag.ContinueOnError)
+	addr := flags.String("addr", "127.0.0.1:9090", "endereço de escuta")
+	secret := flags.String("secret", "", "segredo para conferir a assinatura HMAC")
+	fail := flags.Int("fail", 0, "quantidade de entregas iniciais respondidas com 500")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	receiver := &webhooks.TestReceiver{
+		Secret:    *secret,
+		FailFirst: *fail,
+		OnReceive: func(received webhooks.ReceivedWebhook) {
+			fmt.Printf("%s entrega=%s evento=%s assinatura_valida=%t status=%d\n%s\n",
+				received.ReceivedAt.Format(time.RFC3339), received.Delivery, received.Event,
+				received.SignatureValid, received.StatusCode, received.Body)
+		},
+	}
+
+	fmt.Printf("Receptor de webhooks em http://%s/\n", *addr)
+	return http.ListenAndServe(*addr, receiver)
+}
+
 // hideConsole oculta a janela do console no Windows
 func hideConsole() {
 	console := getConsoleWindow()
@@ -173,6 +551,6 @@ func openBrowser(url string) {
 		err = fmt.Errorf("plataforma não suportada")
 	}
 	if err != nil {
-		log.Printf("Erro ao abrir navegador: %v", err)
+		logger.Warn("Erro ao abrir navegador", "error", err, "url", url)
 	}
 }
@@ -0,0 +1,86 @@
+package metrics
+
+// Métricas compartilhadas entre os pacotes da aplicação.
+// Métricas calculadas a partir do estado de um pacote (ex.: clientes SSE)
+// são registradas pelo próprio pacote com NewGaugeFunc.
+var (
+	// HTTPRequests conta as requisições HTTP por rota, método e status
+	HTTPRequests = NewCounterVec("godesktop_http_requests_total",
+		"Total de requisições HTTP por rota, método e status.", "route", "method", "status")
+
+	// HTTPRequestDuration mede a latência das requisições HTTP por rota
+	HTTPRequestDuration = NewHistogramVec("godesktop_http_request_duration_seconds",
+		"Latência das requisições HTTP em segundos.", DefaultBuckets, "route")
+
+	// FileOperations conta as operações de arquivo por tipo e resultado
+	FileOperations = NewCounterVec("godesktop_file_operations_total",
+		"Total de operações de arquivo por tipo e resultado.", "operation", "result")
+
+	// ProcessesLaunched conta os processos externos iniciados com sucesso
+	ProcessesLaunched = NewCounterVec("godesktop_processes_launched_total",
+		"Total de processos externos iniciados.")
+
+	// ProcessesRunning indica quantos processos externos ainda estão em execução
+	ProcessesRunning = NewGaugeVec("godesktop_processes_running",
+		"Processos externos em execução.")
+
+	// ProcessesFailed conta os processos que falharam ao iniciar ou terminaram com erro
+	ProcessesFailed = NewCounterVec("godesktop_processes_failed_total",
+		"Total de processos externos que falharam, por etapa.", "stage")
+
+	// LicenseChecks conta as verificações de licença por resultado
+	LicenseChecks = NewCounterVec("godesktop_license_checks_total",
+		"Total de verificações de licença por resultado.", "result")
+
+	// LicenseLastSuccess guarda o horário (unix) da última verificação de licença bem-sucedida
+	LicenseLastSuccess = NewGaugeVec("godesktop_license_last_success_timestamp_seconds",
+		"Horário unix da última verificação de licença bem-sucedida.")
+
+	// LicenseNextCheck guarda o horário (unix) da próxima verificação periódica da licença (0 sem agenda)
+	LicenseNextCheck = NewGaugeVec("godesktop_license_next_check_timestamp_seconds",
+		"Horário unix da próxima verificação periódica da licença.")
+
+	// RateLimitAllowed conta as requisições aceitas pelo rate limit por grupo
+	RateLimitAllowed = NewCounterVec("godesktop_rate_limit_allowed_total",
+		"Total de requisições aceitas pelo rate limit por grupo.", "group")
+
+	// RateLimitRejected conta as requisições rejeitadas (429) pelo rate limit por grupo
+	RateLimitRejected = NewCounterVec("godesktop_rate_limit_rejected_total",
+		"Total de requisições rejeitadas pelo rate limit por grupo.", "group")
+
+	// SSEDroppedEvents conta as entradas de log descartadas para clientes SSE lentos
+	SSEDroppedEvents = NewCounterVec("godesktop_sse_dropped_events_total",
+		"Total de entradas de log descartadas para clientes SSE lentos.")
+
+	// WebSocketDroppedEvents conta os eventos descartados para clientes WebSocket lentos
+	WebSocketDroppedEvents = NewCounterVec("godesktop_websocket_dropped_events_total",
+		"Total de eventos descartados para clientes WebSocket lentos.")
+
+	// EventsPublished conta os eventos publicados no barramento interno por tipo
+	EventsPublished = NewCounterVec("godesktop_events_published_total",
+		"Total de eventos publicados no barramento interno por tipo.", "type")
+
+	// EventsDropped conta os eventos descartados por consumidores lentos do barramento
+	EventsDropped = NewCounterVec("godesktop_events_dropped_total",
+		"Total de eventos descartados por consumidores lentos do barramento.", "subscriber")
+
+	// WebhookDeliveries conta as tentativas de entrega de webhooks por resultado
+	WebhookDeliveries = NewCounterVec("godesktop_webhook_deliveries_total",
+		"Total de tentativas de entrega de webhooks por resultado.", "result")
+
+	// AuditRecords conta os registros gravados na trilha de auditoria por operação e resultado
+	AuditRecords = NewCounterVec("godesktop_audit_records_total",
+		"Total de registros da trilha de auditoria por operação e resultado.", "operation", "result")
+
+	// DatabaseBackups conta os backups do banco de dados por resultado
+	DatabaseBackups = NewCounterVec("godesktop_database_backups_total",
+		"Total de backups do banco de dados por resultado.", "result")
+
+	// DatabaseLastBackup guarda o horário (unix) do último backup bem-sucedido
+	DatabaseLastBackup = NewGaugeVec("godesktop_database_last_backup_timestamp_seconds",
+		"Horário unix do último backup do banco de dados bem-sucedido.")
+
+	// DatabaseErrors conta os erros do banco de dados por operação
+	DatabaseErrors = NewCounterVec("godesktop_database_errors_total",
+		"Total de erros do banco de dados por operação.", "operation")
+)
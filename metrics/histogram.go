@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets são os limites superiores (em segundos) usados para latências
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogramValues acumula as observações de uma combinação de labels
+type histogramValues struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// HistogramVec é um histograma com buckets fixos e labels
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	mutex   sync.Mutex
+	values  map[string]*histogramValues
+}
+
+// HistogramBucket representa um bucket cumulativo
+type HistogramBucket struct {
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
+
+// HistogramSnapshot é uma cópia do histograma de uma combinação de labels
+type HistogramSnapshot struct {
+	LabelValues []string
+	Count       uint64
+	Sum         float64
+	Buckets     []HistogramBucket
+}
+
+// NewHistogramVec cria e registra um histograma
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		values:  make(map[string]*histogramValues),
+	}
+	register(name, h)
+	return h
+}
+
+// Observe registra uma observação
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.labels) {
+		panic(fmt.Sprintf("métrica %s espera %d labels, recebeu %d", h.name, len(h.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	v, ok := h.values[key]
+	if !ok {
+		v = &histogramValues{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.values[key] = v
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			v.counts[i]++
+		}
+	}
+	v.count++
+	v.sum += value
+}
+
+// Snapshot retorna uma cópia de todos os histogramas, ordenada pelos labels
+func (h *HistogramVec) Snapshot() []HistogramSnapshot {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	result := make([]HistogramSnapshot, 0, len(h.values))
+	for _, v := range h.values {
+		buckets := make([]HistogramBucket, len(h.buckets))
+		for i, bound := range h.buckets {
+			buckets[i] = HistogramBucket{UpperBound: bound, Count: v.counts[i]}
+		}
+		result = append(result, HistogramSnapshot{
+			LabelValues: v.labelValues,
+			Count:       v.count,
+			Sum:         v.sum,
+			Buckets:     buckets,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Join(result[i].LabelValues, "\xff") < strings.Join(result[j].LabelValues, "\xff")
+	})
+	return result
+}
+
+func (h *HistogramVec) writeText(w io.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+
+	for _, snap := range h.Snapshot() {
+		for _, bucket := range snap.Buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name,
+				formatLabels(h.labels, snap.LabelValues, "le", formatValue(bucket.UpperBound)), bucket.Count)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, snap.LabelValues, "le", "+Inf"), snap.Count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, snap.LabelValues, "", ""), formatValue(snap.Sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, snap.LabelValues, "", ""), snap.Count)
+	}
+}
@@ -0,0 +1,252 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// collector é implementado por todas as métricas registradas
+type collector interface {
+	writeText(w io.Writer)
+}
+
+var (
+	registry      []collector
+	registryNames = make(map[string]bool)
+	registryMutex sync.Mutex
+)
+
+// register adiciona uma métrica ao registro global (nomes duplicados causam panic)
+func register(name string, c collector) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if registryNames[name] {
+		panic(fmt.Sprintf("métrica %s registrada duas vezes", name))
+	}
+	registryNames[name] = true
+	registry = append(registry, c)
+}
+
+// WriteText escreve todas as métricas no formato de exposição texto do Prometheus
+func WriteText(w io.Writer) {
+	registryMutex.Lock()
+	collectors := make([]collector, len(registry))
+	copy(collectors, registry)
+	registryMutex.Unlock()
+
+	for _, c := range collectors {
+		c.writeText(w)
+	}
+}
+
+// series guarda os valores de uma métrica por combinação de labels
+type series struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	mutex  sync.Mutex
+	values map[string]float64
+	keys   map[string][]string
+}
+
+func newSeries(name, help, kind string, labels []string) *series {
+	s := &series{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		values: make(map[string]float64),
+		keys:   make(map[string][]string),
+	}
+
+	// Métricas sem labels são expostas com zero desde o início
+	if len(labels) == 0 {
+		s.values[""] = 0
+		s.keys[""] = nil
+	}
+	return s
+}
+
+func (s *series) add(delta float64, labelValues []string) {
+	key := s.key(labelValues)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[key] += delta
+	s.keys[key] = labelValues
+}
+
+func (s *series) set(value float64, labelValues []string) {
+	key := s.key(labelValues)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[key] = value
+	s.keys[key] = labelValues
+}
+
+func (s *series) get(labelValues []string) float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.values[s.key(labelValues)]
+}
+
+func (s *series) key(labelValues []string) string {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("métrica %s espera %d labels, recebeu %d", s.name, len(s.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func (s *series) writeText(w io.Writer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	writeHeader(w, s.name, s.help, s.kind)
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", s.name, formatLabels(s.labels, s.keys[key], "", ""), formatValue(s.values[key]))
+	}
+}
+
+// CounterVec é um contador monotônico com labels
+type CounterVec struct {
+	s *series
+}
+
+// NewCounterVec cria e registra um contador
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{s: newSeries(name, help, "counter", labels)}
+	register(name, c)
+	return c
+}
+
+// Inc incrementa o contador em 1
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.s.add(1, labelValues)
+}
+
+// Add incrementa o contador pelo valor informado (deve ser positivo)
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.s.add(delta, labelValues)
+}
+
+// Value retorna o valor atual do contador
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	return c.s.get(labelValues)
+}
+
+func (c *CounterVec) writeText(w io.Writer) {
+	c.s.writeText(w)
+}
+
+// GaugeVec é um valor que pode subir e descer, com labels
+type GaugeVec struct {
+	s *series
+}
+
+// NewGaugeVec cria e registra um gauge
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{s: newSeries(name, help, "gauge", labels)}
+	register(name, g)
+	return g
+}
+
+// Set define o valor do gauge
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.s.set(value, labelValues)
+}
+
+// Inc incrementa o gauge em 1
+func (g *GaugeVec) Inc(labelValues ...string) {
+	g.s.add(1, labelValues)
+}
+
+// Dec decrementa o gauge em 1
+func (g *GaugeVec) Dec(labelValues ...string) {
+	g.s.add(-1, labelValues)
+}
+
+// Value retorna o valor atual do gauge
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	return g.s.get(labelValues)
+}
+
+func (g *GaugeVec) writeText(w io.Writer) {
+	g.s.writeText(w)
+}
+
+// GaugeFunc é um gauge cujo valor é calculado no momento da coleta
+type GaugeFunc struct {
+	name string
+	help string
+	fn   func() float64
+}
+
+// NewGaugeFunc cria e registra um gauge calculado
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help, fn: fn}
+	register(name, g)
+	return g
+}
+
+func (g *GaugeFunc) writeText(w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.fn()))
+}
+
+// writeHeader escreve as linhas HELP e TYPE de uma métrica
+func writeHeader(w io.Writer, name, help, kind string) {
+	help = strings.ReplaceAll(help, "\\", `\\`)
+	help = strings.ReplaceAll(help, "\n", `\n`)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// formatLabels formata os labels no padrão {a="1",b="2"}, com um label extra opcional
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+
+	parts := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, name, escapeLabel(values[i])))
+	}
+	if extraName != "" {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, extraName, escapeLabel(extraValue)))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// escapeLabel escapa barras, aspas e quebras de linha nos valores de labels
+func escapeLabel(value string) string {
+	value = strings.ReplaceAll(value, "\\", `\\`)
+	value = strings.ReplaceAll(value, "\"", `\"`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+// formatValue formata um valor numérico no padrão do Prometheus
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
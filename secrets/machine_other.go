@@ -0,0 +1,26 @@
+//go:build !windows
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+)
+
+// protect grava o segredo como está: a proteção é o arquivo legível apenas pelo dono
+func protect(secret []byte) ([]byte, error) {
+	return secret, nil
+}
+
+// unprotect devolve o segredo lido do arquivo
+func unprotect(protected []byte) ([]byte, error) {
+	return protected, nil
+}
+
+// checkKeyFile recusa um arquivo de chave que o grupo ou outros usuários possam acessar
+func checkKeyFile(path string, info os.FileInfo) error {
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("permissões %#o do arquivo de chave %s são abertas demais (use chmod 600)", perm, path)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// dpapiEntropy separa os dados protegidos por esta aplicação dos de outros programas
+var dpapiEntropy = []byte("go-desktop-app machine secret")
+
+// protect cifra o segredo com o DPAPI no escopo da máquina, para que o serviço
+// (LocalSystem) e o modo interativo (usuário) leiam a mesma chave; o arquivo
+// resultante não pode ser decifrado em outro computador
+func protect(secret []byte) ([]byte, error) {
+	var out windows.DataBlob
+	err := windows.CryptProtectData(blob(secret), nil, blob(dpapiEntropy), 0, nil,
+		windows.CRYPTPROTECT_UI_FORBIDDEN|windows.CRYPTPROTECT_LOCAL_MACHINE, &out)
+	if err != nil {
+		return nil, err
+	}
+	return copyBlob(&out), nil
+}
+
+// unprotect decifra um segredo produzido por protect
+func unprotect(protected []byte) ([]byte, error) {
+	var out windows.DataBlob
+	err := windows.CryptUnprotectData(blob(protected), nil, blob(dpapiEntropy), 0, nil,
+		windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	if err != nil {
+		return nil, err
+	}
+	return copyBlob(&out), nil
+}
+
+// checkKeyFile não restringe permissões no Windows: a proteção vem do DPAPI
+func checkKeyFile(path string, info os.FileInfo) error {
+	return nil
+}
+
+// blob aponta um DataBlob para os bytes informados
+func blob(data []byte) *windows.DataBlob {
+	if len(data) == 0 {
+		return &windows.DataBlob{}
+	}
+	return &windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+}
+
+// copyBlob copia o resultado do DPAPI e libera a memória alocada pelo Windows
+func copyBlob(out *windows.DataBlob) []byte {
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...)
+}
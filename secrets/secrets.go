@@ -0,0 +1,199 @@
+// Package secrets cifra os segredos guardados pela aplicação (como o token de licença)
+// com uma chave vinculada à máquina: o segredo de origem fica em um arquivo protegido
+// pelo DPAPI no Windows e em um arquivo legível apenas pelo dono nos demais sistemas.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SecretStore cifra e decifra valores guardados em texto (colunas TEXT do banco)
+type SecretStore interface {
+	// Encrypt cifra o valor e o devolve com o prefixo de versão
+	Encrypt(plaintext string) (string, error)
+	// Decrypt decifra um valor produzido por Encrypt
+	Decrypt(value string) (string, error)
+}
+
+// encryptedPrefix identifica os valores cifrados e a versão do formato:
+// enc:v1:<base64(nonce || AES-256-GCM)>
+const encryptedPrefix = "enc:v1:"
+
+// Parâmetros da chave derivada do segredo da máquina
+const (
+	machineSecretSize = 32
+	keyInfo           = "go-desktop-app secrets v1"
+)
+
+// ErrDecrypt indica um valor que não pôde ser decifrado com a chave desta máquina
+var ErrDecrypt = errors.New("não foi possível decifrar o segredo com a chave desta máquina")
+
+// IsEncrypted informa se o valor foi produzido por SecretStore.Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// MachineStore implementa SecretStore com AES-256-GCM e chave derivada (HKDF-SHA256)
+// do segredo da máquina guardado em keyPath. O segredo é criado no primeiro uso.
+type MachineStore struct {
+	keyPath string
+	mutex   sync.Mutex
+	aead    cipher.AEAD
+}
+
+var _ SecretStore = (*MachineStore)(nil)
+
+// NewMachineStore cria um SecretStore sobre o arquivo de chave informado.
+// O arquivo só é lido (ou criado) na primeira operação.
+func NewMachineStore(keyPath string) *MachineStore {
+	return &MachineStore{keyPath: keyPath}
+}
+
+// KeyPath retorna o caminho do arquivo com o segredo da máquina
+func (s *MachineStore) KeyPath() string {
+	return s.keyPath
+}
+
+// Encrypt cifra o valor com um nonce aleatório
+func (s *MachineStore) Encrypt(plaintext string) (string, error) {
+	aead, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("erro ao gerar nonce: %v", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(encryptedPrefix))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decifra um valor produzido por Encrypt. Retorna ErrDecrypt se o valor
+// foi alterado ou cifrado com a chave de outra máquina.
+func (s *MachineStore) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("valor não está cifrado")
+	}
+	aead, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(encryptedPrefix))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+// cipher carrega o segredo da máquina e prepara o AES-GCM na primeira chamada.
+// Em caso de erro a próxima chamada tenta de novo.
+func (s *MachineStore) cipher() (cipher.AEAD, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.aead != nil {
+		return s.aead, nil
+	}
+
+	secret, err := loadOrCreateSecret(s.keyPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hkdf.Key(sha256.New, secret, nil, keyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao derivar chave: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar cifra: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar cifra: %v", err)
+	}
+	s.aead = aead
+	return aead, nil
+}
+
+// loadOrCreateSecret lê o segredo da máquina em path ou, se o arquivo não existir, gera um novo
+func loadOrCreateSecret(path string) ([]byte, error) {
+	secret, err := readSecret(path)
+	if !os.IsNotExist(err) {
+		return secret, err
+	}
+
+	secret = make([]byte, machineSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("erro ao gerar segredo da máquina: %v", err)
+	}
+	protected, err := protect(secret)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao proteger segredo da máquina: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório da chave %s: %v", filepath.Dir(path), err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		// Outro processo (serviço ou modo interativo) criou a chave primeiro
+		return readSecret(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar arquivo de chave %s: %v", path, err)
+	}
+	if _, err := file.Write(protected); err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("erro ao gravar arquivo de chave %s: %v", path, err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("erro ao gravar arquivo de chave %s: %v", path, err)
+	}
+	return secret, nil
+}
+
+// readSecret lê e desprotege o segredo da máquina. Um arquivo inexistente
+// retorna um erro que satisfaz os.IsNotExist.
+func readSecret(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao acessar arquivo de chave %s: %v", path, err)
+	}
+	if err := checkKeyFile(path, info); err != nil {
+		return nil, err
+	}
+
+	protected, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de chave %s: %v", path, err)
+	}
+	secret, err := unprotect(protected)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao desproteger arquivo de chave %s: %v", path, err)
+	}
+	if len(secret) != machineSecretSize {
+		return nil, fmt.Errorf("arquivo de chave %s inválido", path)
+	}
+	return secret, nil
+}
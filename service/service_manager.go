@@ -186,6 +186,20 @@ func PrintServiceCommands() {
 	fmt.Println("  stop      - Para o serviço")
 	fmt.Println("  status    - Mostra o status do serviço")
 	fmt.Println("  service   - Executa como serviço (uso interno)")
+	fmt.Println("  migrate status | up [versão] | down [quantidade]")
+	fmt.Println("            - Consulta, aplica ou reverte as migrações do banco de dados")
+	fmt.Println("  backup [arquivo | list]")
+	fmt.Println("            - Cria um backup do banco de dados (ou lista os existentes)")
+	fmt.Println("  restore [arquivo]")
+	fmt.Println("            - Restaura o banco de dados do backup informado ou do último válido")
+	fmt.Println("  check [-quick]")
+	fmt.Println("            - Verifica a integridade do banco de dados e dos backups")
+	fmt.Println("  license-import <arquivo>")
+	fmt.Println("            - Instala um arquivo de licença offline assinado")
+	fmt.Println("  device-id")
+	fmt.Println("            - Mostra o UUID e a impressão digital desta máquina")
+	fmt.Println("  webhook-receiver [-addr 127.0.0.1:9090] [-secret S] [-fail N]")
+	fmt.Println("            - Receptor local de webhooks para testes")
 	fmt.Println("")
 	fmt.Println("Exemplo de uso:")
 	fmt.Printf("  %s install\n", os.Args[0])
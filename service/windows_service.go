@@ -4,7 +4,7 @@ import (
 	"context"
 	"embed"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
@@ -14,13 +14,18 @@ import (
 	"golang.org/x/sys/windows/svc/eventlog"
 
 	"go-desktop-app/api"
+	"go-desktop-app/bootstrap"
 	"go-desktop-app/database"
+	"go-desktop-app/logging"
 	"go-desktop-app/ui"
 )
 
 var elog debug.Log
 var webFiles embed.FS
 
+// logger registra o ciclo de vida do serviço
+var logger = logging.Component("service")
+
 // SetWebFiles define os arquivos web embarcados para o serviço
 func SetWebFiles(files embed.FS) {
 	webFiles = files
@@ -55,6 +60,8 @@ loop:
 				changes <- c.CurrentStatus
 			case svc.Stop, svc.Shutdown:
 				elog.Info(1, "Parando serviço...")
+				database.StopLogWriter()
+				logging.CloseFileSink()
 				cancel()
 				break loop
 			case svc.Pause:
@@ -74,32 +81,41 @@ loop:
 }
 
 func startApplication() {
-	// Configura o log para arquivo quando rodando como serviço
-	logFile, err := os.OpenFile("go-desktop-app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err == nil {
-		log.SetOutput(logFile)
-		defer logFile.Close()
-	}
+	// Configura o logger central
+	logging.RedirectStandardLog()
+	logging.AddSink("window", slog.LevelDebug, ui.LogSink())
+	logging.AddSink("web", slog.LevelDebug, api.LogSink())
+
+	logger.Info("Iniciando Go Desktop App como serviço")
 
-	log.Println("Iniciando Go Desktop App como serviço...")
+	// Carrega as configurações (config.json)
+	bootstrap.LoadSettings()
 
-	// Inicializa o banco de dados
-	if err := database.InitDatabase(); err != nil {
-		log.Printf("Erro ao inicializar banco de dados: %v", err)
-		// Continua a execução mesmo com erro no banco
+	// Como serviço não há console: os logs vão para o arquivo com rotação (JSON por linha)
+	if bootstrap.EnableLogFile() {
+		logging.RemoveSink("console")
+		defer logging.CloseFileSink()
 	}
 
+	// Inicializa o banco de dados e as tarefas em segundo plano
+	bootstrap.StartDatabase()
+
+	// Injeta o store de licença (sem banco, as operações de licença retornam erro)
+	store := database.Licenses()
+	api.SetLicenseStore(store)
+	ui.SetLicenseStore(store)
+
 	// Configura os arquivos web embarcados
 	api.SetWebFiles(webFiles)
 
 	// Inicia o servidor da API
 	go func() {
-		log.Println("Iniciando servidor API...")
+		logger.Info("Iniciando servidor API")
 		api.StartServer()
 	}()
 
 	// Configura o system tray (se disponível)
-	log.Println("Configurando system tray...")
+	logger.Info("Configurando system tray")
 	ui.SetupTray()
 }
 
@@ -132,7 +148,8 @@ func runService(name string, isDebug bool) {
 func RunAsService(serviceName string) {
 	isService, err := svc.IsWindowsService()
 	if err != nil {
-		log.Fatalf("Falha ao determinar se está rodando como serviço: %v", err)
+		logger.Error("Falha ao determinar se está rodando como serviço", "error", err)
+		os.Exit(1)
 	}
 
 	if isService {
@@ -152,3 +169,4 @@ func GetExecutablePath() (string, error) {
 	}
 	return filepath.Abs(ex)
 }
+
@@ -2,37 +2,82 @@ package ui
 
 import (
 	"fmt"
-	"log"
+	"sync"
+	"time"
 
 	"go-desktop-app/database"
+	"go-desktop-app/events"
+	"go-desktop-app/license"
 )
 
+// licenseStore é o store de onde o tray lê o estado inicial da licença (definido por SetLicenseStore)
+var licenseStore database.LicenseStore = database.UnconfiguredLicenseStore{Owner: "ui"}
+
+// SetLicenseStore define o store de licença usado pelo tray
+func SetLicenseStore(store database.LicenseStore) {
+	licenseStore = store
+}
+
+// Estado da licença exibido no tray, atualizado pelos eventos LicenseChanged
+var (
+	trayLicense      events.License
+	trayLicenseMutex sync.RWMutex
+)
+
+// setTrayLicense guarda o estado da licença exibido no tray
+func setTrayLicense(status events.License) {
+	trayLicenseMutex.Lock()
+	defer trayLicenseMutex.Unlock()
+	trayLicense = status
+}
+
+// currentTrayLicense retorna o último estado da licença recebido
+func currentTrayLicense() events.License {
+	trayLicenseMutex.RLock()
+	defer trayLicenseMutex.RUnlock()
+	return trayLicense
+}
+
 // GetLicenseStatusForTray obtém o status da licença para exibição no tray
 func GetLicenseStatusForTray() string {
+	return licenseStatusText(currentTrayLicense())
+}
+
+// licenseStatusText formata o estado da licença para o tooltip
+func licenseStatusText(status events.License) string {
 	// Verifica se há licença configurada
-	if !database.HasLicenseInfo() {
+	if !status.HasLicense {
 		return "❌ Licença não configurada"
 	}
 
-	// Recupera as informações da licença
-	info, err := database.GetLicenseInfo()
-	if err != nil {
-		log.Printf("Erro ao recuperar informações de licença: %v", err)
+	if status.Error != "" {
+		logger.Error("Erro ao recuperar informações de licença", "error", status.Error)
 		return "❌ Erro ao verificar licença"
 	}
 
-	if info == nil {
+	if status.DeviceUUID == "" {
 		return "❌ Licença não encontrada"
 	}
 
 	// Verifica o status
-	if info.IsActive {
-		return fmt.Sprintf("✅ Licença ativa\nUUID: %s\nÚltima verificação: %s", 
-			info.DeviceUUID[:8]+"...", 
-			formatLastCheck(info.LastCheck))
-	} else {
-		return fmt.Sprintf("⚠️ Licença inativa\nUUID: %s", info.DeviceUUID[:8]+"...")
+	if status.IsValid {
+		text := fmt.Sprintf("✅ Licença ativa\nUUID: %s\nÚltima verificação: %s",
+			shortUUID(status.DeviceUUID),
+			formatLastCheck(status.LastCheck))
+		if status.NextCheck != "" {
+			text += "\nPróxima verificação: " + formatNextCheck(status.NextCheck)
+		}
+		return text
+	}
+	return fmt.Sprintf("⚠️ Licença inativa\nUUID: %s", shortUUID(status.DeviceUUID))
+}
+
+// shortUUID mostra apenas o início do UUID da máquina
+func shortUUID(deviceUUID string) string {
+	if len(deviceUUID) > 8 {
+		return deviceUUID[:8] + "..."
 	}
+	return deviceUUID
 }
 
 // formatLastCheck formata o timestamp da última verificação
@@ -49,6 +94,15 @@ func formatLastCheck(lastCheck string) string {
 	return lastCheck
 }
 
+// formatNextCheck mostra a próxima verificação (RFC 3339) no horário local
+func formatNextCheck(nextCheck string) string {
+	t, err := time.Parse(time.RFC3339, nextCheck)
+	if err != nil {
+		return nextCheck
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
 // UpdateTrayTooltipWithLicense atualiza o tooltip do tray com informações de licença
 func UpdateTrayTooltipWithLicense() {
 	status := GetLicenseStatusForTray()
@@ -58,37 +112,33 @@ func UpdateTrayTooltipWithLicense() {
 	
 	// Esta função seria chamada para atualizar o tooltip do systray
 	// Por enquanto, apenas loga a informação
-	log.Printf("Tooltip atualizado: %s", tooltip)
+	logger.Debug("Tooltip atualizado", "tooltip", tooltip)
 }
 
 // GetLicenseMenuText retorna o texto para o menu do tray baseado no status da licença
 func GetLicenseMenuText() string {
-	if !database.HasLicenseInfo() {
-		return "⚙️ Configurar Licença"
-	}
+	return licenseMenuText(currentTrayLicense())
+}
 
-	info, err := database.GetLicenseInfo()
-	if err != nil || info == nil {
+// licenseMenuText retorna o texto do item de menu para o estado da licença
+func licenseMenuText(status events.License) string {
+	if !status.HasLicense || status.Error != "" || status.DeviceUUID == "" {
 		return "⚙️ Configurar Licença"
 	}
 
-	if info.IsActive {
+	if status.IsValid {
 		return "✅ Licença Ativa"
-	} else {
-		return "⚠️ Licença Inativa"
 	}
+	return "⚠️ Licença Inativa"
 }
 
 // ShouldShowLicenseWarning verifica se deve mostrar aviso de licença
 func ShouldShowLicenseWarning() bool {
-	if !database.HasLicenseInfo() {
-		return true
-	}
-
-	info, err := database.GetLicenseInfo()
-	if err != nil || info == nil {
-		return true
-	}
+	status := currentTrayLicense()
+	return !status.HasLicense || status.Error != "" || !status.IsValid
+}
 
-	return !info.IsActive
+// loadTrayLicense lê o estado inicial da licença; as mudanças chegam por LicenseChanged
+func loadTrayLicense() {
+	setTrayLicense(license.CurrentStatus(licenseStore))
 }
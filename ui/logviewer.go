@@ -1,10 +1,11 @@
 package ui
 
 import (
-	"log"
 	"strings"
 	"sync"
 
+	"go-desktop-app/logging"
+
 	"github.com/lxn/walk"
 	"github.com/lxn/walk/declarative"
 )
@@ -26,8 +27,8 @@ func NewLogWindow() *LogWindow {
 
 	// Tenta criar a janela, mas não falha se houver erro
 	if err := lw.createWindow(); err != nil {
-		log.Printf("Aviso: Janela de logs nativa não disponível: %v", err)
-		log.Printf("Os logs continuarão sendo exibidos no console e na interface web")
+		logger.Warn("Janela de logs nativa não disponível; os logs continuarão no console e na interface web",
+			"error", err)
 		// Retorna a estrutura mesmo com erro para manter compatibilidade
 		return lw
 	}
@@ -77,7 +78,7 @@ func (lw *LogWindow) Show() {
 		lw.updateDisplay()
 	} else {
 		// Se a janela nativa não está disponível, abre a interface web
-		log.Println("Janela nativa não disponível. Abrindo interface web em http://localhost:8080")
+		logger.Info("Janela nativa não disponível. Abrindo interface web em http://localhost:8080")
 		// Aqui você poderia adicionar código para abrir o navegador automaticamente
 		// exec.Command("cmd", "/c", "start", "http://localhost:8080").Start()
 	}
@@ -145,3 +146,10 @@ func AddGlobalLog(message string) {
 		globalLogWindow.AddLog(message)
 	}
 }
+
+// LogSink retorna o sink do logger central que alimenta a janela de logs nativa
+func LogSink() logging.Sink {
+	return logging.SinkFunc(func(rec logging.Record) {
+		AddGlobalLog(logging.FormatLine(rec))
+	})
+}
@@ -2,11 +2,15 @@ package ui
 
 import (
 	_ "embed"
-	"log"
 	"os"
 	"os/exec"
 	"time"
 
+	"go-desktop-app/config"
+	"go-desktop-app/database"
+	"go-desktop-app/events"
+	"go-desktop-app/logging"
+
 	"github.com/getlantern/systray"
 )
 
@@ -17,29 +21,34 @@ var (
 	logWindow *LogWindow
 )
 
+// logger registra os eventos da bandeja do sistema
+var logger = logging.Component("ui")
+
 // SetupTray configura o ícone da bandeja do sistema
 func SetupTray() {
-	log.Println("Iniciando system tray...")
+	logger.Info("Iniciando system tray")
 	systray.Run(onReady, onExit)
 }
 
 // onReady é chamado quando o systray está pronto
 func onReady() {
-	log.Println("System tray inicializado com sucesso!")
+	logger.Info("System tray inicializado com sucesso")
 
 	// Configura o ícone do sistema
-	log.Println("Carregando ícone...")
+	logger.Debug("Carregando ícone")
 	setTrayIcon()
 	systray.SetTitle("Go App")
 
 	// Atualiza tooltip com informações de licença
+	loadTrayLicense()
 	updateTooltipWithLicense()
 
-	log.Println("Ícone, título e tooltip definidos")
+	logger.Debug("Ícone, título e tooltip definidos")
 
 	// Cria os itens do menu
 	mShowLogs := systray.AddMenuItem("📊 Abrir Logs", "Mostra a interface de logs")
 	mLicense := systray.AddMenuItem(GetLicenseMenuText(), "Gerenciar licença do sistema")
+	mReload := systray.AddMenuItem("🔄 Recarregar Configurações", "Relê o config.json")
 	systray.AddSeparator()
 	mQuit := systray.AddMenuItem("Sair", "Encerra a aplicação")
 	
@@ -47,6 +56,17 @@ func onReady() {
 	InitGlobalLogWindow()
 	logWindow = globalLogWindow
 
+	// Atualiza o tooltip e o menu quando a licença muda, sem consultar o banco
+	events.Subscribe("tray", func(event events.Event) {
+		status, ok := event.Data.(events.License)
+		if !ok {
+			return
+		}
+		setTrayLicense(status)
+		updateTooltipWithLicense()
+		mLicense.SetTitle(licenseMenuText(status))
+	}, events.LicenseChanged)
+
 	// Inicia um goroutine para tentar reconfigurar o ícone periodicamente
 	go refreshTrayIcon()
 	
@@ -57,11 +77,17 @@ func onReady() {
 			case <-mShowLogs.ClickedCh:
 				// Abre a interface web no Chrome como aplicativo
 				openChromeApp()
-				log.Println("Aplicativo Chrome aberto com sucesso")
+				logger.Info("Aplicativo Chrome aberto com sucesso")
 			case <-mLicense.ClickedCh:
 				// Abre a interface de licença no Chrome como aplicativo
 				openLicenseApp()
-				log.Println("Interface de licença aberta com sucesso")
+				logger.Info("Interface de licença aberta com sucesso")
+			case <-mReload.ClickedCh:
+				if err := config.ReloadSettings(""); err != nil {
+					logger.Error("Erro ao recarregar configurações", "error", err)
+				} else {
+					logger.Info("Configurações recarregadas", "path", config.SettingsPath())
+				}
 			case <-mQuit.ClickedCh:
 				systray.Quit()
 				return
@@ -72,7 +98,10 @@ func onReady() {
 
 // onExit é chamado quando o systray está sendo encerrado
 func onExit() {
-	log.Println("Encerrando aplicação...")
+	logger.Info("Encerrando aplicação")
+	// Grava os logs pendentes e encerra o gravador antes de sair
+	database.StopLogWriter()
+	logging.CloseFileSink()
 	if logWindow != nil {
 		logWindow.Close()
 	}
@@ -85,45 +114,45 @@ func setTrayIcon() {
 
 	// Tenta usar o ícone embarcado primeiro
 	if len(embeddedIcon) > 0 {
-		log.Printf("Tentando usar ícone embarcado (%d bytes)", len(embeddedIcon))
+		logger.Debug("Tentando usar ícone embarcado", "bytes", len(embeddedIcon))
 		systray.SetIcon(embeddedIcon)
 		iconSet = true
-		log.Println("✅ Ícone embarcado configurado")
+		logger.Debug("Ícone embarcado configurado")
 	}
 
 	// Fallback 1: Tenta carregar icon.ico do diretório atual
 	if !iconSet {
 		if iconData, err := os.ReadFile("icon.ico"); err == nil {
-			log.Printf("Usando icon.ico do diretório atual (%d bytes)", len(iconData))
+			logger.Debug("Usando icon.ico do diretório atual", "bytes", len(iconData))
 			systray.SetIcon(iconData)
 			iconSet = true
 		} else {
-			log.Printf("Não foi possível carregar icon.ico: %v", err)
+			logger.Warn("Não foi possível carregar icon.ico", "error", err)
 		}
 	}
 
 	// Fallback 2: Tenta carregar do diretório ui/
 	if !iconSet {
 		if iconData, err := os.ReadFile("ui/icon.ico"); err == nil {
-			log.Printf("Usando icon.ico do diretório ui/ (%d bytes)", len(iconData))
+			logger.Debug("Usando icon.ico do diretório ui/", "bytes", len(iconData))
 			systray.SetIcon(iconData)
 			iconSet = true
 		} else {
-			log.Printf("Não foi possível carregar ui/icon.ico: %v", err)
+			logger.Warn("Não foi possível carregar ui/icon.ico", "error", err)
 		}
 	}
 
 	// Fallback 3: Cria um ícone simples programaticamente (PNG 16x16)
 	if !iconSet {
-		log.Println("Criando ícone padrão programaticamente")
+		logger.Debug("Criando ícone padrão programaticamente")
 		defaultIcon := createDefaultIcon()
 		systray.SetIcon(defaultIcon)
-		log.Printf("✅ Ícone padrão configurado (%d bytes)", len(defaultIcon))
+		logger.Debug("Ícone padrão configurado", "bytes", len(defaultIcon))
 	}
 
 	// Força uma atualização do sistema tray
 	systray.SetTitle("Go App")
-	log.Println("🔄 Sistema tray atualizado")
+	logger.Debug("Sistema tray atualizado")
 }
 
 // createDefaultIcon cria um ícone PNG simples de 16x16 pixels
@@ -152,13 +181,13 @@ func refreshTrayIcon() {
 
 	// Tenta reconfigurar o ícone algumas vezes
 	for i := 0; i < 3; i++ {
-		log.Printf("🔄 Tentativa %d de reconfiguração do ícone", i+1)
+		logger.Debug("Reconfigurando o ícone", "attempt", i+1)
 		setTrayIcon()
 		updateTooltipWithLicense()
 		time.Sleep(2 * time.Second)
 	}
 
-	log.Println("✅ Processo de reconfiguração do ícone concluído")
+	logger.Debug("Processo de reconfiguração do ícone concluído")
 }
 
 // updateTooltipWithLicense atualiza o tooltip do tray com informações de licença
@@ -187,13 +216,12 @@ func openChromeApp() {
 	cmd := exec.Command(chromePath, args...)
 
 	if err := cmd.Start(); err != nil {
-		log.Printf("Erro ao abrir Chrome: %v", err)
-		log.Printf("Tentando abrir URL padrão: %s", url)
+		logger.Warn("Erro ao abrir Chrome, tentando abrir URL padrão", "error", err, "url", url)
 		// Fallback para comando padrão do Windows
 		fallbackCmd := exec.Command("cmd", "/c", "start", url)
 		fallbackCmd.Start()
 	} else {
-		log.Printf("Chrome app aberto em: %s", url)
+		logger.Debug("Chrome app aberto", "url", url)
 	}
 }
 
@@ -216,13 +244,12 @@ func openLicenseApp() {
 	cmd := exec.Command(chromePath, args...)
 
 	if err := cmd.Start(); err != nil {
-		log.Printf("Erro ao abrir Chrome para licença: %v", err)
-		log.Printf("Tentando abrir URL padrão: %s", url)
+		logger.Warn("Erro ao abrir Chrome para licença, tentando abrir URL padrão", "error", err, "url", url)
 		// Fallback para comando padrão do Windows
 		fallbackCmd := exec.Command("cmd", "/c", "start", url)
 		fallbackCmd.Start()
 	} else {
-		log.Printf("Chrome app de licença aberto em: %s", url)
+		logger.Debug("Chrome app de licença aberto", "url", url)
 	}
 }
 
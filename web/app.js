@@ -5,7 +5,7 @@ class LogsApp {
         this.logs = [];
         this.totalLogs = 0;
         this.errorCount = 0;
-        this.successCount = 0;
+        this.warnCount = 0;
         this.lastLogTime = null;
 
         console.log('=== OBTENDO ELEMENTOS DOM ===');
@@ -13,14 +13,14 @@ class LogsApp {
         this.totalLogsElement = document.getElementById('totalLogs');
         this.lastLogTimeElement = document.getElementById('lastLogTime');
         this.errorCountElement = document.getElementById('errorCount');
-        this.successCountElement = document.getElementById('successCount');
+        this.warnCountElement = document.getElementById('warnCount');
 
         console.log('Elementos encontrados:', {
             logsContainer: !!this.logsContainer,
             totalLogsElement: !!this.totalLogsElement,
             lastLogTimeElement: !!this.lastLogTimeElement,
             errorCountElement: !!this.errorCountElement,
-            successCountElement: !!this.successCountElement
+            warnCountElement: !!this.warnCountElement
         });
 
         console.log('=== INICIANDO APLICAÇÃO ===');
@@ -61,6 +61,12 @@ class LogsApp {
                     }
                 };
 
+                // O servidor avisa quando descartou entradas porque o cliente ficou para trás
+                this.eventSource.addEventListener('dropped', (event) => {
+                    const data = JSON.parse(event.data);
+                    this.addLog({ content: `${data.dropped} eventos de log descartados (conexão lenta)`, type: 'warn' });
+                });
+
                 this.eventSource.onerror = (event) => {
                     console.error('DEBUG: Erro na conexão SSE:', event);
                     console.log('DEBUG: Estado da conexão SSE:', this.eventSource.readyState);
@@ -137,7 +143,7 @@ class LogsApp {
                     this.logsContainer.innerHTML = '';
                     this.totalLogs = 0;
                     this.errorCount = 0;
-                    this.successCount = 0;
+                    this.warnCount = 0;
 
                     data.logs.forEach(log => this.addLogFromAPI(log));
                     console.log('DEBUG: Logs iniciais carregados:', this.logs.length);
@@ -195,7 +201,7 @@ class LogsApp {
     addLog(logData) {
         const timestamp = logData.timestamp || new Date().toISOString();
         const content = logData.content || logData.message || logData;
-        const type = this.determineLogType(content);
+        const type = logData.type || this.determineLogType(content);
         
         const logEntry = {
             timestamp,
@@ -220,8 +226,8 @@ class LogsApp {
         const contentLower = content.toLowerCase();
         if (contentLower.includes('erro') || contentLower.includes('error') || contentLower.includes('failed')) {
             return 'error';
-        } else if (contentLower.includes('sucesso') || contentLower.includes('success') || contentLower.includes('200')) {
-            return 'success';
+        } else if (contentLower.includes('aviso') || contentLower.includes('warn')) {
+            return 'warn';
         }
         return 'info';
     }
@@ -232,8 +238,8 @@ class LogsApp {
         
         if (type === 'error') {
             this.errorCount++;
-        } else if (type === 'success') {
-            this.successCount++;
+        } else if (type === 'warn') {
+            this.warnCount++;
         }
         
         this.updateStatsDisplay();
@@ -242,7 +248,7 @@ class LogsApp {
     updateStatsDisplay() {
         this.totalLogsElement.textContent = this.totalLogs;
         this.errorCountElement.textContent = this.errorCount;
-        this.successCountElement.textContent = this.successCount;
+        this.warnCountElement.textContent = this.warnCount;
         
         if (this.lastLogTime) {
             const timeStr = this.lastLogTime.toLocaleTimeString('pt-BR', {
@@ -309,7 +315,7 @@ class LogsApp {
                 this.logs = [];
                 this.totalLogs = 0;
                 this.errorCount = 0;
-                this.successCount = 0;
+                this.warnCount = 0;
                 this.lastLogTime = null;
 
                 this.logsContainer.innerHTML = '';
@@ -336,7 +342,7 @@ class LogsApp {
     addSampleLogs() {
         const sampleLogs = [
             { content: "[2025-07-02T18:20:07.6132] GET /status - IP: ::1", type: "info" },
-            { content: "[2025-07-02T18:20:07.6132] GET /status - Status: 200", type: "success" },
+            { content: "[2025-07-02T18:20:07.6132] GET /status - Status: 404", type: "warn" },
             { content: "[2025-07-02T18:21:55.9712] POST /escreve_arquivo - IP: ::1", type: "info" }
         ];
         
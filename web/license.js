@@ -8,6 +8,28 @@ class LicenseManager {
     init() {
         this.setupEventListeners();
         this.checkLicenseStatus();
+        this.connectLicenseEvents();
+    }
+
+    // Atualiza o status a cada mudança da licença (inclusive as verificações em segundo plano)
+    connectLicenseEvents() {
+        if (typeof WebSocket === 'undefined') return;
+
+        const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+        const socket = new WebSocket(`${protocol}//${window.location.host}/api/ws`);
+        socket.onopen = () => {
+            socket.send(JSON.stringify({ action: 'subscribe', topics: ['license'] }));
+        };
+        socket.onmessage = (message) => {
+            const data = JSON.parse(message.data);
+            if (data.type === 'event' && data.topic === 'license') {
+                this.checkLicenseStatus();
+            }
+        };
+        socket.onclose = () => {
+            // Reconecta após 5 segundos
+            setTimeout(() => this.connectLicenseEvents(), 5000);
+        };
     }
 
     setupEventListeners() {
@@ -27,6 +49,7 @@ class LicenseManager {
             
             this.updateStatusDisplay(data);
             this.updateMachineInfo(data.info);
+            document.getElementById('nextCheck').textContent = this.formatDate(data.next_check);
         } catch (error) {
             console.error('Erro ao verificar status da licença:', error);
             this.showMessage('Erro ao verificar status da licença', 'error');
@@ -111,7 +134,7 @@ class LicenseManager {
                 },
                 body: JSON.stringify({
                     token: token,
-                    api_url: apiUrl || 'http://localhost:8000'
+                    api_url: apiUrl
                 })
             });
 
@@ -0,0 +1,82 @@
+package webhooks
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReceivedWebhook é uma entrega recebida pelo receptor de teste
+type ReceivedWebhook struct {
+	Event          string    `json:"event"`
+	Delivery       string    `json:"delivery"`
+	RequestID      string    `json:"request_id,omitempty"`
+	SignatureValid bool      `json:"signature_valid"`
+	StatusCode     int       `json:"status_code"`
+	Body           string    `json:"body"`
+	ReceivedAt     time.Time `json:"received_at"`
+}
+
+// TestReceiver é um receptor de webhooks para testes locais.
+// Confere a assinatura (quando Secret está definido), guarda as entregas recebidas
+// e responde 500 às primeiras FailFirst requisições para exercitar as novas tentativas.
+type TestReceiver struct {
+	Secret    string
+	FailFirst int
+	OnReceive func(ReceivedWebhook)
+
+	mutex    sync.Mutex
+	received []ReceivedWebhook
+}
+
+// ServeHTTP recebe uma entrega
+func (t *TestReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Erro ao ler corpo", http.StatusBadRequest)
+		return
+	}
+
+	received := ReceivedWebhook{
+		Event:          r.Header.Get(EventHeader),
+		Delivery:       r.Header.Get(DeliveryHeader),
+		RequestID:      r.Header.Get("X-Request-ID"),
+		SignatureValid: t.Secret == "" || VerifySignature(t.Secret, r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)),
+		StatusCode:     http.StatusNoContent,
+		Body:           string(body),
+		ReceivedAt:     time.Now(),
+	}
+
+	t.mutex.Lock()
+	switch {
+	case !received.SignatureValid:
+		received.StatusCode = http.StatusUnauthorized
+	case t.FailFirst > 0:
+		t.FailFirst--
+		received.StatusCode = http.StatusInternalServerError
+	}
+	t.received = append(t.received, received)
+	onReceive := t.OnReceive
+	t.mutex.Unlock()
+
+	if onReceive != nil {
+		onReceive(received)
+	}
+	w.WriteHeader(received.StatusCode)
+}
+
+// Received retorna as entregas recebidas até o momento
+func (t *TestReceiver) Received() []ReceivedWebhook {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := make([]ReceivedWebhook, len(t.received))
+	copy(result, t.received)
+	return result
+}
@@ -0,0 +1,25 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// signaturePrefix identifica o algoritmo no cabeçalho X-Webhook-Signature
+const signaturePrefix = "sha256="
+
+// Sign calcula a assinatura HMAC-SHA256 de "<timestamp>.<corpo>" com o segredo do webhook.
+// O resultado vai no cabeçalho X-Webhook-Signature no formato sha256=<hex>.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature confere a assinatura recebida em tempo constante
+func VerifySignature(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// Valor de referência: HMAC-SHA256("segredo", "1700000000.{}")
+	const want = "sha256=28d73844c84580182772a1e98a60aeb8f04184b1bef0d4e147cfa59ebf0bfedc"
+	if got := Sign("segredo", "1700000000", []byte("{}")); got != want {
+		t.Errorf("Sign = %q, esperado %q", got, want)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret, timestamp, body := "segredo", "1700000000", []byte(`{"type":"LicenseChanged"}`)
+	valid := Sign(secret, timestamp, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"assinatura válida", secret, timestamp, body, valid, true},
+		{"segredo diferente", "outro", timestamp, body, valid, false},
+		{"timestamp alterado", secret, "1700000001", body, valid, false},
+		{"corpo alterado", secret, timestamp, []byte(`{"type":"Outro"}`), valid, false},
+		{"sem prefixo", secret, timestamp, body, strings.TrimPrefix(valid, signaturePrefix), false},
+		{"maiúsculas", secret, timestamp, body, strings.ToUpper(valid), false},
+		{"vazia", secret, timestamp, body, "", false},
+		// O ponto separa timestamp e corpo: mover bytes entre eles muda a assinatura
+		{"fronteira deslocada", secret, "170000000", []byte("0." + string(body)), valid, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(tt.secret, tt.timestamp, tt.body, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
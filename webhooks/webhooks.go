@@ -0,0 +1,218 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-desktop-app/config"
+	"go-desktop-app/database"
+	"go-desktop-app/events"
+	"go-desktop-app/logging"
+	"go-desktop-app/metrics"
+)
+
+// Cabeçalhos enviados em cada entrega
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Parâmetros do entregador
+const (
+	pollInterval  = time.Second
+	deliveryBatch = 20
+)
+
+// logger registra as entregas de webhooks
+var logger = logging.Component("webhooks")
+
+var (
+	startOnce  sync.Once
+	wake       = make(chan struct{}, 1)
+	// Redirecionamentos não são seguidos: um 3xx conta como falha e a entrega é repetida
+	httpClient = &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+)
+
+// Start inscreve os webhooks no barramento de eventos e inicia o entregador.
+// Deve ser chamado depois de database.InitDatabase.
+func Start() {
+	startOnce.Do(func() {
+		events.Subscribe("webhooks", enqueueEvent)
+		go run()
+	})
+}
+
+// Wake antecipa a próxima rodada de entregas (ex.: após um replay)
+func Wake() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueueEvent grava uma entrega pendente do evento para cada webhook interessado
+func enqueueEvent(event events.Event) {
+	if !config.GetSettings().Webhooks.Enabled {
+		return
+	}
+
+	hooks, err := database.WebhooksForEvent(string(event.Type))
+	if err != nil {
+		logger.Warn("Erro ao consultar webhooks", "event", event.Type, "error", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("Erro ao serializar evento para webhook", "event", event.Type, "error", err)
+		return
+	}
+
+	if err := database.EnqueueWebhookDeliveries(hooks, int64(event.ID), string(event.Type), event.RequestID, string(payload)); err != nil {
+		logger.Error("Erro ao agendar entregas de webhook", "event", event.Type, "error", err,
+			logging.RequestIDKey, event.RequestID)
+		return
+	}
+	Wake()
+}
+
+// run processa as entregas vencidas a cada pollInterval ou quando acordado
+func run() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+		if config.GetSettings().Webhooks.Enabled {
+			processDue()
+		}
+	}
+}
+
+// processDue tenta entregar todas as entregas cuja próxima tentativa já venceu
+func processDue() {
+	for {
+		due, err := database.DueWebhookDeliveries(time.Now(), deliveryBatch)
+		if err != nil {
+			logger.Warn("Erro ao consultar entregas de webhook", "error", err)
+			return
+		}
+		for _, delivery := range due {
+			attempt(delivery)
+		}
+		if len(due) < deliveryBatch {
+			return
+		}
+	}
+}
+
+// attempt envia a entrega e registra o resultado: entregue, nova tentativa ou dead letter
+func attempt(delivery database.WebhookDelivery) {
+	settings := config.GetSettings().Webhooks
+
+	hook, err := database.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		logger.Warn("Erro ao carregar webhook da entrega", "delivery_id", delivery.ID, "error", err)
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	statusCode, err := send(hook, delivery, time.Duration(settings.TimeoutSeconds)*time.Second)
+	if err == nil {
+		metrics.WebhookDeliveries.Inc("delivered")
+		if err := database.MarkWebhookDelivered(delivery.ID, attempts, statusCode); err != nil {
+			logger.Error("Erro ao registrar entrega de webhook", "delivery_id", delivery.ID, "error", err)
+		}
+		logger.Debug("Webhook entregue", "delivery_id", delivery.ID, "url", hook.URL, "event", delivery.EventType,
+			"status", statusCode, logging.RequestIDKey, delivery.RequestID)
+		return
+	}
+
+	if attempts >= settings.MaxAttempts {
+		metrics.WebhookDeliveries.Inc("dead_letter")
+		if err := database.MarkWebhookDeadLetter(delivery, attempts, statusCode, err.Error()); err != nil {
+			logger.Error("Erro ao mover entrega para dead letter", "delivery_id", delivery.ID, "error", err)
+		}
+		logger.Error("Webhook movido para dead letter", "delivery_id", delivery.ID, "url", hook.URL,
+			"event", delivery.EventType, "attempts", attempts, "error", err, logging.RequestIDKey, delivery.RequestID)
+		return
+	}
+
+	next := time.Now().Add(Backoff(attempts, settings))
+	metrics.WebhookDeliveries.Inc("retry")
+	if err := database.MarkWebhookRetry(delivery.ID, attempts, statusCode, err.Error(), next); err != nil {
+		logger.Error("Erro ao agendar nova tentativa de webhook", "delivery_id", delivery.ID, "error", err)
+	}
+	logger.Warn("Falha ao entregar webhook", "delivery_id", delivery.ID, "url", hook.URL, "event", delivery.EventType,
+		"attempts", attempts, "next_attempt", next.Format(time.RFC3339), "error", err, logging.RequestIDKey, delivery.RequestID)
+}
+
+// send faz o POST do payload assinado; qualquer status fora de 2xx é uma falha
+func send(hook *database.Webhook, delivery database.WebhookDelivery, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar requisição: %v", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-desktop-app-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, timestamp)
+	if delivery.RequestID != "" {
+		req.Header.Set("X-Request-ID", delivery.RequestID)
+	}
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao enviar webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receptor respondeu %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff retorna a espera antes da próxima tentativa: initial_backoff_seconds
+// dobrando a cada tentativa já feita, limitado a max_backoff_seconds
+func Backoff(attempts int, settings config.WebhookSettings) time.Duration {
+	wait := time.Duration(settings.InitialBackoffSeconds) * time.Second
+	limit := time.Duration(settings.MaxBackoffSeconds) * time.Second
+	for i := 1; i < attempts && wait < limit; i++ {
+		wait *= 2
+	}
+	if limit > 0 && wait > limit {
+		wait = limit
+	}
+	return wait
+}
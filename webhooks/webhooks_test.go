@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-desktop-app/database"
+)
+
+func TestSendStatus(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	tests := []struct {
+		name       string
+		status     int
+		location   string
+		shouldFail bool
+	}{
+		{"200 entregue", http.StatusOK, "", false},
+		{"204 entregue", http.StatusNoContent, "", false},
+		{"302 não é seguido", http.StatusFound, target.URL, true},
+		{"308 não é seguido", http.StatusPermanentRedirect, target.URL, true},
+		{"404 falha", http.StatusNotFound, "", true},
+		{"500 falha", http.StatusInternalServerError, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.location != "" {
+					w.Header().Set("Location", tt.location)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			hook := &database.Webhook{URL: receiver.URL}
+			delivery := database.WebhookDelivery{ID: 1, EventType: "file.read", Payload: "{}"}
+			status, err := send(hook, delivery, 5*time.Second)
+			if status != tt.status {
+				t.Errorf("status = %d, esperado %d", status, tt.status)
+			}
+			if (err != nil) != tt.shouldFail {
+				t.Errorf("erro = %v, esperado falha = %v", err, tt.shouldFail)
+			}
+		})
+	}
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	var received *http.Request
+	var valid bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		valid = VerifySignature("segredo", r.Header.Get(TimestampHeader), []byte(`{"id":1}`), r.Header.Get(SignatureHeader))
+	}))
+	defer receiver.Close()
+
+	hook := &database.Webhook{URL: receiver.URL, Secret: "segredo"}
+	delivery := database.WebhookDelivery{ID: 7, EventType: "license.changed", Payload: `{"id":1}`, RequestID: "req-1"}
+	if _, err := send(hook, delivery, 5*time.Second); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if !valid {
+		t.Error("assinatura inválida no receptor")
+	}
+	headers := map[string]string{
+		EventHeader:    "license.changed",
+		DeliveryHeader: "7",
+		"X-Request-ID": "req-1",
+	}
+	for name, expected := range headers {
+		if got := received.Header.Get(name); got != expected {
+			t.Errorf("%s = %q, esperado %q", name, got, expected)
+		}
+	}
+}